@@ -8,10 +8,14 @@ import (
 	"github.com/yakoovad/avito-winter-2025/internal/api"
 	"github.com/yakoovad/avito-winter-2025/internal/db"
 	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"github.com/yakoovad/avito-winter-2025/internal/scheduler"
 	"github.com/yakoovad/avito-winter-2025/internal/service"
 	"github.com/yakoovad/avito-winter-2025/pkg/logger"
 	"go.uber.org/zap"
 	"log"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -44,22 +48,121 @@ func main() {
 	prRepo := repository.NewPgxPullRequestRepository(pool)
 	userRepo := repository.NewPgxUserRepository(pool)
 	reviewRepo := repository.NewPgxReviewRepository(pool)
+	statsRepo := repository.NewPgxStatsRepository(pool)
+	auditRepo := repository.NewPgxAuditRepository(pool)
+	eventRepo := repository.NewPgxPullRequestEventRepository(pool)
+	revokedTokenRepo := repository.NewPgxRevokedTokenRepository(pool)
+
+	pr := service.NewPullRequestService(transactor).
+		WithPullRequestRepo(prRepo).
+		WithTeamRepo(teamRepo).
+		WithUserRepo(userRepo).
+		WithReviewRepo(reviewRepo).
+		WithAuditRepo(auditRepo).
+		WithEventRepo(eventRepo)
+
+	if v := os.Getenv("MAX_OPEN_REVIEWS"); v != "" {
+		maxOpenReviews, err := strconv.Atoi(v)
+		if err != nil || maxOpenReviews < 0 {
+			l.Fatal("invalid MAX_OPEN_REVIEWS", zap.String("value", v), zap.Error(err))
+		}
+		pr.WithMaxOpenReviews(maxOpenReviews)
+	}
+
+	weights := make(map[string]int)
+	for userID, w := range parsePairs(os.Getenv("REVIEWER_WEIGHTS")) {
+		if weights[userID], err = strconv.Atoi(w); err != nil {
+			l.Fatal("invalid reviewer weight", zap.String("user_id", userID), zap.Error(err))
+		}
+	}
+
+	selectorCfg := service.ReviewerSelectorConfig{
+		Strategy: service.ReviewerStrategy(os.Getenv("REVIEWER_STRATEGY")),
+		Seed:     time.Now().UnixNano(),
+		Weights:  weights,
+	}
+
+	selector, err := service.NewReviewerSelector(selectorCfg, reviewRepo)
+	if err != nil {
+		l.Fatal("failed to create reviewer selector", zap.Error(err))
+	}
+	pr.WithReviewerSelector(selector)
+
+	// REVIEWER_TEAM_STRATEGIES=backend=round_robin,frontend=least_loaded
+	for teamName, strategy := range parsePairs(os.Getenv("REVIEWER_TEAM_STRATEGIES")) {
+		selectorCfg.Strategy = service.ReviewerStrategy(strategy)
+
+		teamSelector, err := service.NewReviewerSelector(selectorCfg, reviewRepo)
+		if err != nil {
+			l.Fatal("failed to create team reviewer selector", zap.String("team_name", teamName), zap.Error(err))
+		}
+		pr.WithTeamReviewerSelector(teamName, teamSelector)
+	}
 
 	team := service.NewTeamService(transactor).
 		WithTeamRepo(teamRepo).
 		WithUserRepo(userRepo).
-		WithReviewRepo(reviewRepo)
+		WithReviewRepo(reviewRepo).
+		WithAuditRepo(auditRepo).
+		WithPullRequestService(pr)
 
 	user := service.NewUserService(transactor).
 		WithUserRepo(userRepo).
 		WithTeamRepo(teamRepo).
-		WithReviewRepo(reviewRepo)
+		WithReviewRepo(reviewRepo).
+		WithAuditRepo(auditRepo).
+		WithPullRequestService(pr)
+
+	stats := service.NewStatsService().
+		WithStatsRepo(statsRepo).
+		WithTeamRepo(teamRepo)
+
+	auditLog := service.NewAuditService().
+		WithAuditRepo(auditRepo)
+
+	authService := service.NewAuthService(transactor).
+		WithRevokedTokenRepo(revokedTokenRepo).
+		WithAuditRepo(auditRepo)
+
+	// STALE_CHECK_INTERVAL=5m, 0 disables the stale PR scheduler
+	staleInterval := 5 * time.Minute
+	if v := os.Getenv("STALE_CHECK_INTERVAL"); v != "" {
+		if staleInterval, err = time.ParseDuration(v); err != nil || staleInterval < 0 {
+			l.Fatal("invalid STALE_CHECK_INTERVAL", zap.String("value", v), zap.Error(err))
+		}
+	}
 
-	pr := service.NewPullRequestService(transactor).
-		WithPullRequestRepo(prRepo).
-		WithTeamRepo(teamRepo).
-		WithUserRepo(userRepo).
-		WithReviewRepo(reviewRepo)
+	ctx, cancel := context.WithCancel(logger.WithLogger(context.Background(), l))
+	defer cancel()
+
+	if staleInterval > 0 {
+		staleLock := db.NewAdvisoryLock(pool, scheduler.StaleLockKey)
+		go scheduler.NewStaleScheduler(pr, staleLock, staleInterval).Run(ctx)
+	}
+
+	// REVOCATION_REFRESH_INTERVAL=30s, how long a token revoked on another replica is still accepted here
+	revocationInterval := 30 * time.Second
+	if v := os.Getenv("REVOCATION_REFRESH_INTERVAL"); v != "" {
+		if revocationInterval, err = time.ParseDuration(v); err != nil || revocationInterval <= 0 {
+			l.Fatal("invalid REVOCATION_REFRESH_INTERVAL", zap.String("value", v), zap.Error(err))
+		}
+	}
+
+	if srvErr := authService.RefreshRevokedTokens(ctx); srvErr != nil {
+		l.Fatal("failed to load revoked tokens", zap.Error(srvErr))
+	}
+	go scheduler.NewRevocationRefresher(authService, revocationInterval).Run(ctx)
+
+	// ALLOW_TOKENS_WITHOUT_JTI=true accepts tokens issued before jti until they expire, they cannot be revoked
+	allowTokensWithoutJTI := false
+	if v := os.Getenv("ALLOW_TOKENS_WITHOUT_JTI"); v != "" {
+		if allowTokensWithoutJTI, err = strconv.ParseBool(v); err != nil {
+			l.Fatal("invalid ALLOW_TOKENS_WITHOUT_JTI", zap.String("value", v), zap.Error(err))
+		}
+		if allowTokensWithoutJTI {
+			l.Warn("tokens without jti are accepted and cannot be revoked")
+		}
+	}
 
 	e := echo.New()
 
@@ -77,6 +180,10 @@ func main() {
 		WithTeamService(team).
 		WithUserService(user).
 		WithPullRequestService(pr).
+		WithStatsService(stats).
+		WithAuditService(auditLog).
+		WithAuthService(authService).
+		WithTokensWithoutJTI(allowTokensWithoutJTI).
 		WithHealthChecker(healthChecker)
 
 	handler.RegisterRoutes(e)
@@ -85,3 +192,16 @@ func main() {
 		l.Fatal("fatal server error", zap.Error(err))
 	}
 }
+
+// parsePairs Parses "key=value,key=value" lists used in env configuration
+func parsePairs(s string) map[string]string {
+	res := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" {
+			continue
+		}
+		res[key] = value
+	}
+	return res
+}
@@ -0,0 +1,153 @@
+// tokenctl Issues and inspects API tokens signed with TOKEN_AUTH_SECRET.
+//
+//	tokenctl issue -type user -sub u1 -team backend -scopes reviews:read -ttl 720h
+//	tokenctl issue -type admin -sub ops -ttl 1h
+//	tokenctl decode <token>
+//
+// issue prints the token to stdout and its jti and expiry to stderr, decode prints the claims and
+// exits with 1 when the token does not verify against the secret.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/yakoovad/avito-winter-2025/internal/auth"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const usage = `usage:
+  tokenctl issue -type user|admin [-sub USER_ID] [-team TEAM] [-scopes a,b] [-ttl 24h]
+  tokenctl decode [TOKEN]    reads the token from stdin when omitted or "-"`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprintln(stderr, usage)
+		return 2
+	}
+
+	if auth.TokenSecretKey == "" {
+		_, _ = fmt.Fprintln(stderr, "TOKEN_AUTH_SECRET is not set")
+		return 1
+	}
+
+	switch args[0] {
+	case "issue":
+		return issue(args[1:], stdout, stderr)
+	case "decode":
+		return decode(args[1:], stdin, stdout, stderr)
+	default:
+		_, _ = fmt.Fprintln(stderr, usage)
+		return 2
+	}
+}
+
+func issue(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("issue", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	tokenType := fs.String("type", string(auth.TokenTypeUser), "token type, user or admin")
+	subject := fs.String("sub", "", "user ID of the caller, required for user tokens")
+	team := fs.String("team", "", "team the user may query")
+	scopes := fs.String("scopes", "", "comma separated scopes of a user token: reviews:read, reviews:write, teams:read, stats:read")
+	ttl := fs.Duration("ttl", 24*time.Hour, "time until the token expires")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *ttl <= 0 {
+		_, _ = fmt.Fprintln(stderr, "ttl must be positive")
+		return 2
+	}
+
+	spec := auth.TokenSpec{
+		Type:    auth.TokenType(*tokenType),
+		Subject: *subject,
+		Team:    *team,
+		Scopes:  splitScopes(*scopes),
+		TTL:     *ttl,
+	}
+
+	token, err := auth.GenerateToken(spec)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, "failed to issue token:", err)
+		return 1
+	}
+
+	claims, err := auth.VerifyToken(token)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, "issued token does not verify:", err)
+		return 1
+	}
+
+	_, _ = fmt.Fprintln(stdout, token)
+	_, _ = fmt.Fprintln(stderr, "jti:", claims.ID)
+	_, _ = fmt.Fprintln(stderr, "expires_at:", claims.ExpiresAt.Format(time.RFC3339))
+	return 0
+}
+
+func decode(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	token := ""
+	if len(args) > 0 && args[0] != "-" {
+		token = args[0]
+	} else {
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			_, _ = fmt.Fprintln(stderr, "failed to read token:", err)
+			return 1
+		}
+		token = line
+	}
+	token = strings.TrimPrefix(strings.TrimSpace(token), "Bearer ")
+
+	claims, err := auth.DecodeToken(token)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, "failed to decode token:", err)
+		return 1
+	}
+
+	_, _ = fmt.Fprintln(stdout, "jti:       ", claims.ID)
+	_, _ = fmt.Fprintln(stdout, "type:      ", claims.Type)
+	_, _ = fmt.Fprintln(stdout, "sub:       ", claims.Subject)
+	_, _ = fmt.Fprintln(stdout, "team:      ", claims.Team)
+	_, _ = fmt.Fprintln(stdout, "scopes:    ", strings.Join(claims.Scopes, ","))
+	if claims.IssuedAt != nil {
+		_, _ = fmt.Fprintln(stdout, "issued_at: ", claims.IssuedAt.Format(time.RFC3339))
+	}
+	if claims.ExpiresAt != nil {
+		_, _ = fmt.Fprintf(stdout, "expires_at: %s (%s)\n",
+			claims.ExpiresAt.Format(time.RFC3339), expiresIn(claims.ExpiresAt.Time))
+	}
+
+	if _, err = auth.VerifyToken(token); err != nil {
+		_, _ = fmt.Fprintln(stdout, "valid:      false,", err)
+		return 1
+	}
+	_, _ = fmt.Fprintln(stdout, "valid:      true")
+	return 0
+}
+
+func splitScopes(s string) []string {
+	var res []string
+	for _, scope := range strings.Split(s, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			res = append(res, scope)
+		}
+	}
+	return res
+}
+
+func expiresIn(t time.Time) string {
+	d := time.Until(t).Round(time.Second)
+	if d <= 0 {
+		return fmt.Sprintf("expired %s ago", -d)
+	}
+	return fmt.Sprintf("in %s", d)
+}
@@ -13,12 +13,18 @@ import (
 )
 
 type Handler struct {
-	pr   *service.PullRequestService
-	team *service.TeamService
-	user *service.UserService
+	pr    *service.PullRequestService
+	team  *service.TeamService
+	user  *service.UserService
+	stats *service.StatsService
+	audit *service.AuditService
+	auth  *service.AuthService
 
 	healthChecker HealthChecker
 
+	// allowTokensWithoutJTI Accepts tokens issued before jti, they cannot be revoked
+	allowTokensWithoutJTI bool
+
 	logger *zap.Logger
 }
 
@@ -43,6 +49,27 @@ func (h *Handler) WithUserService(user *service.UserService) *Handler {
 	return h
 }
 
+func (h *Handler) WithStatsService(stats *service.StatsService) *Handler {
+	h.stats = stats
+	return h
+}
+
+func (h *Handler) WithAuditService(audit *service.AuditService) *Handler {
+	h.audit = audit
+	return h
+}
+
+func (h *Handler) WithAuthService(auth *service.AuthService) *Handler {
+	h.auth = auth
+	return h
+}
+
+// WithTokensWithoutJTI Accepts tokens without jti during the transition, they are rejected by default
+func (h *Handler) WithTokensWithoutJTI(allow bool) *Handler {
+	h.allowTokensWithoutJTI = allow
+	return h
+}
+
 func (h *Handler) WithPullRequestService(pr *service.PullRequestService) *Handler {
 	h.pr = pr
 	return h
@@ -57,18 +84,43 @@ func (h *Handler) RegisterRoutes(e *echo.Echo) {
 
 	e.GET("/health", h.healthChecker.HealthCheck())
 
-	userSecurity := e.Group("", AuthMiddleware(auth.TokenTypeUser, auth.TokenTypeAdmin))
+	var revoked RevocationChecker
+	if h.auth != nil {
+		revoked = h.auth
+	}
+
+	userSecurity := e.Group("", AuthMiddleware(revoked, h.allowTokensWithoutJTI, auth.TokenTypeUser, auth.TokenTypeAdmin))
 
-	userSecurity.POST("/team/get", h.GetTeam)
-	userSecurity.GET("/users/getReview", h.GetUserReview)
+	userSecurity.POST("/team/get", h.GetTeam, RequireScope(auth.ScopeTeamsRead))
+	userSecurity.GET("/team/absences", h.GetTeamAbsences, RequireScope(auth.ScopeTeamsRead))
+	userSecurity.GET("/stats/reviewers", h.GetReviewerStats, RequireScope(auth.ScopeStatsRead))
+	userSecurity.GET("/stats/pullRequests", h.GetPullRequestStats, RequireScope(auth.ScopeStatsRead))
+	userSecurity.GET("/users/getReview", h.GetUserReview, RequireScope(auth.ScopeReviewsRead))
+	userSecurity.GET("/pullRequest/history", h.GetPullRequestHistory, RequireScope(auth.ScopeReviewsRead))
+	userSecurity.POST("/pullRequest/review", h.ReviewPullRequest, RequireScope(auth.ScopeReviewsWrite))
 
-	adminSecurity := e.Group("", AuthMiddleware(auth.TokenTypeAdmin))
+	adminSecurity := e.Group("", AuthMiddleware(revoked, h.allowTokensWithoutJTI, auth.TokenTypeAdmin))
 
 	adminSecurity.POST("/team/add", h.AddTeam)
+	adminSecurity.POST("/team/deactivateUsers", h.DeactivateTeamUsers)
+	adminSecurity.GET("/team/settings/get", h.GetTeamSettings)
+	adminSecurity.POST("/team/settings/set", h.SetTeamSettings)
+	adminSecurity.GET("/team/rules/get", h.GetTeamRules)
+	adminSecurity.POST("/team/rules/set", h.SetTeamRules)
 	adminSecurity.POST("/users/setIsActive", h.SetUserIsActive)
+	adminSecurity.POST("/users/setAvailability", h.SetUserAvailability)
+	adminSecurity.POST("/users/setMaxOpenReviews", h.SetUserMaxOpenReviews)
 	adminSecurity.POST("/pullRequest/create", h.CreatePullRequest)
 	adminSecurity.POST("/pullRequest/merge", h.MergePullRequest)
+	adminSecurity.POST("/pullRequest/markReady", h.MarkPullRequestReady)
+	adminSecurity.POST("/pullRequest/close", h.ClosePullRequest)
+	adminSecurity.POST("/pullRequest/reopen", h.ReopenPullRequest)
 	adminSecurity.POST("/pullRequest/reassign", h.ReassignPullRequest)
+	adminSecurity.POST("/pullRequest/addReviewer", h.AddReviewer)
+	adminSecurity.POST("/pullRequest/removeReviewer", h.RemoveReviewer)
+	adminSecurity.GET("/audit/list", h.ListAuditEvents)
+	adminSecurity.POST("/auth/revoke", h.RevokeToken)
+	adminSecurity.GET("/auth/revoked", h.ListRevokedTokens)
 }
 
 func (h *Handler) GetUserReview(e echo.Context) error {
@@ -78,6 +130,10 @@ func (h *Handler) GetUserReview(e echo.Context) error {
 
 	l.Info("getting user reviews", zap.String("user_id", userID))
 
+	if !auth.PrincipalFromContext(e.Request().Context()).CanAccessUser(userID) {
+		return h.transportError(e, service.NewError(service.ErrorCodeForbidden, "users may only query their own reviews"))
+	}
+
 	reviews, err := h.pr.GetUserReview(e.Request().Context(), userID)
 	if err != nil {
 		l.Error("failed to get user reviews", zap.String("user_id", userID), zap.Any("error", err))
@@ -87,12 +143,41 @@ func (h *Handler) GetUserReview(e echo.Context) error {
 	return e.JSON(http.StatusOK, reviews)
 }
 
+func (h *Handler) GetPullRequestHistory(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	prID := e.QueryParam("pull_request_id")
+
+	l.Info("getting pull request history", zap.String("pull_request_id", prID))
+
+	if principal := auth.PrincipalFromContext(e.Request().Context()); !principal.IsAdmin() {
+		teamName, err := h.pr.GetAuthorTeam(e.Request().Context(), prID)
+		if err != nil {
+			l.Error("failed to get pull request author team", zap.String("pull_request_id", prID), zap.Any("error", err))
+			return h.transportError(e, err)
+		}
+		if !principal.CanAccessTeam(teamName) {
+			return h.transportError(e, service.NewError(service.ErrorCodeForbidden, "users may only query PRs of their own team"))
+		}
+	}
+
+	history, err := h.pr.GetHistory(e.Request().Context(), prID)
+	if err != nil {
+		l.Error("failed to get pull request history", zap.String("pull_request_id", prID), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, history)
+}
+
 func (h *Handler) ReassignPullRequest(e echo.Context) error {
 	l := logger.FromContext(e.Request().Context())
 
 	var req struct {
-		ID     string `json:"pull_request_id" validate:"required"`
-		UserID string `json:"old_user_id" validate:"required"`
+		ID        string `json:"pull_request_id" validate:"required"`
+		UserID    string `json:"old_user_id" validate:"required"`
+		NewUserID string `json:"new_user_id"`
+		Force     bool   `json:"force"`
 	}
 
 	if err := h.decodeRequest(e, &req); err != nil {
@@ -102,9 +187,11 @@ func (h *Handler) ReassignPullRequest(e echo.Context) error {
 
 	l.Info("reassigning pull request",
 		zap.String("pr_id", req.ID),
-		zap.String("old_user_id", req.UserID))
+		zap.String("old_user_id", req.UserID),
+		zap.String("new_user_id", req.NewUserID),
+		zap.Bool("force", req.Force))
 
-	pr, err := h.pr.ReassignPullRequest(e.Request().Context(), req.ID, req.UserID)
+	pr, err := h.pr.ReassignPullRequest(e.Request().Context(), req.ID, req.UserID, req.NewUserID, req.Force)
 	if err != nil {
 		l.Error("failed to reassign pull request",
 			zap.String("pr_id", req.ID),
@@ -116,11 +203,101 @@ func (h *Handler) ReassignPullRequest(e echo.Context) error {
 	return e.JSON(http.StatusOK, pr)
 }
 
+func (h *Handler) AddReviewer(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	var req struct {
+		ID     string `json:"pull_request_id" validate:"required"`
+		UserID string `json:"user_id" validate:"required"`
+	}
+
+	if err := h.decodeRequest(e, &req); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("adding reviewer", zap.String("pr_id", req.ID), zap.String("user_id", req.UserID))
+
+	pr, err := h.pr.AddReviewer(e.Request().Context(), req.ID, req.UserID)
+	if err != nil {
+		l.Error("failed to add reviewer",
+			zap.String("pr_id", req.ID),
+			zap.String("user_id", req.UserID),
+			zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, pr)
+}
+
+func (h *Handler) RemoveReviewer(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	var req struct {
+		ID     string `json:"pull_request_id" validate:"required"`
+		UserID string `json:"user_id" validate:"required"`
+	}
+
+	if err := h.decodeRequest(e, &req); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("removing reviewer", zap.String("pr_id", req.ID), zap.String("user_id", req.UserID))
+
+	pr, err := h.pr.RemoveReviewer(e.Request().Context(), req.ID, req.UserID)
+	if err != nil {
+		l.Error("failed to remove reviewer",
+			zap.String("pr_id", req.ID),
+			zap.String("user_id", req.UserID),
+			zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, pr)
+}
+
+func (h *Handler) ReviewPullRequest(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	var req struct {
+		ID     string            `json:"pull_request_id" validate:"required"`
+		UserID string            `json:"user_id" validate:"required"`
+		State  model.ReviewState `json:"state" validate:"required,oneof=PENDING APPROVED CHANGES_REQUESTED"`
+	}
+
+	if err := h.decodeRequest(e, &req); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("reviewing pull request",
+		zap.String("pr_id", req.ID),
+		zap.String("user_id", req.UserID),
+		zap.String("state", string(req.State)))
+
+	if !auth.PrincipalFromContext(e.Request().Context()).CanAccessUser(req.UserID) {
+		return h.transportError(e, service.NewError(service.ErrorCodeForbidden, "users may only submit their own reviews"))
+	}
+
+	pr, err := h.pr.SubmitReview(e.Request().Context(), req.ID, req.UserID, req.State)
+	if err != nil {
+		l.Error("failed to review pull request",
+			zap.String("pr_id", req.ID),
+			zap.String("user_id", req.UserID),
+			zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, pr)
+}
+
 func (h *Handler) MergePullRequest(e echo.Context) error {
 	l := logger.FromContext(e.Request().Context())
 
 	var req struct {
-		ID string `json:"pull_request_id" validate:"required"`
+		ID    string `json:"pull_request_id" validate:"required"`
+		Force bool   `json:"force"`
 	}
 
 	if err := h.decodeRequest(e, &req); err != nil {
@@ -128,9 +305,9 @@ func (h *Handler) MergePullRequest(e echo.Context) error {
 		return h.transportError(e, err)
 	}
 
-	l.Info("merging pull request", zap.String("pr_id", req.ID))
+	l.Info("merging pull request", zap.String("pr_id", req.ID), zap.Bool("force", req.Force))
 
-	pr, err := h.pr.MergePullRequest(e.Request().Context(), req.ID)
+	pr, err := h.pr.MergePullRequest(e.Request().Context(), req.ID, req.Force)
 	if err != nil {
 		l.Error("failed to merge pull request", zap.String("pr_id", req.ID), zap.Any("error", err))
 		return h.transportError(e, err)
@@ -139,6 +316,75 @@ func (h *Handler) MergePullRequest(e echo.Context) error {
 	return e.JSON(http.StatusOK, pr)
 }
 
+func (h *Handler) MarkPullRequestReady(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	var req struct {
+		ID string `json:"pull_request_id" validate:"required"`
+	}
+
+	if err := h.decodeRequest(e, &req); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("marking pull request ready", zap.String("pr_id", req.ID))
+
+	pr, err := h.pr.MarkReady(e.Request().Context(), req.ID)
+	if err != nil {
+		l.Error("failed to mark pull request ready", zap.String("pr_id", req.ID), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, pr)
+}
+
+func (h *Handler) ClosePullRequest(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	var req struct {
+		ID string `json:"pull_request_id" validate:"required"`
+	}
+
+	if err := h.decodeRequest(e, &req); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("closing pull request", zap.String("pr_id", req.ID))
+
+	pr, err := h.pr.ClosePullRequest(e.Request().Context(), req.ID)
+	if err != nil {
+		l.Error("failed to close pull request", zap.String("pr_id", req.ID), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, pr)
+}
+
+func (h *Handler) ReopenPullRequest(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	var req struct {
+		ID string `json:"pull_request_id" validate:"required"`
+	}
+
+	if err := h.decodeRequest(e, &req); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("reopening pull request", zap.String("pr_id", req.ID))
+
+	pr, err := h.pr.ReopenPullRequest(e.Request().Context(), req.ID)
+	if err != nil {
+		l.Error("failed to reopen pull request", zap.String("pr_id", req.ID), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, pr)
+}
+
 func (h *Handler) CreatePullRequest(e echo.Context) error {
 	l := logger.FromContext(e.Request().Context())
 
@@ -146,6 +392,7 @@ func (h *Handler) CreatePullRequest(e echo.Context) error {
 		ID       string `json:"pull_request_id" validate:"required"`
 		Name     string `json:"pull_request_name" validate:"required"`
 		AuthorID string `json:"author_id" validate:"required"`
+		Draft    bool   `json:"draft"`
 	}
 
 	if err := h.decodeRequest(e, &req); err != nil {
@@ -156,12 +403,17 @@ func (h *Handler) CreatePullRequest(e echo.Context) error {
 	l.Info("creating pull request",
 		zap.String("pr_id", req.ID),
 		zap.String("pr_name", req.Name),
-		zap.String("author_id", req.AuthorID))
+		zap.String("author_id", req.AuthorID),
+		zap.Bool("draft", req.Draft))
 
 	short := &model.PullRequestShort{
 		ID:       req.ID,
 		Name:     req.Name,
 		AuthorID: req.AuthorID,
+		Status:   model.PRStatusOpen,
+	}
+	if req.Draft {
+		short.Status = model.PRStatusDraft
 	}
 
 	pr, err := h.pr.CreatePullRequest(e.Request().Context(), short)
@@ -179,8 +431,9 @@ func (h *Handler) SetUserIsActive(e echo.Context) error {
 	l := logger.FromContext(e.Request().Context())
 
 	var req struct {
-		UserID   string `json:"user_id" validate:"required"`
-		IsActive bool   `json:"is_active"`
+		UserID              string `json:"user_id" validate:"required"`
+		IsActive            bool   `json:"is_active"`
+		ReassignOpenReviews bool   `json:"reassign_open_reviews"`
 	}
 
 	if err := h.decodeRequest(e, &req); err != nil {
@@ -190,9 +443,10 @@ func (h *Handler) SetUserIsActive(e echo.Context) error {
 
 	l.Info("setting user active status",
 		zap.String("user_id", req.UserID),
-		zap.Bool("is_active", req.IsActive))
+		zap.Bool("is_active", req.IsActive),
+		zap.Bool("reassign_open_reviews", req.ReassignOpenReviews))
 
-	user, err := h.user.SetUserIsActive(e.Request().Context(), req.UserID, req.IsActive)
+	user, err := h.user.SetUserIsActive(e.Request().Context(), req.UserID, req.IsActive, req.ReassignOpenReviews)
 	if err != nil {
 		l.Error("failed to set user active status",
 			zap.String("user_id", req.UserID),
@@ -203,6 +457,167 @@ func (h *Handler) SetUserIsActive(e echo.Context) error {
 	return e.JSON(http.StatusOK, user)
 }
 
+func (h *Handler) SetUserAvailability(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	availability := &model.UserAvailability{}
+
+	if err := h.decodeRequest(e, availability); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("setting user availability", zap.String("user_id", availability.UserID))
+
+	res, err := h.user.SetAvailability(e.Request().Context(), availability)
+	if err != nil {
+		l.Error("failed to set user availability", zap.String("user_id", availability.UserID), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) SetUserMaxOpenReviews(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	var req struct {
+		UserID         string `json:"user_id" validate:"required"`
+		MaxOpenReviews *int   `json:"max_open_reviews" validate:"omitempty,min=1"`
+	}
+
+	if err := h.decodeRequest(e, &req); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("setting user review cap", zap.String("user_id", req.UserID), zap.Any("max_open_reviews", req.MaxOpenReviews))
+
+	user, err := h.user.SetMaxOpenReviews(e.Request().Context(), req.UserID, req.MaxOpenReviews)
+	if err != nil {
+		l.Error("failed to set user review cap", zap.String("user_id", req.UserID), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, user)
+}
+
+func (h *Handler) GetTeamAbsences(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	teamName := e.QueryParam("team_name")
+
+	l.Info("getting team absences", zap.String("team_name", teamName))
+
+	if !auth.PrincipalFromContext(e.Request().Context()).CanAccessTeam(teamName) {
+		return h.transportError(e, service.NewError(service.ErrorCodeForbidden, "users may only query their own team"))
+	}
+
+	absences, err := h.team.GetAbsences(e.Request().Context(), teamName)
+	if err != nil {
+		l.Error("failed to get team absences", zap.String("team_name", teamName), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, absences)
+}
+
+func (h *Handler) GetReviewerStats(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	filter := &model.StatsFilter{}
+
+	if err := h.decodeRequest(e, filter); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	if err := h.restrictStatsTeam(e, &filter.TeamName); err != nil {
+		return h.transportError(e, err)
+	}
+
+	stats, err := h.stats.GetReviewerStats(e.Request().Context(), filter)
+	if err != nil {
+		l.Error("failed to get reviewer stats", zap.String("team_name", filter.TeamName), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, stats)
+}
+
+func (h *Handler) GetPullRequestStats(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	filter := &model.PullRequestStatsFilter{}
+
+	if err := h.decodeRequest(e, filter); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	if err := h.restrictStatsTeam(e, &filter.TeamName); err != nil {
+		return h.transportError(e, err)
+	}
+
+	stats, err := h.stats.GetPullRequestStats(e.Request().Context(), filter)
+	if err != nil {
+		l.Error("failed to get pull request stats", zap.String("team_name", filter.TeamName), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, stats)
+}
+
+func (h *Handler) ListAuditEvents(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	filter := &model.AuditFilter{}
+
+	if err := h.decodeRequest(e, filter); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	page, err := h.audit.ListEvents(e.Request().Context(), filter)
+	if err != nil {
+		l.Error("failed to list audit events", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) RevokeToken(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	req := &model.TokenRevocation{}
+
+	if err := h.decodeRequest(e, req); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	res, err := h.auth.RevokeToken(e.Request().Context(), req)
+	if err != nil {
+		l.Error("failed to revoke token", zap.String("jti", req.ID), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) ListRevokedTokens(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	res, err := h.auth.ListRevokedTokens(e.Request().Context())
+	if err != nil {
+		l.Error("failed to list revoked tokens", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, res)
+}
+
 func (h *Handler) AddTeam(e echo.Context) error {
 	l := logger.FromContext(e.Request().Context())
 
@@ -223,6 +638,34 @@ func (h *Handler) AddTeam(e echo.Context) error {
 	return e.JSON(http.StatusCreated, team)
 }
 
+func (h *Handler) DeactivateTeamUsers(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	var req struct {
+		TeamName string   `json:"team_name" validate:"required"`
+		UserIDs  []string `json:"user_ids" validate:"required,min=1,dive,required"`
+	}
+
+	if err := h.decodeRequest(e, &req); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("deactivating team users",
+		zap.String("team_name", req.TeamName),
+		zap.Int("count", len(req.UserIDs)))
+
+	res, err := h.team.DeactivateUsers(e.Request().Context(), req.TeamName, req.UserIDs)
+	if err != nil {
+		l.Error("failed to deactivate team users",
+			zap.String("team_name", req.TeamName),
+			zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, res)
+}
+
 func (h *Handler) GetTeam(e echo.Context) error {
 	l := logger.FromContext(e.Request().Context())
 
@@ -230,6 +673,10 @@ func (h *Handler) GetTeam(e echo.Context) error {
 
 	l.Info("getting team", zap.String("team_name", teamName))
 
+	if !auth.PrincipalFromContext(e.Request().Context()).CanAccessTeam(teamName) {
+		return h.transportError(e, service.NewError(service.ErrorCodeForbidden, "users may only query their own team"))
+	}
+
 	team, err := h.team.GetTeam(e.Request().Context(), teamName)
 	if err != nil {
 		l.Error("failed to get team", zap.String("team_name", teamName), zap.Any("error", err))
@@ -239,6 +686,96 @@ func (h *Handler) GetTeam(e echo.Context) error {
 	return e.JSON(http.StatusOK, team)
 }
 
+func (h *Handler) GetTeamSettings(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	teamName := e.QueryParam("team_name")
+
+	l.Info("getting team settings", zap.String("team_name", teamName))
+
+	settings, err := h.team.GetSettings(e.Request().Context(), teamName)
+	if err != nil {
+		l.Error("failed to get team settings", zap.String("team_name", teamName), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, settings)
+}
+
+func (h *Handler) SetTeamSettings(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	update := &model.TeamSettingsUpdate{}
+
+	if err := h.decodeRequest(e, update); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("setting team settings", zap.String("team_name", update.TeamName))
+
+	res, err := h.team.SetSettings(e.Request().Context(), update)
+	if err != nil {
+		l.Error("failed to set team settings", zap.String("team_name", update.TeamName), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) GetTeamRules(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	teamName := e.QueryParam("team_name")
+
+	l.Info("getting reviewer rules", zap.String("team_name", teamName))
+
+	rules, err := h.team.GetRules(e.Request().Context(), teamName)
+	if err != nil {
+		l.Error("failed to get reviewer rules", zap.String("team_name", teamName), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, rules)
+}
+
+func (h *Handler) SetTeamRules(e echo.Context) error {
+	l := logger.FromContext(e.Request().Context())
+
+	rules := &model.TeamRules{}
+
+	if err := h.decodeRequest(e, rules); err != nil {
+		l.Error("invalid request", zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	l.Info("setting reviewer rules", zap.String("team_name", rules.TeamName))
+
+	res, err := h.team.SetRules(e.Request().Context(), rules)
+	if err != nil {
+		l.Error("failed to set reviewer rules", zap.String("team_name", rules.TeamName), zap.Any("error", err))
+		return h.transportError(e, err)
+	}
+
+	return e.JSON(http.StatusOK, res)
+}
+
+// restrictStatsTeam Plain users only see the stats of their own team, it is used when no team is requested
+func (h *Handler) restrictStatsTeam(e echo.Context, teamName *string) *service.Error {
+	principal := auth.PrincipalFromContext(e.Request().Context())
+	if principal.IsAdmin() {
+		return nil
+	}
+
+	if *teamName == "" && principal != nil {
+		*teamName = principal.TeamName
+	}
+	if !principal.CanAccessTeam(*teamName) {
+		return service.NewError(service.ErrorCodeForbidden, "users may only query stats of their own team")
+	}
+	return nil
+}
+
 func (h *Handler) decodeRequest(e echo.Context, req any) *service.Error {
 	if err := e.Bind(req); err != nil {
 		return service.NewError(service.ErrorCodeInvalidBody, "invalid request body")
@@ -260,12 +797,17 @@ func (h *Handler) transportError(e echo.Context, err *service.Error) error {
 		return e.JSON(http.StatusNotFound, response)
 	case service.ErrorCodeTeamExists:
 		return e.JSON(http.StatusBadRequest, response)
-	case service.ErrorCodePRExists, service.ErrorCodePRMerged, service.ErrorCodeNotAssigned, service.ErrorCodeNoCandidate:
+	case service.ErrorCodePRExists, service.ErrorCodePRMerged, service.ErrorCodeNotAssigned, service.ErrorCodeNoCandidate,
+		service.ErrorCodeAlreadyApproved, service.ErrorCodeNotApproved, service.ErrorCodeInvalidTransition,
+		service.ErrorCodePRNotOpen, service.ErrorCodeAlreadyAssigned, service.ErrorCodeNotEligible, service.ErrorCodeAtCapacity,
+		service.ErrorCodeAlreadyRevoked:
 		return e.JSON(http.StatusConflict, response)
 	case service.ErrorCodeInvalidBody:
 		return e.JSON(http.StatusBadRequest, response)
-	case service.ErrorCodeUserInactive:
+	case service.ErrorCodeUserInactive, service.ErrorCodeUserAbsent:
 		return e.JSON(http.StatusConflict, response)
+	case service.ErrorCodeForbidden:
+		return e.JSON(http.StatusForbidden, response)
 	default:
 		return e.JSON(http.StatusInternalServerError, response)
 	}
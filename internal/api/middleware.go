@@ -3,33 +3,61 @@ package api
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/yakoovad/avito-winter-2025/internal/audit"
 	"github.com/yakoovad/avito-winter-2025/internal/auth"
 	"github.com/yakoovad/avito-winter-2025/internal/service"
 	"github.com/yakoovad/avito-winter-2025/pkg/logger"
 	"go.uber.org/zap"
 	"net/http"
+	"slices"
 	"time"
 )
 
-func AuthMiddleware(types ...auth.TokenType) echo.MiddlewareFunc {
+// RevocationChecker Reports tokens revoked before their exp
+type RevocationChecker interface {
+	IsRevoked(jti string) bool
+}
+
+// AuthMiddleware Accepts tokens of the listed types, any valid token when none are listed.
+// User tokens must carry a subject, tokens revoked by jti are rejected when revoked is set.
+// Tokens without jti cannot be revoked and are rejected unless allowWithoutJTI is set, which is meant only
+// for the transition until tokens issued before jti expire.
+// The principal is attached to the request context, its user ID (the token type for admin tokens
+// without subject) is the actor of audit events.
+func AuthMiddleware(revoked RevocationChecker, allowWithoutJTI bool, types ...auth.TokenType) echo.MiddlewareFunc {
 	return middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
 		Skipper:   middleware.DefaultSkipper,
 		KeyLookup: "header:X-Api-Key,cookie:X-Api-Key,header:Authorization:Bearer ",
 		Validator: func(t string, c echo.Context) (bool, error) {
-			tokenType, valid := auth.IsValidToken(t)
-			if !valid {
+			claims, err := auth.VerifyToken(t)
+			if err != nil {
 				return false, nil
 			}
-			if len(types) == 0 {
-				return true, nil
+			if len(types) > 0 && !slices.Contains(types, claims.Type) {
+				return false, nil
 			}
-
-			for _, tt := range types {
-				if tt == tokenType {
-					return true, nil
-				}
+			if claims.Type == auth.TokenTypeUser && claims.Subject == "" {
+				return false, nil
 			}
-			return false, nil
+			if claims.ID == "" && !allowWithoutJTI {
+				l := logger.FromContext(c.Request().Context())
+				l.Warn("token without jti used", zap.String("sub", claims.Subject))
+				return false, nil
+			}
+			if revoked != nil && claims.ID != "" && revoked.IsRevoked(claims.ID) {
+				l := logger.FromContext(c.Request().Context())
+				l.Warn("revoked token used", zap.String("jti", claims.ID), zap.String("sub", claims.Subject))
+				return false, nil
+			}
+
+			principal := auth.NewPrincipal(claims)
+
+			req := c.Request()
+			ctx := auth.WithPrincipal(req.Context(), principal)
+			ctx = audit.WithActor(ctx, principal.Actor())
+			c.SetRequest(req.WithContext(ctx))
+
+			return true, nil
 		},
 		ErrorHandler: func(err error, c echo.Context) error {
 			l := logger.FromContext(c.Request().Context())
@@ -41,6 +69,22 @@ func AuthMiddleware(types ...auth.TokenType) echo.MiddlewareFunc {
 	})
 }
 
+// RequireScope Rejects callers whose token lacks the scope with 403, it must run after AuthMiddleware
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal := auth.PrincipalFromContext(c.Request().Context())
+			if !principal.HasScope(scope) {
+				l := logger.FromContext(c.Request().Context())
+				l.Warn("token lacks scope", zap.String("scope", scope), zap.String("actor", audit.ActorFromContext(c.Request().Context())))
+
+				return c.JSON(http.StatusForbidden, service.NewError(service.ErrorCodeForbidden, "token lacks scope "+scope))
+			}
+			return next(c)
+		}
+	}
+}
+
 func ZapLoggerMiddleware(l *zap.Logger) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -58,6 +102,7 @@ func ZapLoggerMiddleware(l *zap.Logger) echo.MiddlewareFunc {
 			c.Set("logger", reqLogger)
 
 			ctx := logger.WithLogger(req.Context(), reqLogger)
+			ctx = audit.WithRequestID(ctx, requestID)
 			c.SetRequest(req.WithContext(ctx))
 
 			err := next(c)
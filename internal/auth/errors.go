@@ -6,4 +6,5 @@ var (
 	ErrInvalidToken         = fmt.Errorf("invalid token")
 	ErrExpiredToken         = fmt.Errorf("expired token")
 	ErrInvalidSigningMethod = fmt.Errorf("invalid signing method")
+	ErrInvalidClaims        = fmt.Errorf("invalid token claims")
 )
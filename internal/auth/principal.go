@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+type principalContextKey struct{}
+
+// Principal Caller authenticated by a token
+type Principal struct {
+	Type     TokenType
+	UserID   string
+	TeamName string
+	Scopes   []string
+}
+
+func NewPrincipal(claims *TokenClaims) *Principal {
+	return &Principal{
+		Type:     claims.Type,
+		UserID:   claims.Subject,
+		TeamName: claims.Team,
+		Scopes:   claims.Scopes,
+	}
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p != nil && p.Type == TokenTypeAdmin
+}
+
+// CanAccessUser Admins may access any user, plain users only themselves
+func (p *Principal) CanAccessUser(userID string) bool {
+	if p == nil {
+		return false
+	}
+	return p.IsAdmin() || (p.UserID != "" && p.UserID == userID)
+}
+
+// CanAccessTeam Admins may access any team, plain users only the team of their token
+func (p *Principal) CanAccessTeam(teamName string) bool {
+	if p == nil {
+		return false
+	}
+	return p.IsAdmin() || (p.TeamName != "" && p.TeamName == teamName)
+}
+
+// HasScope Admins and user tokens issued without scopes have every scope
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+	return p.IsAdmin() || len(p.Scopes) == 0 || slices.Contains(p.Scopes, scope)
+}
+
+// Actor Returns the user ID recorded as actor of audit events, the token type for tokens without subject
+func (p *Principal) Actor() string {
+	if p.UserID != "" {
+		return p.UserID
+	}
+	return string(p.Type)
+}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// PrincipalFromContext Returns the caller attached by the auth middleware, nil for unauthenticated calls
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipal_Access(t *testing.T) {
+	tests := []struct {
+		name        string
+		principal   *Principal
+		userID      string
+		teamName    string
+		expectUser  bool
+		expectTeam  bool
+		expectActor string
+		expectScope bool
+	}{
+		{
+			name:        "admin: any user and team",
+			principal:   &Principal{Type: TokenTypeAdmin},
+			userID:      "u2",
+			teamName:    "frontend",
+			expectUser:  true,
+			expectTeam:  true,
+			expectActor: "admin",
+			expectScope: true,
+		},
+		{
+			name:        "user: own reviews and team",
+			principal:   &Principal{Type: TokenTypeUser, UserID: "u1", TeamName: "backend"},
+			userID:      "u1",
+			teamName:    "backend",
+			expectUser:  true,
+			expectTeam:  true,
+			expectActor: "u1",
+			expectScope: true,
+		},
+		{
+			name:        "user: other user and team",
+			principal:   &Principal{Type: TokenTypeUser, UserID: "u1", TeamName: "backend"},
+			userID:      "u2",
+			teamName:    "frontend",
+			expectUser:  false,
+			expectTeam:  false,
+			expectActor: "u1",
+			expectScope: true,
+		},
+		{
+			name:        "user: token without team",
+			principal:   &Principal{Type: TokenTypeUser, UserID: "u1"},
+			userID:      "u1",
+			teamName:    "",
+			expectUser:  true,
+			expectTeam:  false,
+			expectActor: "u1",
+			expectScope: true,
+		},
+		{
+			name:        "user: scoped token without the scope",
+			principal:   &Principal{Type: TokenTypeUser, UserID: "u1", TeamName: "backend", Scopes: []string{ScopeReviewsRead}},
+			userID:      "u1",
+			teamName:    "backend",
+			expectUser:  true,
+			expectTeam:  true,
+			expectActor: "u1",
+			expectScope: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectUser, tt.principal.CanAccessUser(tt.userID))
+			assert.Equal(t, tt.expectTeam, tt.principal.CanAccessTeam(tt.teamName))
+			assert.Equal(t, tt.expectScope, tt.principal.HasScope(ScopeStatsRead))
+			assert.Equal(t, tt.expectActor, tt.principal.Actor())
+		})
+	}
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	TokenSecretKey = testSecretKey
+
+	tokenString, err := GenerateToken(TokenSpec{Type: TokenTypeUser, Subject: "u1", Team: "backend", Scopes: []string{ScopeReviewsRead}, TTL: time.Hour})
+	assert.NoError(t, err)
+	claims, err := VerifyToken(tokenString)
+	assert.NoError(t, err)
+
+	ctx := WithPrincipal(context.Background(), NewPrincipal(claims))
+	assert.Equal(t, &Principal{Type: TokenTypeUser, UserID: "u1", TeamName: "backend", Scopes: []string{ScopeReviewsRead}}, PrincipalFromContext(ctx))
+
+	missing := PrincipalFromContext(context.Background())
+	assert.Nil(t, missing)
+	assert.False(t, missing.IsAdmin())
+	assert.False(t, missing.CanAccessUser("u1"))
+	assert.False(t, missing.CanAccessTeam("backend"))
+}
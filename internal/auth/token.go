@@ -1,9 +1,12 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
 	"os"
+	"slices"
 	"time"
 )
 
@@ -15,19 +18,68 @@ const (
 	TokenTypeAdmin     TokenType = "admin"
 )
 
+// Scopes restrict what a user token may do, a user token without scopes may call every user route
+const (
+	ScopeReviewsRead  = "reviews:read"
+	ScopeReviewsWrite = "reviews:write"
+	ScopeTeamsRead    = "teams:read"
+	ScopeStatsRead    = "stats:read"
+)
+
+var knownScopes = []string{ScopeReviewsRead, ScopeReviewsWrite, ScopeTeamsRead, ScopeStatsRead}
+
 var TokenSecretKey = os.Getenv("TOKEN_AUTH_SECRET")
 
+// TokenClaims Subject (sub) is the user ID of the caller, user tokens must carry it.
+// Team is the team the user may query, without it user tokens have no access to team endpoints.
+// ID (jti) identifies the token for revocation.
 type TokenClaims struct {
-	Type TokenType `json:"type"`
+	Type   TokenType `json:"type"`
+	Team   string    `json:"team,omitempty"`
+	Scopes []string  `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(tokenType TokenType, dur time.Duration) (string, error) {
+// TokenSpec Subject and Team may be empty for admin tokens, Scopes must be empty for them
+type TokenSpec struct {
+	Type    TokenType
+	Subject string
+	Team    string
+	Scopes  []string
+	TTL     time.Duration
+}
+
+// GenerateToken Issues a token with a random ID signed with TokenSecretKey that expires TTL after now
+func GenerateToken(spec TokenSpec) (string, error) {
+	switch {
+	case spec.Type != TokenTypeUser && spec.Type != TokenTypeAdmin:
+		return "", errors.Wrap(ErrInvalidClaims, "unknown token type")
+	case spec.Type == TokenTypeUser && spec.Subject == "":
+		return "", errors.Wrap(ErrInvalidClaims, "user token requires a subject")
+	case spec.Type == TokenTypeAdmin && len(spec.Scopes) > 0:
+		return "", errors.Wrap(ErrInvalidClaims, "admin tokens are not scoped")
+	}
+	for _, scope := range spec.Scopes {
+		if !slices.Contains(knownScopes, scope) {
+			return "", errors.Wrapf(ErrInvalidClaims, "unknown scope %q", scope)
+		}
+	}
+
+	id, err := newTokenID()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate token ID")
+	}
+
+	now := time.Now()
 	claims := TokenClaims{
-		Type: tokenType,
+		Type:   spec.Type,
+		Team:   spec.Team,
+		Scopes: spec.Scopes,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(dur)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        id,
+			Subject:   spec.Subject,
+			ExpiresAt: jwt.NewNumericDate(now.Add(spec.TTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
@@ -35,6 +87,15 @@ func GenerateToken(tokenType TokenType, dur time.Duration) (string, error) {
 	return token.SignedString([]byte(TokenSecretKey))
 }
 
+// DecodeToken Parses the claims without verifying the signature or expiry, use VerifyToken to trust them
+func DecodeToken(tokenString string) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
 func VerifyToken(tokenString string) (*TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -61,3 +122,11 @@ func IsValidToken(tokenString string) (TokenType, bool) {
 	}
 	return claims.Type, true
 }
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
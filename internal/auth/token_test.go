@@ -15,42 +15,84 @@ func TestGenerateToken(t *testing.T) {
 	TokenSecretKey = testSecretKey
 
 	tests := []struct {
-		name      string
-		tokenType TokenType
-		duration  time.Duration
+		name        string
+		spec        TokenSpec
+		expectError bool
 	}{
 		{
-			name:      "success: generate valid user token",
-			tokenType: TokenTypeUser,
-			duration:  time.Hour,
+			name: "success: generate valid user token",
+			spec: TokenSpec{Type: TokenTypeUser, Subject: "u1", Team: "backend", Scopes: []string{"reviews:read"}, TTL: time.Hour},
 		},
 		{
-			name:      "success: generate valid admin token",
-			tokenType: TokenTypeAdmin,
-			duration:  30 * time.Minute,
+			name: "success: generate valid admin token",
+			spec: TokenSpec{Type: TokenTypeAdmin, TTL: 30 * time.Minute},
+		},
+		{
+			name:        "failure: user token without subject",
+			spec:        TokenSpec{Type: TokenTypeUser, Team: "backend", TTL: time.Hour},
+			expectError: true,
+		},
+		{
+			name:        "failure: unknown token type",
+			spec:        TokenSpec{Type: "root", Subject: "u1", TTL: time.Hour},
+			expectError: true,
+		},
+		{
+			name:        "failure: unknown scope",
+			spec:        TokenSpec{Type: TokenTypeUser, Subject: "u1", Scopes: []string{"reviews:delete"}, TTL: time.Hour},
+			expectError: true,
+		},
+		{
+			name:        "failure: scoped admin token",
+			spec:        TokenSpec{Type: TokenTypeAdmin, Scopes: []string{ScopeStatsRead}, TTL: time.Hour},
+			expectError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tokenString, err := GenerateToken(tt.tokenType, tt.duration)
+			tokenString, err := GenerateToken(tt.spec)
+			if tt.expectError {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidClaims)
+				return
+			}
 			require.NoError(t, err)
 			require.NotEmpty(t, tokenString)
 
 			claims, err := VerifyToken(tokenString)
 			require.NoError(t, err)
-			assert.Equal(t, tt.tokenType, claims.Type)
-			assert.WithinDuration(t, time.Now().Add(tt.duration), claims.ExpiresAt.Time, time.Second*5)
+			assert.Equal(t, tt.spec.Type, claims.Type)
+			assert.Equal(t, tt.spec.Subject, claims.Subject)
+			assert.Equal(t, tt.spec.Team, claims.Team)
+			assert.Equal(t, tt.spec.Scopes, claims.Scopes)
+			assert.Len(t, claims.ID, 32)
+			assert.WithinDuration(t, time.Now().Add(tt.spec.TTL), claims.ExpiresAt.Time, time.Second*5)
 		})
 	}
 }
 
+func TestDecodeToken(t *testing.T) {
+	TokenSecretKey = testSecretKey
+
+	expiredToken, _ := GenerateToken(TokenSpec{Type: TokenTypeUser, Subject: "u1", Team: "backend", TTL: -time.Hour})
+
+	claims, err := DecodeToken(expiredToken)
+	require.NoError(t, err)
+	assert.Equal(t, TokenTypeUser, claims.Type)
+	assert.Equal(t, "u1", claims.Subject)
+	assert.True(t, claims.ExpiresAt.Before(time.Now()))
+
+	_, err = DecodeToken("not-a-valid-jwt-token")
+	assert.ErrorIs(t, err, jwt.ErrTokenMalformed)
+}
+
 func TestVerifyToken(t *testing.T) {
 	TokenSecretKey = testSecretKey
 
-	validUserToken, _ := GenerateToken(TokenTypeUser, time.Hour)
+	validUserToken, _ := GenerateToken(TokenSpec{Type: TokenTypeUser, Subject: "u1", Team: "backend", TTL: time.Hour})
 
-	expiredToken, _ := GenerateToken(TokenTypeUser, -time.Hour)
+	expiredToken, _ := GenerateToken(TokenSpec{Type: TokenTypeUser, Subject: "u1", Team: "backend", TTL: -time.Hour})
 
 	claimsWithWrongMethod := TokenClaims{
 		Type: TokenTypeUser,
@@ -131,8 +173,8 @@ func TestVerifyToken(t *testing.T) {
 func TestIsValidToken(t *testing.T) {
 	TokenSecretKey = testSecretKey
 
-	validAdminToken, _ := GenerateToken(TokenTypeAdmin, time.Hour)
-	expiredUserToken, _ := GenerateToken(TokenTypeUser, -time.Hour)
+	validAdminToken, _ := GenerateToken(TokenSpec{Type: TokenTypeAdmin, TTL: time.Hour})
+	expiredUserToken, _ := GenerateToken(TokenSpec{Type: TokenTypeUser, Subject: "u1", Team: "backend", TTL: -time.Hour})
 
 	tests := []struct {
 		name              string
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+// AdvisoryLock Session level Postgres advisory lock used for leader election between replicas.
+// The lock lives on a dedicated pool connection, so it is released by Postgres when that connection is lost.
+type AdvisoryLock struct {
+	pool *pgxpool.Pool
+	key  int64
+
+	mu   sync.Mutex
+	conn *pgxpool.Conn
+}
+
+func NewAdvisoryLock(pool *pgxpool.Pool, key int64) *AdvisoryLock {
+	return &AdvisoryLock{pool: pool, key: key}
+}
+
+// TryLock Reports whether this process holds the lock, taking it when it is free.
+// A holder whose connection was lost gives the lock up and competes for it again.
+func (a *AdvisoryLock) TryLock(ctx context.Context) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.conn != nil {
+		if err := a.conn.Ping(ctx); err == nil {
+			return true, nil
+		}
+		_ = a.conn.Conn().Close(ctx)
+		a.conn.Release()
+		a.conn = nil
+	}
+
+	conn, err := a.pool.Acquire(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to acquire connection")
+	}
+
+	var locked bool
+	if err = conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", a.key).Scan(&locked); err != nil {
+		conn.Release()
+		return false, errors.Wrap(err, "failed to take advisory lock")
+	}
+	if !locked {
+		conn.Release()
+		return false, nil
+	}
+
+	a.conn = conn
+	return true, nil
+}
+
+// Unlock Releases the lock if this process holds it
+func (a *AdvisoryLock) Unlock(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.conn == nil {
+		return nil
+	}
+	defer func() {
+		a.conn.Release()
+		a.conn = nil
+	}()
+
+	if _, err := a.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", a.key); err != nil {
+		// Closing the session releases the lock, the connection must not return to the pool still holding it
+		_ = a.conn.Conn().Close(ctx)
+		return errors.Wrap(err, "failed to release advisory lock")
+	}
+	return nil
+}
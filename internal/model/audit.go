@@ -0,0 +1,72 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionTeamAdd             AuditAction = "team.add"
+	AuditActionTeamDeactivateUsers AuditAction = "team.deactivate_users"
+	AuditActionTeamSetSettings     AuditAction = "team.set_settings"
+	AuditActionTeamSetRules        AuditAction = "team.set_rules"
+
+	AuditActionUserSetIsActive       AuditAction = "user.set_is_active"
+	AuditActionUserSetAvailability   AuditAction = "user.set_availability"
+	AuditActionUserSetMaxOpenReviews AuditAction = "user.set_max_open_reviews"
+
+	AuditActionPRCreate         AuditAction = "pull_request.create"
+	AuditActionPRMerge          AuditAction = "pull_request.merge"
+	AuditActionPRReassign       AuditAction = "pull_request.reassign"
+	AuditActionPRAddReviewer    AuditAction = "pull_request.add_reviewer"
+	AuditActionPRRemoveReviewer AuditAction = "pull_request.remove_reviewer"
+	AuditActionPRReview         AuditAction = "pull_request.review"
+	AuditActionPRMarkReady      AuditAction = "pull_request.mark_ready"
+	AuditActionPRClose          AuditAction = "pull_request.close"
+	AuditActionPRReopen         AuditAction = "pull_request.reopen"
+	AuditActionPRStale          AuditAction = "pull_request.stale"
+
+	AuditActionTokenRevoke AuditAction = "token.revoke"
+)
+
+type AuditEntity string
+
+const (
+	AuditEntityTeam        AuditEntity = "team"
+	AuditEntityUser        AuditEntity = "user"
+	AuditEntityPullRequest AuditEntity = "pull_request"
+	AuditEntityToken       AuditEntity = "token"
+)
+
+// AuditEvent One state change. Before and After hold the entity as the API returns it, null when there is no such state.
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     AuditAction     `json:"action"`
+	EntityType AuditEntity     `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter Events are listed newest first, Cursor is the next_cursor of the previous page. Empty fields match everything.
+type AuditFilter struct {
+	Actor      string      `query:"actor"`
+	Action     AuditAction `query:"action"`
+	EntityType AuditEntity `query:"entity_type" validate:"omitempty,oneof=team user pull_request token"`
+	EntityID   string      `query:"entity_id"`
+	From       time.Time   `query:"from"`
+	To         time.Time   `query:"to"`
+	Cursor     int64       `query:"cursor" validate:"gte=0"`
+	Limit      int         `query:"limit" validate:"gte=0,lte=500"`
+}
+
+// AuditEventPage NextCursor is omitted on the last page
+type AuditEventPage struct {
+	Events     []*AuditEvent `json:"events"`
+	NextCursor *int64        `json:"next_cursor,omitempty"`
+}
@@ -5,18 +5,44 @@ import "time"
 type PRStatus string
 
 const (
+	PRStatusDraft  PRStatus = "DRAFT"
 	PRStatusOpen   PRStatus = "OPEN"
 	PRStatusMerged PRStatus = "MERGED"
+	PRStatusClosed PRStatus = "CLOSED"
 )
 
+type ReviewState string
+
+const (
+	ReviewStatePending          ReviewState = "PENDING"
+	ReviewStateApproved         ReviewState = "APPROVED"
+	ReviewStateChangesRequested ReviewState = "CHANGES_REQUESTED"
+)
+
+// Review Decision of one assigned reviewer
+type Review struct {
+	UserID string      `json:"user_id"`
+	State  ReviewState `json:"state"`
+}
+
 type PullRequest struct {
-	ID        string     `json:"pull_request_id" validate:"required"`
-	Name      string     `json:"pull_request_name" validate:"required"`
-	AuthorID  string     `json:"author_id" validate:"required"`
-	Status    PRStatus   `json:"status" validate:"required"`
-	Reviewers []string   `json:"assigned_reviewers" validate:"required"`
-	CreatedAt *time.Time `json:"createdAt,omitempty"`
-	MergedAt  *time.Time `json:"mergedAt,omitempty"`
+	ID                string     `json:"pull_request_id" validate:"required"`
+	Name              string     `json:"pull_request_name" validate:"required"`
+	AuthorID          string     `json:"author_id" validate:"required"`
+	Status            PRStatus   `json:"status" validate:"required"`
+	Reviewers         []string   `json:"assigned_reviewers" validate:"required"`
+	Reviews           []*Review  `json:"reviews"`
+	NeedMoreReviewers bool       `json:"need_more_reviewers"`
+	ForceMerged       bool       `json:"force_merged,omitempty"`
+	ExternalReviewers []string   `json:"external_reviewers,omitempty"`
+	CreatedAt         *time.Time `json:"createdAt,omitempty"`
+	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+}
+
+// PullRequestReassignment PR after a reviewer was replaced, with the user who took over the review
+type PullRequestReassignment struct {
+	*PullRequest
+	ReplacedBy string `json:"replaced_by"`
 }
 
 type PullRequestShort struct {
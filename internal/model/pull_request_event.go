@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+type PullRequestEventType string
+
+const (
+	PREventCreated            PullRequestEventType = "CREATED"
+	PREventReviewerAssigned   PullRequestEventType = "REVIEWER_ASSIGNED"
+	PREventReviewerUnassigned PullRequestEventType = "REVIEWER_UNASSIGNED"
+	PREventReviewerReassigned PullRequestEventType = "REVIEWER_REASSIGNED"
+	PREventReviewSubmitted    PullRequestEventType = "REVIEW_SUBMITTED"
+	PREventStatusChanged      PullRequestEventType = "STATUS_CHANGED"
+	PREventMerged             PullRequestEventType = "MERGED"
+	// PREventEscalated The PR stayed OPEN without activity longer than the team's SLA
+	PREventEscalated PullRequestEventType = "ESCALATED"
+)
+
+// PullRequestEventReason Why reviewers changed or how the PR was merged
+type PullRequestEventReason string
+
+const (
+	// PREventReasonAuto Reviewers selected on creation, markReady or reopen, or released on close
+	PREventReasonAuto PullRequestEventReason = "AUTO"
+	// PREventReasonManual Reviewer changed through addReviewer, removeReviewer or reassign
+	PREventReasonManual       PullRequestEventReason = "MANUAL"
+	PREventReasonTopUp        PullRequestEventReason = "TOP_UP"
+	PREventReasonDeactivation PullRequestEventReason = "DEACTIVATION"
+	// PREventReasonInactive Deactivated reviewer released when the PR was merged
+	PREventReasonInactive PullRequestEventReason = "INACTIVE"
+	PREventReasonForce    PullRequestEventReason = "FORCE"
+	// PREventReasonStale Change made by the stale PR job
+	PREventReasonStale PullRequestEventReason = "STALE"
+)
+
+// PullRequestEvent One entry of the PR timeline, only the fields relevant to Type are set.
+// Actor is whoever made the request that caused the event.
+type PullRequestEvent struct {
+	ID            int64                  `json:"id"`
+	Type          PullRequestEventType   `json:"type"`
+	Actor         string                 `json:"actor"`
+	ReviewerID    string                 `json:"reviewer_id,omitempty"`
+	NewReviewerID string                 `json:"new_reviewer_id,omitempty"`
+	ReviewState   ReviewState            `json:"review_state,omitempty"`
+	FromStatus    PRStatus               `json:"from_status,omitempty"`
+	ToStatus      PRStatus               `json:"to_status,omitempty"`
+	Reason        PullRequestEventReason `json:"reason,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+type PullRequestHistory struct {
+	PullRequestID string              `json:"pull_request_id"`
+	Events        []*PullRequestEvent `json:"events"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// StatsFilter Narrows statistics down to a team and a time range [from, to), omitted bounds stay open
+type StatsFilter struct {
+	TeamName string    `query:"team_name"`
+	From     time.Time `query:"from"`
+	To       time.Time `query:"to"`
+}
+
+type StatsBucket string
+
+const (
+	StatsBucketDay   StatsBucket = "day"
+	StatsBucketWeek  StatsBucket = "week"
+	StatsBucketMonth StatsBucket = "month"
+)
+
+// PullRequestStatsFilter Bucket defaults to day
+type PullRequestStatsFilter struct {
+	StatsFilter
+	Bucket StatsBucket `query:"bucket" validate:"omitempty,oneof=day week month"`
+}
+
+// ReviewerStats Review work of a single user. Assigned includes the reviews reassigned away from the user.
+type ReviewerStats struct {
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	TeamName       string `json:"team_name"`
+	Assigned       int    `json:"assigned"`
+	Open           int    `json:"open"`
+	Merged         int    `json:"merged"`
+	ReassignedAway int    `json:"reassigned_away"`
+}
+
+type ReviewerStatsReport struct {
+	Reviewers []*ReviewerStats `json:"reviewers"`
+}
+
+// PullRequestStats PR flow of a team within a bucket starting at BucketStart.
+// Merge times are in seconds from created_at to merged_at and are omitted when nothing was merged.
+type PullRequestStats struct {
+	TeamName                 string    `json:"team_name"`
+	BucketStart              time.Time `json:"bucket_start"`
+	Created                  int       `json:"created"`
+	Merged                   int       `json:"merged"`
+	MedianTimeToMergeSeconds *float64  `json:"median_time_to_merge_seconds,omitempty"`
+	P90TimeToMergeSeconds    *float64  `json:"p90_time_to_merge_seconds,omitempty"`
+	AvgReassignments         float64   `json:"avg_reassignments"`
+}
+
+type PullRequestStatsReport struct {
+	Bucket  StatsBucket         `json:"bucket"`
+	Buckets []*PullRequestStats `json:"buckets"`
+}
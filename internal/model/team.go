@@ -5,8 +5,132 @@ type Team struct {
 	Members []*TeamMember `json:"members" validate:"required"`
 }
 
+// TeamMember Role defaults to MIDDLE when omitted
 type TeamMember struct {
-	UserID   string `json:"user_id" validate:"required"`
-	Username string `json:"username" validate:"required"`
-	IsActive bool   `json:"is_active" validate:"required"`
+	UserID   string   `json:"user_id" validate:"required"`
+	Username string   `json:"username" validate:"required"`
+	IsActive bool     `json:"is_active" validate:"required"`
+	Role     UserRole `json:"role" validate:"omitempty,oneof=JUNIOR MIDDLE SENIOR LEAD"`
+	Skills   []string `json:"skills" validate:"dive,required"`
+}
+
+// TeamSettings StaleAfterMinutes is the time an OPEN PR may go without activity before StalePolicy applies, 0 disables it
+type TeamSettings struct {
+	TeamName               string      `json:"team_name" validate:"required"`
+	MinReviewers           int         `json:"min_reviewers" validate:"gte=0"`
+	MaxReviewers           int         `json:"max_reviewers" validate:"gte=0,gtefield=MinReviewers"`
+	LeadID                 string      `json:"lead_id,omitempty"`
+	LeadMandatory          bool        `json:"lead_mandatory"`
+	AllowInactiveReviewers bool        `json:"allow_inactive_reviewers"`
+	RequiredApprovals      int         `json:"required_approvals" validate:"gte=0"`
+	AllowExternalReviewers bool        `json:"allow_external_reviewers"`
+	FallbackTeams          []string    `json:"fallback_teams" validate:"dive,required"`
+	RequireSenior          bool        `json:"require_senior"`
+	StaleAfterMinutes      int         `json:"stale_after_minutes" validate:"gte=0"`
+	StalePolicy            StalePolicy `json:"stale_policy" validate:"omitempty,oneof=ESCALATE ADD_REVIEWER REASSIGN_IDLE"`
+}
+
+// TeamSettingsUpdate Body of /team/settings/set. Omitted fields keep their current value (the default for teams
+// without stored settings), an empty lead_id clears the lead.
+type TeamSettingsUpdate struct {
+	TeamName               string       `json:"team_name" validate:"required"`
+	MinReviewers           *int         `json:"min_reviewers" validate:"omitempty,gte=0"`
+	MaxReviewers           *int         `json:"max_reviewers" validate:"omitempty,gte=0"`
+	LeadID                 *string      `json:"lead_id"`
+	LeadMandatory          *bool        `json:"lead_mandatory"`
+	AllowInactiveReviewers *bool        `json:"allow_inactive_reviewers"`
+	RequiredApprovals      *int         `json:"required_approvals" validate:"omitempty,gte=0"`
+	AllowExternalReviewers *bool        `json:"allow_external_reviewers"`
+	FallbackTeams          *[]string    `json:"fallback_teams" validate:"omitempty,dive,required"`
+	RequireSenior          *bool        `json:"require_senior"`
+	StaleAfterMinutes      *int         `json:"stale_after_minutes" validate:"omitempty,gte=0"`
+	StalePolicy            *StalePolicy `json:"stale_policy" validate:"omitempty,oneof=ESCALATE ADD_REVIEWER REASSIGN_IDLE"`
+}
+
+// Apply Overwrites the fields of settings that are set in the update
+func (u *TeamSettingsUpdate) Apply(settings *TeamSettings) {
+	if u.MinReviewers != nil {
+		settings.MinReviewers = *u.MinReviewers
+	}
+	if u.MaxReviewers != nil {
+		settings.MaxReviewers = *u.MaxReviewers
+	}
+	if u.LeadID != nil {
+		settings.LeadID = *u.LeadID
+	}
+	if u.LeadMandatory != nil {
+		settings.LeadMandatory = *u.LeadMandatory
+	}
+	if u.AllowInactiveReviewers != nil {
+		settings.AllowInactiveReviewers = *u.AllowInactiveReviewers
+	}
+	if u.RequiredApprovals != nil {
+		settings.RequiredApprovals = *u.RequiredApprovals
+	}
+	if u.AllowExternalReviewers != nil {
+		settings.AllowExternalReviewers = *u.AllowExternalReviewers
+	}
+	if u.FallbackTeams != nil {
+		settings.FallbackTeams = *u.FallbackTeams
+	}
+	if u.RequireSenior != nil {
+		settings.RequireSenior = *u.RequireSenior
+	}
+	if u.StaleAfterMinutes != nil {
+		settings.StaleAfterMinutes = *u.StaleAfterMinutes
+	}
+	if u.StalePolicy != nil {
+		settings.StalePolicy = *u.StalePolicy
+	}
+}
+
+// StalePolicy What happens to a PR that stayed OPEN without activity longer than the team allows.
+// ADD_REVIEWER and REASSIGN_IDLE fall back to ESCALATE when no reviewer can be found.
+type StalePolicy string
+
+const (
+	StalePolicyEscalate     StalePolicy = "ESCALATE"
+	StalePolicyAddReviewer  StalePolicy = "ADD_REVIEWER"
+	StalePolicyReassignIdle StalePolicy = "REASSIGN_IDLE"
+)
+
+type ReviewerRuleKind string
+
+const (
+	ReviewerRuleExclude ReviewerRuleKind = "EXCLUDE"
+	ReviewerRulePair    ReviewerRuleKind = "PAIR"
+)
+
+// ReviewerRule EXCLUDE: reviewer is never assigned to PRs authored by target.
+// PAIR: reviewer is only assigned together with target, with several PAIR rules any of the targets will do.
+type ReviewerRule struct {
+	Kind       ReviewerRuleKind `json:"kind" validate:"required,oneof=EXCLUDE PAIR"`
+	ReviewerID string           `json:"reviewer_id" validate:"required"`
+	TargetID   string           `json:"target_id" validate:"required,nefield=ReviewerID"`
+}
+
+// TeamRules Reviewer rules applied when selecting reviewers for PRs of the team's authors
+type TeamRules struct {
+	TeamName string          `json:"team_name" validate:"required"`
+	Rules    []*ReviewerRule `json:"rules" validate:"dive"`
+}
+
+// TeamDeactivation Result of deactivating a group of team members
+type TeamDeactivation struct {
+	TeamName      string                `json:"team_name"`
+	Users         []*User               `json:"users"`
+	Reassignments []*ReviewReassignment `json:"reassignments"`
+}
+
+// DefaultTeamSettings Settings used for teams that never configured their own
+func DefaultTeamSettings(teamName string) *TeamSettings {
+	return &TeamSettings{
+		TeamName:               teamName,
+		MinReviewers:           2,
+		MaxReviewers:           2,
+		AllowInactiveReviewers: true,
+		RequiredApprovals:      1,
+		FallbackTeams:          []string{},
+		StalePolicy:            StalePolicyEscalate,
+	}
 }
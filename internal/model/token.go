@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// RevokedToken ExpiresAt is the exp of the token, nil when the token was revoked by jti alone
+type RevokedToken struct {
+	ID        string     `json:"jti"`
+	Subject   string     `json:"sub,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	RevokedBy string     `json:"revoked_by"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt time.Time  `json:"revoked_at"`
+}
+
+// TokenRevocation Either the token itself or its jti, the token also gives the subject and expiry
+type TokenRevocation struct {
+	Token  string `json:"token" validate:"required_without=ID"`
+	ID     string `json:"jti" validate:"required_without=Token"`
+	Reason string `json:"reason"`
+}
+
+type RevokedTokenList struct {
+	Tokens []*RevokedToken `json:"tokens"`
+}
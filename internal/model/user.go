@@ -1,13 +1,70 @@
 package model
 
+import "time"
+
+type UserRole string
+
+const (
+	UserRoleJunior UserRole = "JUNIOR"
+	UserRoleMiddle UserRole = "MIDDLE"
+	UserRoleSenior UserRole = "SENIOR"
+	UserRoleLead   UserRole = "LEAD"
+)
+
 type User struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
-	TeamName string `json:"team_name"`
+	ID       string   `json:"id"`
+	Username string   `json:"username"`
+	IsActive bool     `json:"is_active"`
+	TeamName string   `json:"team_name"`
+	Role     UserRole `json:"role"`
+	Skills   []string `json:"skills"`
+	// MaxOpenReviews overrides the global cap of OPEN reviews for this user
+	MaxOpenReviews *int `json:"max_open_reviews,omitempty"`
+	// Absent is set while the user is out of office, such users are never picked as reviewers
+	Absent bool `json:"-"`
+}
+
+// Absence Out-of-office window, the user is not picked as reviewer from StartsAt until EndsAt
+type Absence struct {
+	UserID   string    `json:"user_id,omitempty"`
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+}
+
+// UserAvailability All absence windows of a user
+type UserAvailability struct {
+	UserID   string     `json:"user_id" validate:"required"`
+	Absences []*Absence `json:"absences" validate:"dive"`
+}
+
+// TeamAbsences Current and upcoming absences of team members
+type TeamAbsences struct {
+	TeamName string     `json:"team_name"`
+	Absences []*Absence `json:"absences"`
 }
 
 type UserReviews struct {
 	UserID       string              `json:"user_id"`
 	PullRequests []*PullRequestShort `json:"pull_requests"`
 }
+
+type ReassignmentStatus string
+
+const (
+	ReassignmentStatusReassigned  ReassignmentStatus = "REASSIGNED"
+	ReassignmentStatusNoCandidate ReassignmentStatus = "NO_CANDIDATE"
+)
+
+// ReviewReassignment Outcome of moving one review away from a deactivated user
+type ReviewReassignment struct {
+	PullRequestID string             `json:"pull_request_id"`
+	OldReviewerID string             `json:"old_reviewer_id"`
+	NewReviewerID string             `json:"new_reviewer_id,omitempty"`
+	Status        ReassignmentStatus `json:"status"`
+}
+
+// UserActivation User after an is_active change, with reassigned reviews when requested
+type UserActivation struct {
+	*User
+	Reassignments []*ReviewReassignment `json:"reassignments,omitempty"`
+}
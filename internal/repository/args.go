@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/stephenafamo/bob"
+	"github.com/stephenafamo/bob/dialect/psql"
+)
+
+// argList Builds a parenthesized list of placeholders to be used with IN
+func argList(values []string) bob.Expression {
+	args := make([]any, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return psql.Arg(args...)
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stephenafamo/bob/dialect/psql"
+	"github.com/stephenafamo/bob/dialect/psql/im"
+	"github.com/stephenafamo/bob/dialect/psql/sm"
+	"github.com/yakoovad/avito-winter-2025/internal/db"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"time"
+)
+
+// AuditEvent Before and After are JSON documents, nil is stored as NULL
+type AuditEvent struct {
+	ID         int64             `db:"id"`
+	Actor      string            `db:"actor"`
+	Action     model.AuditAction `db:"action"`
+	EntityType model.AuditEntity `db:"entity_type"`
+	EntityID   string            `db:"entity_id"`
+	Before     []byte            `db:"before"`
+	After      []byte            `db:"after"`
+	RequestID  string            `db:"request_id"`
+	CreatedAt  time.Time         `db:"created_at"`
+}
+
+// AuditFilter Zero values match everything, BeforeID restricts the result to older events
+type AuditFilter struct {
+	Actor      string
+	Action     model.AuditAction
+	EntityType model.AuditEntity
+	EntityID   string
+	From       time.Time
+	To         time.Time
+	BeforeID   int64
+	Limit      int
+}
+
+type AuditRepository interface {
+	Append(ctx context.Context, event *AuditEvent) error
+	List(ctx context.Context, filter *AuditFilter) ([]*AuditEvent, error)
+}
+
+type pgxAuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxAuditRepository(pool *pgxpool.Pool) AuditRepository {
+	return &pgxAuditRepository{pool: pool}
+}
+
+// Append Inserts the event and fills its ID and CreatedAt. Call it within the transaction of the change being audited.
+func (p *pgxAuditRepository) Append(ctx context.Context, event *AuditEvent) error {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Insert(
+		im.Into("audit_event", "actor", "action", "entity_type", "entity_id", "before", "after", "request_id"),
+		im.Values(
+			psql.Arg(event.Actor),
+			psql.Arg(event.Action),
+			psql.Arg(event.EntityType),
+			psql.Arg(event.EntityID),
+			psql.Arg(event.Before),
+			psql.Arg(event.After),
+			psql.Arg(event.RequestID),
+		),
+		im.Returning("id", "created_at"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	return e.QueryRow(ctx, sql, args...).Scan(&event.ID, &event.CreatedAt)
+}
+
+// List Returns up to filter.Limit matching events ordered by ID descending, i.e. newest first
+func (p *pgxAuditRepository) List(ctx context.Context, filter *AuditFilter) ([]*AuditEvent, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	conds := between(psql.Quote("created_at"), filter.From, filter.To)
+	if filter.Actor != "" {
+		conds = append(conds, psql.Quote("actor").EQ(psql.Arg(filter.Actor)))
+	}
+	if filter.Action != "" {
+		conds = append(conds, psql.Quote("action").EQ(psql.Arg(filter.Action)))
+	}
+	if filter.EntityType != "" {
+		conds = append(conds, psql.Quote("entity_type").EQ(psql.Arg(filter.EntityType)))
+	}
+	if filter.EntityID != "" {
+		conds = append(conds, psql.Quote("entity_id").EQ(psql.Arg(filter.EntityID)))
+	}
+	if filter.BeforeID > 0 {
+		conds = append(conds, psql.Quote("id").LT(psql.Arg(filter.BeforeID)))
+	}
+
+	q := psql.Select(
+		sm.Columns("id", "actor", "action", "entity_type", "entity_id", "before", "after", "request_id", "created_at"),
+		sm.From("audit_event"),
+		sm.OrderBy(psql.Quote("id")).Desc(),
+		sm.Limit(filter.Limit),
+	)
+	if len(conds) > 0 {
+		q.Apply(sm.Where(psql.And(conds...)))
+	}
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*AuditEvent, error) {
+		ev := &AuditEvent{}
+		if err := row.Scan(&ev.ID, &ev.Actor, &ev.Action, &ev.EntityType, &ev.EntityID, &ev.Before, &ev.After, &ev.RequestID, &ev.CreatedAt); err != nil {
+			return nil, err
+		}
+		return ev, nil
+	})
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stephenafamo/bob/dialect/psql"
+	"github.com/stephenafamo/bob/dialect/psql/im"
+	"github.com/stephenafamo/bob/dialect/psql/sm"
+	"github.com/yakoovad/avito-winter-2025/internal/db"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"time"
+)
+
+// PullRequestEvent Fields that do not apply to the event type are empty strings
+type PullRequestEvent struct {
+	ID            int64                        `db:"id"`
+	PullRequestID string                       `db:"pull_request_id"`
+	Type          model.PullRequestEventType   `db:"type"`
+	Actor         string                       `db:"actor"`
+	ReviewerID    string                       `db:"reviewer_id"`
+	NewReviewerID string                       `db:"new_reviewer_id"`
+	ReviewState   model.ReviewState            `db:"review_state"`
+	FromStatus    model.PRStatus               `db:"from_status"`
+	ToStatus      model.PRStatus               `db:"to_status"`
+	Reason        model.PullRequestEventReason `db:"reason"`
+	CreatedAt     time.Time                    `db:"created_at"`
+}
+
+type PullRequestEventRepository interface {
+	Append(ctx context.Context, events []*PullRequestEvent) error
+	List(ctx context.Context, prID string) ([]*PullRequestEvent, error)
+}
+
+type pgxPullRequestEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxPullRequestEventRepository(pool *pgxpool.Pool) PullRequestEventRepository {
+	return &pgxPullRequestEventRepository{pool: pool}
+}
+
+// Append Inserts the events in the given order with a single statement
+func (p *pgxPullRequestEventRepository) Append(ctx context.Context, events []*PullRequestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Insert(
+		im.Into("pull_request_event", "pull_request_id", "type", "actor", "reviewer_id", "new_reviewer_id",
+			"review_state", "from_status", "to_status", "reason"),
+	)
+
+	for _, ev := range events {
+		q.Apply(im.Values(
+			psql.Arg(ev.PullRequestID),
+			psql.Arg(ev.Type),
+			psql.Arg(ev.Actor),
+			psql.Arg(ev.ReviewerID),
+			psql.Arg(ev.NewReviewerID),
+			psql.Arg(ev.ReviewState),
+			psql.Arg(ev.FromStatus),
+			psql.Arg(ev.ToStatus),
+			psql.Arg(ev.Reason),
+		))
+	}
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err = e.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// List Returns the events of the PR in the order they were recorded
+func (p *pgxPullRequestEventRepository) List(ctx context.Context, prID string) ([]*PullRequestEvent, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("id", "pull_request_id", "type", "actor", "reviewer_id", "new_reviewer_id",
+			"review_state", "from_status", "to_status", "reason", "created_at"),
+		sm.From("pull_request_event"),
+		sm.Where(psql.Quote("pull_request_id").EQ(psql.Arg(prID))),
+		sm.OrderBy(psql.Quote("id")),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*PullRequestEvent, error) {
+		ev := &PullRequestEvent{}
+		if err := row.Scan(&ev.ID, &ev.PullRequestID, &ev.Type, &ev.Actor, &ev.ReviewerID, &ev.NewReviewerID,
+			&ev.ReviewState, &ev.FromStatus, &ev.ToStatus, &ev.Reason, &ev.CreatedAt); err != nil {
+			return nil, err
+		}
+		return ev, nil
+	})
+}
@@ -23,6 +23,7 @@ type PullRequest struct {
 	AuthorID          string         `db:"author_id"`
 	Status            model.PRStatus `db:"status"`
 	NeedMoreReviewers bool           `db:"need_more_reviewers"`
+	ForceMerged       bool           `db:"force_merged"`
 	CreatedAt         *time.Time     `db:"created_at"`
 	MergedAt          *time.Time     `db:"merged_at"`
 }
@@ -33,14 +34,29 @@ type PullRequestPatch struct {
 	AuthorID          *string         `db:"author_id"`
 	Status            *model.PRStatus `db:"status"`
 	NeedMoreReviewers *bool           `db:"need_more_reviewers"`
+	ForceMerged       *bool           `db:"force_merged"`
+}
+
+// StalePullRequest OPEN PR whose last activity is older than the SLA of its author's team
+type StalePullRequest struct {
+	ID             string    `db:"id"`
+	AuthorID       string    `db:"author_id"`
+	TeamName       string    `db:"team_name"`
+	LastActivityAt time.Time `db:"last_activity_at"`
 }
 
 type PullRequestRepository interface {
 	Create(ctx context.Context, pr *PullRequest) error
 	Patch(ctx context.Context, pr *PullRequestPatch) (*PullRequest, error)
 	Get(ctx context.Context, prID string) (*PullRequest, error)
+	GetForUpdate(ctx context.Context, prID string) (*PullRequest, error)
 	GetReviewers(ctx context.Context, prID string) ([]string, error)
 	GetReviewPRs(ctx context.Context, userID string) ([]*PullRequest, error)
+	GetNeedMoreReviewers(ctx context.Context, teamName string) ([]*PullRequest, error)
+	GetOpenReviewPRs(ctx context.Context, userIDs []string) ([]*PullRequest, error)
+	GetReviewersOf(ctx context.Context, prIDs []string) (map[string][]string, error)
+	SetNeedMoreReviewers(ctx context.Context, prIDs []string, needMore bool) error
+	GetStale(ctx context.Context, now time.Time) ([]*StalePullRequest, error)
 }
 
 type pgxPullRequestRepository struct {
@@ -115,19 +131,189 @@ func (p *pgxPullRequestRepository) GetReviewers(ctx context.Context, prID string
 		err = row.Scan(&id)
 		return id, err
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reviewers, nil
+}
+
+// GetNeedMoreReviewers Returns OPEN pull requests flagged with need_more_reviewers whose author is in the team.
+// Returned rows are locked for update until the end of the transaction.
+func (p *pgxPullRequestRepository) GetNeedMoreReviewers(ctx context.Context, teamName string) ([]*PullRequest, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns(
+			psql.Quote("pull_request", "id"),
+			psql.Quote("pull_request", "name"),
+			psql.Quote("pull_request", "author_id"),
+			psql.Quote("pull_request", "status"),
+			psql.Quote("pull_request", "need_more_reviewers"),
+			psql.Quote("pull_request", "created_at"),
+			psql.Quote("pull_request", "merged_at"),
+		),
+		sm.From("pull_request"),
+		sm.InnerJoin("users").On(psql.Quote("pull_request", "author_id").EQ(psql.Quote("users", "id"))),
+		sm.Where(
+			psql.Quote("users", "team_name").EQ(psql.Arg(teamName)).
+				And(psql.Quote("pull_request", "status").EQ(psql.Arg(model.PRStatusOpen))).
+				And(psql.Quote("pull_request", "need_more_reviewers")),
+		),
+		sm.OrderBy(psql.Quote("pull_request", "created_at")),
+		sm.ForUpdate("pull_request"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*PullRequest, error) {
+		pr := &PullRequest{}
+		if err = row.Scan(
+			&pr.ID,
+			&pr.Name,
+			&pr.AuthorID,
+			&pr.Status,
+			&pr.NeedMoreReviewers,
+			&pr.CreatedAt,
+			&pr.MergedAt,
+		); err != nil {
+			return nil, err
+		}
+		return pr, nil
+	})
+}
+
+// GetOpenReviewPRs Returns OPEN pull requests reviewed by any of the users.
+// Returned rows are locked for update until the end of the transaction.
+func (p *pgxPullRequestRepository) GetOpenReviewPRs(ctx context.Context, userIDs []string) ([]*PullRequest, error) {
+	if len(userIDs) == 0 {
+		return []*PullRequest{}, nil
+	}
+
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("id", "name", "author_id", "status", "need_more_reviewers", "created_at", "merged_at"),
+		sm.From("pull_request"),
+		sm.Where(
+			psql.Quote("id").In(psql.Select(
+				sm.Columns("pull_request_id"),
+				sm.From("review"),
+				sm.Where(psql.Quote("user_id").In(argList(userIDs))),
+			)).And(psql.Quote("status").EQ(psql.Arg(model.PRStatusOpen))),
+		),
+		sm.OrderBy("created_at"),
+		sm.OrderBy("id"),
+		sm.ForUpdate("pull_request"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*PullRequest, error) {
+		pr := &PullRequest{}
+		if err = row.Scan(
+			&pr.ID,
+			&pr.Name,
+			&pr.AuthorID,
+			&pr.Status,
+			&pr.NeedMoreReviewers,
+			&pr.CreatedAt,
+			&pr.MergedAt,
+		); err != nil {
+			return nil, err
+		}
+		return pr, nil
+	})
+}
+
+// GetReviewersOf Returns reviewers of every listed pull request keyed by pull request ID.
+// Pull requests without reviewers are omitted.
+func (p *pgxPullRequestRepository) GetReviewersOf(ctx context.Context, prIDs []string) (map[string][]string, error) {
+	reviewers := make(map[string][]string, len(prIDs))
+	if len(prIDs) == 0 {
+		return reviewers, nil
+	}
+
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("pull_request_id", "user_id"),
+		sm.From("review"),
+		sm.Where(psql.Quote("pull_request_id").In(argList(prIDs))),
+		sm.OrderBy("pull_request_id"),
+		sm.OrderBy("user_id"),
+		sm.ForShare("review"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	if len(reviewers) == 0 {
-		return nil, ErrNotFound
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prID, userID string
+	_, err = pgx.ForEachRow(rows, []any{&prID, &userID}, func() error {
+		reviewers[prID] = append(reviewers[prID], userID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return reviewers, nil
 }
 
+// SetNeedMoreReviewers Updates need_more_reviewers of all listed pull requests with a single statement
+func (p *pgxPullRequestRepository) SetNeedMoreReviewers(ctx context.Context, prIDs []string, needMore bool) error {
+	if len(prIDs) == 0 {
+		return nil
+	}
+
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Update(
+		um.Table("pull_request"),
+		um.SetCol("need_more_reviewers").ToArg(needMore),
+		um.Where(psql.Quote("id").In(argList(prIDs))),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.Exec(ctx, sql, args...)
+	return err
+}
+
 func (p *pgxPullRequestRepository) Get(ctx context.Context, prID string) (*PullRequest, error) {
 	e := db.GetPgxExecutorFromContext(ctx, p.pool)
 
 	q := psql.Select(
-		sm.Columns("id", "name", "author_id", "status", "need_more_reviewers", "created_at", "merged_at"),
+		sm.Columns("id", "name", "author_id", "status", "need_more_reviewers", "force_merged", "created_at", "merged_at"),
 		sm.From("pull_request"),
 		sm.Where(psql.Quote("id").EQ(psql.Arg(prID))),
 		sm.ForShare("pull_request"),
@@ -145,6 +331,42 @@ func (p *pgxPullRequestRepository) Get(ctx context.Context, prID string) (*PullR
 		&pr.AuthorID,
 		&pr.Status,
 		&pr.NeedMoreReviewers,
+		&pr.ForceMerged,
+		&pr.CreatedAt,
+		&pr.MergedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return pr, nil
+}
+
+// GetForUpdate Same as Get but the row stays locked for update until the end of the transaction
+func (p *pgxPullRequestRepository) GetForUpdate(ctx context.Context, prID string) (*PullRequest, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("id", "name", "author_id", "status", "need_more_reviewers", "force_merged", "created_at", "merged_at"),
+		sm.From("pull_request"),
+		sm.Where(psql.Quote("id").EQ(psql.Arg(prID))),
+		sm.ForUpdate("pull_request"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pr := &PullRequest{}
+	if err = e.QueryRow(ctx, sql, args...).Scan(
+		&pr.ID,
+		&pr.Name,
+		&pr.AuthorID,
+		&pr.Status,
+		&pr.NeedMoreReviewers,
+		&pr.ForceMerged,
 		&pr.CreatedAt,
 		&pr.MergedAt,
 	); err != nil {
@@ -192,6 +414,7 @@ func (p *pgxPullRequestRepository) Create(ctx context.Context, pr *PullRequest)
 	return err
 }
 
+// Patch Updates the set fields, setting status to MERGED also stamps merged_at unless it is already set
 func (p *pgxPullRequestRepository) Patch(ctx context.Context, patch *PullRequestPatch) (*PullRequest, error) {
 	e := db.GetPgxExecutorFromContext(ctx, p.pool)
 
@@ -201,15 +424,21 @@ func (p *pgxPullRequestRepository) Patch(ctx context.Context, patch *PullRequest
 	}
 	if patch.Status != nil {
 		sets = append(sets, um.SetCol("status").ToArg(*patch.Status))
+		if *patch.Status == model.PRStatusMerged {
+			sets = append(sets, um.SetCol("merged_at").To(psql.F("COALESCE", psql.Quote("merged_at"), psql.Raw("NOW()"))))
+		}
 	}
 	if patch.NeedMoreReviewers != nil {
 		sets = append(sets, um.SetCol("need_more_reviewers").ToArg(*patch.NeedMoreReviewers))
 	}
+	if patch.ForceMerged != nil {
+		sets = append(sets, um.SetCol("force_merged").ToArg(*patch.ForceMerged))
+	}
 
 	q := psql.Update(
 		um.Table("pull_request"),
 		um.Where(psql.Quote("id").EQ(psql.Arg(patch.ID))),
-		um.Returning("id", "name", "status", "author_id", "need_more_reviewers", "created_at", "merged_at"),
+		um.Returning("id", "name", "status", "author_id", "need_more_reviewers", "force_merged", "created_at", "merged_at"),
 	)
 
 	q.Apply(sets...)
@@ -226,6 +455,7 @@ func (p *pgxPullRequestRepository) Patch(ctx context.Context, patch *PullRequest
 		&pr.Status,
 		&pr.AuthorID,
 		&pr.NeedMoreReviewers,
+		&pr.ForceMerged,
 		&pr.CreatedAt,
 		&pr.MergedAt,
 	); err != nil {
@@ -236,3 +466,54 @@ func (p *pgxPullRequestRepository) Patch(ctx context.Context, patch *PullRequest
 	}
 	return pr, nil
 }
+
+// GetStale Returns OPEN pull requests whose author's team has stale_after_minutes set and that had no activity
+// for longer than that at now, least recently active first. Activity is the latest PR event or the creation time.
+func (p *pgxPullRequestRepository) GetStale(ctx context.Context, now time.Time) ([]*StalePullRequest, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	lastEvent := psql.Select(
+		sm.Columns(psql.F("MAX", psql.Quote("ev", "created_at"))()),
+		sm.From("pull_request_event").As("ev"),
+		sm.Where(psql.Quote("ev", "pull_request_id").EQ(psql.Quote("pr", "id"))),
+	)
+	lastActivity := psql.F("GREATEST", psql.Quote("pr", "created_at"), psql.Group(lastEvent))()
+
+	q := psql.Select(
+		sm.Columns(
+			psql.Quote("pr", "id"),
+			psql.Quote("pr", "author_id"),
+			psql.Quote("u", "team_name"),
+			lastActivity,
+		),
+		sm.From("pull_request").As("pr"),
+		sm.InnerJoin("users").As("u").OnEQ(psql.Quote("u", "id"), psql.Quote("pr", "author_id")),
+		sm.InnerJoin("team_settings").As("ts").OnEQ(psql.Quote("ts", "team_name"), psql.Quote("u", "team_name")),
+		sm.Where(
+			psql.Quote("pr", "status").EQ(psql.Arg(model.PRStatusOpen)).
+				And(psql.Quote("ts", "stale_after_minutes").GT(psql.Raw("0"))).
+				And(lastActivity.LT(psql.Cast(psql.Arg(now), "TIMESTAMPTZ").Minus(psql.Raw(`"ts"."stale_after_minutes" * INTERVAL '1 minute'`)))),
+		),
+		sm.OrderBy(psql.Raw("4")),
+		sm.OrderBy(psql.Quote("pr", "id")),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*StalePullRequest, error) {
+		pr := &StalePullRequest{}
+		if err := row.Scan(&pr.ID, &pr.AuthorID, &pr.TeamName, &pr.LastActivityAt); err != nil {
+			return nil, err
+		}
+		return pr, nil
+	})
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+)
+
+// testPool Connects to the database from TEST_DATABASE_URL, the schema must be migrated with goose beforehand
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	require.NoError(t, pool.Ping(context.Background()))
+	return pool
+}
+
+// createTestPR Inserts a team, its author and an OPEN pull request, all removed when the test ends
+func createTestPR(t *testing.T, pool *pgxpool.Pool) *PullRequest {
+	t.Helper()
+	ctx := context.Background()
+
+	suffix := fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano())
+	team := &Team{Name: "team-" + suffix}
+	author := &User{ID: "user-" + suffix, Username: "author", IsActive: true, TeamName: team.Name, Role: model.UserRoleMiddle, Skills: []string{}}
+	pr := &PullRequest{ID: "pr-" + suffix, Name: "feat: merge", AuthorID: author.ID, Status: model.PRStatusOpen}
+
+	require.NoError(t, NewPgxTeamRepository(pool).Create(ctx, team))
+	require.NoError(t, NewPgxUserRepository(pool).Upsert(ctx, author))
+	require.NoError(t, NewPgxPullRequestRepository(pool).Create(ctx, pr))
+
+	t.Cleanup(func() {
+		_, _ = pool.Exec(ctx, "DELETE FROM pull_request WHERE id = $1", pr.ID)
+		_, _ = pool.Exec(ctx, "DELETE FROM users WHERE id = $1", author.ID)
+		_, _ = pool.Exec(ctx, "DELETE FROM team WHERE name = $1", team.Name)
+	})
+
+	return pr
+}
+
+func TestPgxPullRequestRepository_PatchMerge(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	repo := NewPgxPullRequestRepository(pool)
+
+	pr := createTestPR(t, pool)
+	assert.Nil(t, pr.MergedAt)
+
+	status := model.PRStatusMerged
+
+	first, err := repo.Patch(ctx, &PullRequestPatch{ID: pr.ID, Status: &status})
+	require.NoError(t, err)
+	assert.Equal(t, model.PRStatusMerged, first.Status)
+	require.NotNil(t, first.MergedAt, "first merge must stamp merged_at")
+
+	second, err := repo.Patch(ctx, &PullRequestPatch{ID: pr.ID, Status: &status})
+	require.NoError(t, err)
+	require.NotNil(t, second.MergedAt)
+	assert.True(t, first.MergedAt.Equal(*second.MergedAt), "repeated merge must keep the original merged_at")
+
+	stored, err := repo.Get(ctx, pr.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.MergedAt)
+	assert.True(t, first.MergedAt.Equal(*stored.MergedAt))
+}
+
+func TestPgxPullRequestRepository_PatchWithoutStatus(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	repo := NewPgxPullRequestRepository(pool)
+
+	pr := createTestPR(t, pool)
+
+	needMore := true
+	got, err := repo.Patch(ctx, &PullRequestPatch{ID: pr.ID, NeedMoreReviewers: &needMore})
+	require.NoError(t, err)
+	assert.Equal(t, model.PRStatusOpen, got.Status)
+	assert.Nil(t, got.MergedAt, "only merging may stamp merged_at")
+}
+
+func TestPgxPullRequestRepository_PatchNotFound(t *testing.T) {
+	pool := testPool(t)
+
+	status := model.PRStatusMerged
+	_, err := NewPgxPullRequestRepository(pool).Patch(context.Background(), &PullRequestPatch{ID: "missing-pr", Status: &status})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPgxPullRequestRepository_GetStale(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	repo := NewPgxPullRequestRepository(pool)
+
+	pr := createTestPR(t, pool)
+	author, err := NewPgxUserRepository(pool).Get(ctx, pr.AuthorID)
+	require.NoError(t, err)
+
+	isStale := func() bool {
+		stale, err := repo.GetStale(ctx, time.Now())
+		require.NoError(t, err)
+		return slices.ContainsFunc(stale, func(s *StalePullRequest) bool { return s.ID == pr.ID })
+	}
+
+	_, err = pool.Exec(ctx, "UPDATE pull_request SET created_at = NOW() - INTERVAL '3 hours' WHERE id = $1", pr.ID)
+	require.NoError(t, err)
+	assert.False(t, isStale(), "teams without stale_after_minutes are not checked")
+
+	require.NoError(t, NewPgxTeamRepository(pool).UpsertSettings(ctx, &TeamSettings{
+		TeamName:          author.TeamName,
+		FallbackTeams:     []string{},
+		StaleAfterMinutes: 60,
+		StalePolicy:       model.StalePolicyEscalate,
+	}))
+	t.Cleanup(func() {
+		_, _ = pool.Exec(ctx, "DELETE FROM team_settings WHERE team_name = $1", author.TeamName)
+	})
+	assert.True(t, isStale())
+
+	require.NoError(t, NewPgxPullRequestEventRepository(pool).Append(ctx, []*PullRequestEvent{
+		{PullRequestID: pr.ID, Type: model.PREventEscalated, Reason: model.PREventReasonStale},
+	}))
+	assert.False(t, isStale(), "any PR event counts as activity")
+}
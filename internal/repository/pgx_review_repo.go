@@ -2,16 +2,41 @@ package repository
 
 import (
 	"context"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
 	"github.com/stephenafamo/bob/dialect/psql"
 	"github.com/stephenafamo/bob/dialect/psql/dm"
 	"github.com/stephenafamo/bob/dialect/psql/im"
+	"github.com/stephenafamo/bob/dialect/psql/sm"
+	"github.com/stephenafamo/bob/dialect/psql/um"
 	"github.com/yakoovad/avito-winter-2025/internal/db"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
 )
 
+type Review struct {
+	UserID        string            `db:"user_id"`
+	PullRequestID string            `db:"pull_request_id"`
+	State         model.ReviewState `db:"state"`
+}
+
+// Reassignment A reviewer taken off a PR in favor of NewReviewerID, nil when nobody replaced them
+type Reassignment struct {
+	PullRequestID string  `db:"pull_request_id"`
+	OldReviewerID string  `db:"old_reviewer_id"`
+	NewReviewerID *string `db:"new_reviewer_id"`
+}
+
 type ReviewRepository interface {
 	Assign(ctx context.Context, prID string, reviewerIDs []string) error
+	AssignMany(ctx context.Context, reviews []*Review) error
 	Unassign(ctx context.Context, prID string, reviewerIDs string) error
+	UnassignMany(ctx context.Context, prIDs []string, reviewerIDs []string) error
+	GetReviews(ctx context.Context, prID string) ([]*Review, error)
+	SetState(ctx context.Context, prID, reviewerID string, state model.ReviewState) error
+	CountTeamOpenReviews(ctx context.Context, teamName string) (map[string]int, error)
+	PeekTeamOpenReviews(ctx context.Context, teamName string) (map[string]int, error)
+	RecordReassignments(ctx context.Context, reassignments []*Reassignment) error
 }
 type pgxReviewRepository struct {
 	pool *pgxpool.Pool
@@ -22,6 +47,10 @@ func NewPgxReviewRepository(pool *pgxpool.Pool) ReviewRepository {
 }
 
 func (p *pgxReviewRepository) Assign(ctx context.Context, prID string, reviewerIDs []string) error {
+	if len(reviewerIDs) == 0 {
+		return nil
+	}
+
 	e := db.GetPgxExecutorFromContext(ctx, p.pool)
 
 	q := psql.Insert(
@@ -44,6 +73,59 @@ func (p *pgxReviewRepository) Assign(ctx context.Context, prID string, reviewerI
 	return nil
 }
 
+// AssignMany Inserts reviews of several pull requests with a single statement
+func (p *pgxReviewRepository) AssignMany(ctx context.Context, reviews []*Review) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Insert(
+		im.Into("review", "user_id", "pull_request_id"),
+	)
+
+	for _, review := range reviews {
+		q.Apply(im.Values(psql.Arg(review.UserID), psql.Arg(review.PullRequestID)))
+	}
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err = e.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RecordReassignments Appends the reassignments to review_reassignment with a single statement
+func (p *pgxReviewRepository) RecordReassignments(ctx context.Context, reassignments []*Reassignment) error {
+	if len(reassignments) == 0 {
+		return nil
+	}
+
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Insert(
+		im.Into("review_reassignment", "pull_request_id", "old_reviewer_id", "new_reviewer_id"),
+	)
+
+	for _, r := range reassignments {
+		q.Apply(im.Values(psql.Arg(r.PullRequestID), psql.Arg(r.OldReviewerID), psql.Arg(r.NewReviewerID)))
+	}
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.Exec(ctx, sql, args...)
+	return err
+}
+
 func (p *pgxReviewRepository) Unassign(ctx context.Context, prID string, reviewerID string) error {
 	e := db.GetPgxExecutorFromContext(ctx, p.pool)
 
@@ -70,3 +152,165 @@ func (p *pgxReviewRepository) Unassign(ctx context.Context, prID string, reviewe
 
 	return nil
 }
+
+// UnassignMany Removes every review of the listed reviewers on the listed pull requests with a single statement
+func (p *pgxReviewRepository) UnassignMany(ctx context.Context, prIDs []string, reviewerIDs []string) error {
+	if len(prIDs) == 0 || len(reviewerIDs) == 0 {
+		return nil
+	}
+
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Delete(
+		dm.From("review"),
+		dm.Where(
+			psql.Quote("pull_request_id").In(argList(prIDs)).
+				And(psql.Quote("user_id").In(argList(reviewerIDs))),
+		))
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.Exec(ctx, sql, args...)
+	return err
+}
+
+// GetReviews Returns reviews of the pull request ordered by reviewer ID
+func (p *pgxReviewRepository) GetReviews(ctx context.Context, prID string) ([]*Review, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("user_id", "pull_request_id", "state"),
+		sm.From("review"),
+		sm.Where(psql.Quote("pull_request_id").EQ(psql.Arg(prID))),
+		sm.OrderBy("user_id"),
+		sm.ForShare("review"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Review, error) {
+		review := &Review{}
+		if err = row.Scan(&review.UserID, &review.PullRequestID, &review.State); err != nil {
+			return nil, err
+		}
+		return review, nil
+	})
+}
+
+// SetState Returns ErrNotFound when the reviewer is not assigned to the pull request
+func (p *pgxReviewRepository) SetState(ctx context.Context, prID, reviewerID string, state model.ReviewState) error {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Update(
+		um.Table("review"),
+		um.SetCol("state").ToArg(state),
+		um.Where(
+			psql.Quote("pull_request_id").EQ(psql.Arg(prID)).
+				And(psql.Quote("user_id").EQ(psql.Arg(reviewerID))),
+		),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := e.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CountTeamOpenReviews Returns the number of OPEN pull requests each member of the team reviews.
+// The team row is locked first, so concurrent callers for the same team are serialized until
+// the transaction ends, and the counting statement sees reviews committed by the previous holder.
+// Must be called within a transaction, members without open reviews are omitted.
+func (p *pgxReviewRepository) CountTeamOpenReviews(ctx context.Context, teamName string) (map[string]int, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	lock := psql.Select(
+		sm.Columns("name"),
+		sm.From("team"),
+		sm.Where(psql.Quote("name").EQ(psql.Arg(teamName))),
+		sm.ForNoKeyUpdate("team"),
+	)
+
+	sql, args, err := lock.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var name string
+	if err = e.QueryRow(ctx, sql, args...).Scan(&name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return countTeamOpenReviews(ctx, e, teamName)
+}
+
+// PeekTeamOpenReviews Same as CountTeamOpenReviews without locking the team, so the counts may change
+// before the transaction ends. Used where another team is already locked and a second lock could deadlock.
+func (p *pgxReviewRepository) PeekTeamOpenReviews(ctx context.Context, teamName string) (map[string]int, error) {
+	return countTeamOpenReviews(ctx, db.GetPgxExecutorFromContext(ctx, p.pool), teamName)
+}
+
+func countTeamOpenReviews(ctx context.Context, e db.Executor, teamName string) (map[string]int, error) {
+	q := psql.Select(
+		sm.Columns(psql.Quote("review", "user_id"), psql.F("COUNT", "*")),
+		sm.From("review"),
+		sm.InnerJoin("pull_request").On(psql.Quote("review", "pull_request_id").EQ(psql.Quote("pull_request", "id"))),
+		sm.InnerJoin("users").On(psql.Quote("review", "user_id").EQ(psql.Quote("users", "id"))),
+		sm.Where(
+			psql.Quote("users", "team_name").EQ(psql.Arg(teamName)).
+				And(psql.Quote("pull_request", "status").EQ(psql.Arg(model.PRStatusOpen))),
+		),
+		sm.GroupBy(psql.Quote("review", "user_id")),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	var (
+		userID string
+		count  int
+	)
+	_, err = pgx.ForEachRow(rows, []any{&userID, &count}, func() error {
+		counts[userID] = count
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
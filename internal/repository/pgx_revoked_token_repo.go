@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+	"github.com/stephenafamo/bob/dialect/psql"
+	"github.com/stephenafamo/bob/dialect/psql/im"
+	"github.com/stephenafamo/bob/dialect/psql/sm"
+	"github.com/yakoovad/avito-winter-2025/internal/db"
+	"time"
+)
+
+type RevokedToken struct {
+	ID        string     `db:"jti"`
+	Subject   string     `db:"subject"`
+	Reason    string     `db:"reason"`
+	RevokedBy string     `db:"revoked_by"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	RevokedAt time.Time  `db:"revoked_at"`
+}
+
+type RevokedTokenRepository interface {
+	Revoke(ctx context.Context, token *RevokedToken) error
+	ListActive(ctx context.Context, now time.Time) ([]*RevokedToken, error)
+}
+
+type pgxRevokedTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxRevokedTokenRepository(pool *pgxpool.Pool) RevokedTokenRepository {
+	return &pgxRevokedTokenRepository{pool: pool}
+}
+
+// Revoke Inserts the token and fills its RevokedAt, ErrAlreadyExists when the jti is already revoked
+func (p *pgxRevokedTokenRepository) Revoke(ctx context.Context, token *RevokedToken) error {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Insert(
+		im.Into("revoked_token", "jti", "subject", "reason", "revoked_by", "expires_at"),
+		im.Values(
+			psql.Arg(token.ID),
+			psql.Arg(token.Subject),
+			psql.Arg(token.Reason),
+			psql.Arg(token.RevokedBy),
+			psql.Arg(token.ExpiresAt),
+		),
+		im.Returning("revoked_at"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = e.QueryRow(ctx, sql, args...).Scan(&token.RevokedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrAlreadyExists
+	}
+
+	return err
+}
+
+// ListActive Returns the revoked tokens that are not expired at now, newest revocation first.
+// Tokens revoked without a known expiry are always returned.
+func (p *pgxRevokedTokenRepository) ListActive(ctx context.Context, now time.Time) ([]*RevokedToken, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("jti", "subject", "reason", "revoked_by", "expires_at", "revoked_at"),
+		sm.From("revoked_token"),
+		sm.Where(psql.Or(
+			psql.Quote("expires_at").IsNull(),
+			psql.Quote("expires_at").GT(psql.Arg(now)),
+		)),
+		sm.OrderBy(psql.Quote("revoked_at")).Desc(),
+		sm.OrderBy(psql.Quote("jti")),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*RevokedToken, error) {
+		t := &RevokedToken{}
+		if err := row.Scan(&t.ID, &t.Subject, &t.Reason, &t.RevokedBy, &t.ExpiresAt, &t.RevokedAt); err != nil {
+			return nil, err
+		}
+		return t, nil
+	})
+}
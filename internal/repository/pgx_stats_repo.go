@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stephenafamo/bob"
+	"github.com/stephenafamo/bob/dialect/psql"
+	"github.com/stephenafamo/bob/dialect/psql/dialect"
+	"github.com/stephenafamo/bob/dialect/psql/fm"
+	"github.com/stephenafamo/bob/dialect/psql/sm"
+	"github.com/yakoovad/avito-winter-2025/internal/db"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"time"
+)
+
+// StatsFilter Narrows statistics down to a team and a time range, zero values leave them open
+type StatsFilter struct {
+	TeamName string
+	From     time.Time
+	To       time.Time
+}
+
+type ReviewerStats struct {
+	UserID         string `db:"user_id"`
+	Username       string `db:"username"`
+	TeamName       string `db:"team_name"`
+	Assigned       int    `db:"assigned"`
+	Open           int    `db:"open"`
+	Merged         int    `db:"merged"`
+	ReassignedAway int    `db:"reassigned_away"`
+}
+
+// PullRequestStats PR flow of a team within one time bucket. Merge times are in seconds and nil without merges.
+type PullRequestStats struct {
+	TeamName          string    `db:"team_name"`
+	BucketStart       time.Time `db:"bucket_start"`
+	Created           int       `db:"created"`
+	Merged            int       `db:"merged"`
+	MedianTimeToMerge *float64  `db:"median_time_to_merge"`
+	P90TimeToMerge    *float64  `db:"p90_time_to_merge"`
+	AvgReassignments  float64   `db:"avg_reassignments"`
+}
+
+type StatsRepository interface {
+	GetReviewerStats(ctx context.Context, filter *StatsFilter) ([]*ReviewerStats, error)
+	GetPullRequestStats(ctx context.Context, filter *StatsFilter, bucket model.StatsBucket) ([]*PullRequestStats, error)
+}
+
+type pgxStatsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgxStatsRepository(pool *pgxpool.Pool) StatsRepository {
+	return &pgxStatsRepository{pool: pool}
+}
+
+// countWhere Builds COUNT(*) FILTER (WHERE cond)
+func countWhere(cond any) *dialect.Function {
+	return psql.F("COUNT", "*")(fm.Filter(cond))
+}
+
+// between Restricts column to [from, to), zero bounds are skipped
+func between(column psql.Expression, from, to time.Time) []bob.Expression {
+	conds := make([]bob.Expression, 0, 2)
+	if !from.IsZero() {
+		conds = append(conds, column.GTE(psql.Arg(from)))
+	}
+	if !to.IsZero() {
+		conds = append(conds, column.LT(psql.Arg(to)))
+	}
+	return conds
+}
+
+// GetReviewerStats Aggregates review work per user ordered by user ID.
+// Assignments are the current reviews plus the reviews reassigned away, counted by the creation time of their PR.
+// Reassignments away are counted by the time they happened. Open and merged are the reviews the user currently
+// holds on PRs in that status, whatever the period.
+func (p *pgxStatsRepository) GetReviewerStats(ctx context.Context, filter *StatsFilter) ([]*ReviewerStats, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	reviewed := psql.Select(
+		sm.Columns(psql.Quote("user_id"), psql.Quote("pull_request_id")),
+		sm.From("review"),
+		sm.UnionAll(psql.Select(
+			sm.Columns(psql.Quote("old_reviewer_id"), psql.Quote("pull_request_id")),
+			sm.From("review_reassignment"),
+		)),
+	)
+
+	assigned := psql.Select(
+		sm.Columns(psql.Quote("a", "user_id"), psql.F("COUNT", "*")().As("assigned")),
+		sm.From(reviewed).As("a"),
+		sm.InnerJoin("pull_request").As("pr").OnEQ(psql.Quote("pr", "id"), psql.Quote("a", "pull_request_id")),
+		sm.GroupBy(psql.Quote("a", "user_id")),
+	)
+	if conds := between(psql.Quote("pr", "created_at"), filter.From, filter.To); len(conds) > 0 {
+		assigned.Apply(sm.Where(psql.And(conds...)))
+	}
+
+	current := psql.Select(
+		sm.Columns(
+			psql.Quote("rv", "user_id"),
+			countWhere(psql.Quote("pr", "status").EQ(psql.S(string(model.PRStatusOpen)))).As("open"),
+			countWhere(psql.Quote("pr", "status").EQ(psql.S(string(model.PRStatusMerged)))).As("merged"),
+		),
+		sm.From("review").As("rv"),
+		sm.InnerJoin("pull_request").As("pr").OnEQ(psql.Quote("pr", "id"), psql.Quote("rv", "pull_request_id")),
+		sm.GroupBy(psql.Quote("rv", "user_id")),
+	)
+
+	reassigned := psql.Select(
+		sm.Columns(psql.Quote("ra", "old_reviewer_id"), psql.F("COUNT", "*")().As("reassigned_away")),
+		sm.From("review_reassignment").As("ra"),
+		sm.GroupBy(psql.Quote("ra", "old_reviewer_id")),
+	)
+	if conds := between(psql.Quote("ra", "reassigned_at"), filter.From, filter.To); len(conds) > 0 {
+		reassigned.Apply(sm.Where(psql.And(conds...)))
+	}
+
+	q := psql.Select(
+		sm.Columns(
+			psql.Quote("u", "id"),
+			psql.Quote("u", "username"),
+			psql.Quote("u", "team_name"),
+			psql.F("COALESCE", psql.Quote("a", "assigned"), psql.Raw("0")),
+			psql.F("COALESCE", psql.Quote("c", "open"), psql.Raw("0")),
+			psql.F("COALESCE", psql.Quote("c", "merged"), psql.Raw("0")),
+			psql.F("COALESCE", psql.Quote("ra", "reassigned_away"), psql.Raw("0")),
+		),
+		sm.From("users").As("u"),
+		sm.LeftJoin(assigned).As("a").OnEQ(psql.Quote("a", "user_id"), psql.Quote("u", "id")),
+		sm.LeftJoin(current).As("c").OnEQ(psql.Quote("c", "user_id"), psql.Quote("u", "id")),
+		sm.LeftJoin(reassigned).As("ra").OnEQ(psql.Quote("ra", "old_reviewer_id"), psql.Quote("u", "id")),
+		sm.OrderBy(psql.Quote("u", "id")),
+	)
+	if filter.TeamName != "" {
+		q.Apply(sm.Where(psql.Quote("u", "team_name").EQ(psql.Arg(filter.TeamName))))
+	}
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*ReviewerStats, error) {
+		s := &ReviewerStats{}
+		if err := row.Scan(&s.UserID, &s.Username, &s.TeamName, &s.Assigned, &s.Open, &s.Merged, &s.ReassignedAway); err != nil {
+			return nil, err
+		}
+		return s, nil
+	})
+}
+
+// GetPullRequestStats Aggregates PRs per author's team and bucket ordered by team and bucket start.
+// Created PRs and their reassignments fall into the bucket of created_at, merged PRs into the bucket of merged_at.
+// bucket is inlined into the query and must be one of the model.StatsBucket constants.
+func (p *pgxStatsRepository) GetPullRequestStats(ctx context.Context, filter *StatsFilter, bucket model.StatsBucket) ([]*PullRequestStats, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	truncate := func(column psql.Expression) *dialect.Function {
+		return psql.F("date_trunc", psql.S(string(bucket)), column)()
+	}
+	timeToMerge := psql.Raw(`EXTRACT(EPOCH FROM "pr"."merged_at" - "pr"."created_at")`)
+	percentile := func(fraction string) *dialect.Function {
+		return psql.F("percentile_cont", psql.Raw(fraction))(fm.WithinGroup(), fm.OrderBy(timeToMerge))
+	}
+
+	reassignments := psql.Select(
+		sm.Columns(psql.Quote("pull_request_id"), psql.F("COUNT", "*")().As("count")),
+		sm.From("review_reassignment"),
+		sm.GroupBy(psql.Quote("pull_request_id")),
+	)
+
+	created := psql.Select(
+		sm.Columns(
+			psql.Quote("u", "team_name"),
+			truncate(psql.Quote("pr", "created_at")).As("bucket_start"),
+			psql.F("COUNT", "*")().As("created"),
+			psql.F("AVG", psql.F("COALESCE", psql.Quote("ra", "count"), psql.Raw("0"))())().As("avg_reassignments"),
+		),
+		sm.From("pull_request").As("pr"),
+		sm.InnerJoin("users").As("u").OnEQ(psql.Quote("u", "id"), psql.Quote("pr", "author_id")),
+		sm.LeftJoin(reassignments).As("ra").OnEQ(psql.Quote("ra", "pull_request_id"), psql.Quote("pr", "id")),
+		sm.GroupBy(psql.Quote("u", "team_name")),
+		sm.GroupBy(truncate(psql.Quote("pr", "created_at"))),
+	)
+	if conds := between(psql.Quote("pr", "created_at"), filter.From, filter.To); len(conds) > 0 {
+		created.Apply(sm.Where(psql.And(conds...)))
+	}
+
+	merged := psql.Select(
+		sm.Columns(
+			psql.Quote("u", "team_name"),
+			truncate(psql.Quote("pr", "merged_at")).As("bucket_start"),
+			psql.F("COUNT", "*")().As("merged"),
+			percentile("0.5").As("median_time_to_merge"),
+			percentile("0.9").As("p90_time_to_merge"),
+		),
+		sm.From("pull_request").As("pr"),
+		sm.InnerJoin("users").As("u").OnEQ(psql.Quote("u", "id"), psql.Quote("pr", "author_id")),
+		// merged_at is backfilled by migration 00017, the check keeps a NULL bucket out if one slips through
+		sm.Where(psql.Quote("pr", "status").EQ(psql.S(string(model.PRStatusMerged)))),
+		sm.Where(psql.Quote("pr", "merged_at").IsNotNull()),
+		sm.GroupBy(psql.Quote("u", "team_name")),
+		sm.GroupBy(truncate(psql.Quote("pr", "merged_at"))),
+	)
+	if conds := between(psql.Quote("pr", "merged_at"), filter.From, filter.To); len(conds) > 0 {
+		merged.Apply(sm.Where(psql.And(conds...)))
+	}
+
+	if filter.TeamName != "" {
+		created.Apply(sm.Where(psql.Quote("u", "team_name").EQ(psql.Arg(filter.TeamName))))
+		merged.Apply(sm.Where(psql.Quote("u", "team_name").EQ(psql.Arg(filter.TeamName))))
+	}
+
+	q := psql.Select(
+		sm.Columns(
+			psql.F("COALESCE", psql.Quote("c", "team_name"), psql.Quote("m", "team_name"))(),
+			psql.F("COALESCE", psql.Quote("c", "bucket_start"), psql.Quote("m", "bucket_start"))(),
+			psql.F("COALESCE", psql.Quote("c", "created"), psql.Raw("0"))(),
+			psql.F("COALESCE", psql.Quote("m", "merged"), psql.Raw("0"))(),
+			psql.Quote("m", "median_time_to_merge"),
+			psql.Quote("m", "p90_time_to_merge"),
+			psql.F("COALESCE", psql.Quote("c", "avg_reassignments"), psql.Raw("0"))(),
+		),
+		sm.From(created).As("c"),
+		sm.FullJoin(merged).As("m").On(
+			psql.Quote("m", "team_name").EQ(psql.Quote("c", "team_name")).
+				And(psql.Quote("m", "bucket_start").EQ(psql.Quote("c", "bucket_start"))),
+		),
+		sm.OrderBy(psql.Raw("1")),
+		sm.OrderBy(psql.Raw("2")),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*PullRequestStats, error) {
+		s := &PullRequestStats{}
+		if err := row.Scan(&s.TeamName, &s.BucketStart, &s.Created, &s.Merged, &s.MedianTimeToMerge, &s.P90TimeToMerge, &s.AvgReassignments); err != nil {
+			return nil, err
+		}
+		return s, nil
+	})
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+)
+
+func TestPgxStatsRepository_GetReviewerStats(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+
+	pr := createTestPR(t, pool)
+	author, err := NewPgxUserRepository(pool).Get(ctx, pr.AuthorID)
+	require.NoError(t, err)
+
+	reviewers := []string{author.ID + "-r1", author.ID + "-r2", author.ID + "-r3"}
+	for _, id := range reviewers {
+		require.NoError(t, NewPgxUserRepository(pool).Upsert(ctx, &User{
+			ID: id, Username: id, IsActive: true, TeamName: author.TeamName, Role: model.UserRoleMiddle, Skills: []string{},
+		}))
+	}
+	t.Cleanup(func() {
+		_, _ = pool.Exec(ctx, "DELETE FROM review WHERE pull_request_id = $1", pr.ID)
+		_, _ = pool.Exec(ctx, "DELETE FROM users WHERE id = ANY($1)", reviewers)
+	})
+	r1, r2, r3 := reviewers[0], reviewers[1], reviewers[2]
+
+	// r1 is replaced by r2, r2 and r3 still review the PR
+	reviewRepo := NewPgxReviewRepository(pool)
+	require.NoError(t, reviewRepo.Assign(ctx, pr.ID, []string{r2, r3}))
+	require.NoError(t, reviewRepo.RecordReassignments(ctx, []*Reassignment{
+		{PullRequestID: pr.ID, OldReviewerID: r1, NewReviewerID: &r2},
+	}))
+
+	repo := NewPgxStatsRepository(pool)
+
+	got, err := repo.GetReviewerStats(ctx, &StatsFilter{TeamName: author.TeamName})
+	require.NoError(t, err)
+
+	byUser := make(map[string]*ReviewerStats, len(got))
+	for _, s := range got {
+		byUser[s.UserID] = s
+	}
+	assert.Equal(t, &ReviewerStats{UserID: r1, Username: r1, TeamName: author.TeamName, Assigned: 1, ReassignedAway: 1}, byUser[r1])
+	assert.Equal(t, &ReviewerStats{UserID: r2, Username: r2, TeamName: author.TeamName, Assigned: 1, Open: 1}, byUser[r2])
+	assert.Equal(t, &ReviewerStats{UserID: r3, Username: r3, TeamName: author.TeamName, Assigned: 1, Open: 1}, byUser[r3])
+
+	got, err = repo.GetReviewerStats(ctx, &StatsFilter{TeamName: author.TeamName, From: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	for _, s := range got {
+		assert.Zero(t, s.Assigned, "PRs created before the period are not counted")
+		assert.Zero(t, s.ReassignedAway)
+		if s.UserID == r2 {
+			assert.Equal(t, 1, s.Open, "current reviews are counted whatever the period")
+		}
+	}
+}
@@ -7,19 +7,47 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pkg/errors"
 	"github.com/stephenafamo/bob/dialect/psql"
+	"github.com/stephenafamo/bob/dialect/psql/dm"
 	"github.com/stephenafamo/bob/dialect/psql/im"
 	"github.com/stephenafamo/bob/dialect/psql/sm"
 	"github.com/yakoovad/avito-winter-2025/internal/db"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
 )
 
 type Team struct {
 	Name string `db:"name"`
 }
 
+type TeamSettings struct {
+	TeamName               string            `db:"team_name"`
+	MinReviewers           int               `db:"min_reviewers"`
+	MaxReviewers           int               `db:"max_reviewers"`
+	LeadID                 *string           `db:"lead_id"`
+	LeadMandatory          bool              `db:"lead_mandatory"`
+	AllowInactiveReviewers bool              `db:"allow_inactive_reviewers"`
+	RequiredApprovals      int               `db:"required_approvals"`
+	AllowExternalReviewers bool              `db:"allow_external_reviewers"`
+	FallbackTeams          []string          `db:"fallback_teams"`
+	RequireSenior          bool              `db:"require_senior"`
+	StaleAfterMinutes      int               `db:"stale_after_minutes"`
+	StalePolicy            model.StalePolicy `db:"stale_policy"`
+}
+
+type ReviewerRule struct {
+	Kind       model.ReviewerRuleKind `db:"kind"`
+	ReviewerID string                 `db:"reviewer_id"`
+	TargetID   string                 `db:"target_id"`
+}
+
 type TeamRepository interface {
 	Create(ctx context.Context, team *Team) error
 	Get(ctx context.Context, name string) (*Team, error)
 	GetTeamMembers(ctx context.Context, name string) ([]*User, error)
+	GetSettings(ctx context.Context, name string) (*TeamSettings, error)
+	UpsertSettings(ctx context.Context, settings *TeamSettings) error
+	GetTeamsFallingBackTo(ctx context.Context, name string) ([]string, error)
+	GetReviewerRules(ctx context.Context, name string) ([]*ReviewerRule, error)
+	ReplaceReviewerRules(ctx context.Context, name string, rules []*ReviewerRule) error
 }
 
 type pgxTeamRepository struct {
@@ -81,7 +109,7 @@ func (p *pgxTeamRepository) GetTeamMembers(ctx context.Context, name string) ([]
 	e := db.GetPgxExecutorFromContext(ctx, p.pool)
 
 	q := psql.Select(
-		sm.Columns("*"),
+		sm.Columns("id", "username", "is_active", "team_name", "role", "skills", "max_open_reviews", absentNow("u")),
 		sm.From("users").As("u"),
 		sm.Where(psql.Quote("u", "team_name").EQ(psql.Arg(name))),
 	)
@@ -99,7 +127,7 @@ func (p *pgxTeamRepository) GetTeamMembers(ctx context.Context, name string) ([]
 
 	users, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*User, error) {
 		user := &User{}
-		if err = row.Scan(&user.ID, &user.Username, &user.IsActive, &user.TeamName); err != nil {
+		if err = row.Scan(&user.ID, &user.Username, &user.IsActive, &user.TeamName, &user.Role, &user.Skills, &user.MaxOpenReviews, &user.Absent); err != nil {
 			return nil, err
 		}
 		return user, nil
@@ -110,3 +138,183 @@ func (p *pgxTeamRepository) GetTeamMembers(ctx context.Context, name string) ([]
 
 	return users, err
 }
+
+// GetSettings Returns ErrNotFound when the team has no stored settings
+func (p *pgxTeamRepository) GetSettings(ctx context.Context, name string) (*TeamSettings, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("team_name", "min_reviewers", "max_reviewers", "lead_id", "lead_mandatory", "allow_inactive_reviewers", "required_approvals", "allow_external_reviewers", "fallback_teams", "require_senior", "stale_after_minutes", "stale_policy"),
+		sm.From("team_settings"),
+		sm.Where(psql.Quote("team_name").EQ(psql.Arg(name))),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &TeamSettings{}
+	if err = e.QueryRow(ctx, sql, args...).Scan(
+		&settings.TeamName,
+		&settings.MinReviewers,
+		&settings.MaxReviewers,
+		&settings.LeadID,
+		&settings.LeadMandatory,
+		&settings.AllowInactiveReviewers,
+		&settings.RequiredApprovals,
+		&settings.AllowExternalReviewers,
+		&settings.FallbackTeams,
+		&settings.RequireSenior,
+		&settings.StaleAfterMinutes,
+		&settings.StalePolicy,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+// UpsertSettings Returns ErrNotFound when the team or the lead does not exist
+func (p *pgxTeamRepository) UpsertSettings(ctx context.Context, settings *TeamSettings) error {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Insert(
+		im.Into("team_settings", "team_name", "min_reviewers", "max_reviewers", "lead_id", "lead_mandatory", "allow_inactive_reviewers", "required_approvals", "allow_external_reviewers", "fallback_teams", "require_senior", "stale_after_minutes", "stale_policy"),
+		im.Values(
+			psql.Arg(settings.TeamName),
+			psql.Arg(settings.MinReviewers),
+			psql.Arg(settings.MaxReviewers),
+			psql.Arg(settings.LeadID),
+			psql.Arg(settings.LeadMandatory),
+			psql.Arg(settings.AllowInactiveReviewers),
+			psql.Arg(settings.RequiredApprovals),
+			psql.Arg(settings.AllowExternalReviewers),
+			psql.Arg(settings.FallbackTeams),
+			psql.Arg(settings.RequireSenior),
+			psql.Arg(settings.StaleAfterMinutes),
+			psql.Arg(settings.StalePolicy),
+		),
+		im.OnConflict(psql.Quote("team_name")).DoUpdate(
+			im.SetExcluded("min_reviewers", "max_reviewers", "lead_id", "lead_mandatory", "allow_inactive_reviewers", "required_approvals", "allow_external_reviewers", "fallback_teams", "require_senior", "stale_after_minutes", "stale_policy"),
+		),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.Exec(ctx, sql, args...)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// GetTeamsFallingBackTo Returns the names of teams listing the team among their fallback_teams, ordered by name
+func (p *pgxTeamRepository) GetTeamsFallingBackTo(ctx context.Context, name string) ([]string, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("team_name"),
+		sm.From("team_settings"),
+		sm.Where(psql.Arg(name).EQ(psql.F("ANY", psql.Quote("fallback_teams"))())),
+		sm.OrderBy("team_name"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// GetReviewerRules Returns the team's rules ordered by kind, reviewer and target
+func (p *pgxTeamRepository) GetReviewerRules(ctx context.Context, name string) ([]*ReviewerRule, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("kind", "reviewer_id", "target_id"),
+		sm.From("reviewer_rule"),
+		sm.Where(psql.Quote("team_name").EQ(psql.Arg(name))),
+		sm.OrderBy("kind"),
+		sm.OrderBy("reviewer_id"),
+		sm.OrderBy("target_id"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*ReviewerRule, error) {
+		rule := &ReviewerRule{}
+		if err := row.Scan(&rule.Kind, &rule.ReviewerID, &rule.TargetID); err != nil {
+			return nil, err
+		}
+		return rule, nil
+	})
+}
+
+// ReplaceReviewerRules Swaps the team's rules for the given ones, returns ErrNotFound when the team or a user does not exist
+func (p *pgxTeamRepository) ReplaceReviewerRules(ctx context.Context, name string, rules []*ReviewerRule) error {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	del := psql.Delete(
+		dm.From("reviewer_rule"),
+		dm.Where(psql.Quote("team_name").EQ(psql.Arg(name))),
+	)
+
+	sql, args, err := del.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err = e.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	ins := psql.Insert(
+		im.Into("reviewer_rule", "team_name", "kind", "reviewer_id", "target_id"),
+	)
+	for _, rule := range rules {
+		ins.Apply(im.Values(psql.Arg(name), psql.Arg(rule.Kind), psql.Arg(rule.ReviewerID), psql.Arg(rule.TargetID)))
+	}
+
+	sql, args, err = ins.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.Exec(ctx, sql, args...)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrNotFound
+	}
+
+	return err
+}
@@ -3,22 +3,39 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stephenafamo/bob"
 	"github.com/stephenafamo/bob/dialect/psql"
 	"github.com/stephenafamo/bob/dialect/psql/dialect"
+	"github.com/stephenafamo/bob/dialect/psql/dm"
 	"github.com/stephenafamo/bob/dialect/psql/im"
 	"github.com/stephenafamo/bob/dialect/psql/sm"
 	"github.com/stephenafamo/bob/dialect/psql/um"
 	"github.com/yakoovad/avito-winter-2025/internal/db"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"time"
 )
 
 type User struct {
-	ID       string `db:"id"`
-	Username string `db:"username"`
-	IsActive bool   `db:"is_active"`
-	TeamName string `db:"team_name"`
+	ID       string         `db:"id"`
+	Username string         `db:"username"`
+	IsActive bool           `db:"is_active"`
+	TeamName string         `db:"team_name"`
+	Role     model.UserRole `db:"role"`
+	Skills   []string       `db:"skills"`
+	// MaxOpenReviews overrides the global cap of OPEN reviews, nil means the default applies
+	MaxOpenReviews *int `db:"max_open_reviews"`
+	// Absent is only filled by Get, GetUserTeam and TeamRepository.GetTeamMembers
+	Absent bool `db:"absent"`
+}
+
+type Absence struct {
+	UserID   string    `db:"user_id"`
+	StartsAt time.Time `db:"starts_at"`
+	EndsAt   time.Time `db:"ends_at"`
 }
 
 type UserPatch struct {
@@ -30,9 +47,23 @@ type UserPatch struct {
 
 type UserRepository interface {
 	Get(ctx context.Context, userID string) (*User, error)
+	GetTeamNames(ctx context.Context, userIDs []string) (map[string]string, error)
 	GetUserTeam(ctx context.Context, userID string) ([]*User, error)
 	Upsert(ctx context.Context, user *User) error
 	Patch(ctx context.Context, patch *UserPatch) (*User, error)
+	SetTeamMembersActive(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]*User, error)
+	SetMaxOpenReviews(ctx context.Context, userID string, limit *int) (*User, error)
+	ReplaceAbsences(ctx context.Context, userID string, absences []*Absence) error
+	GetAbsences(ctx context.Context, userID string) ([]*Absence, error)
+	GetTeamAbsences(ctx context.Context, teamName string, after time.Time) ([]*Absence, error)
+}
+
+// absentNow Evaluates to true while the user row of `table` is inside one of its absence windows
+func absentNow(table string) psql.Expression {
+	return psql.Raw(fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM user_absence WHERE user_absence.user_id = %s.id AND user_absence.starts_at <= NOW() AND user_absence.ends_at > NOW())",
+		table,
+	))
 }
 
 type pgxUserRepository struct {
@@ -48,7 +79,7 @@ func (p *pgxUserRepository) GetUserTeam(ctx context.Context, userID string) ([]*
 
 	q := psql.Select(
 		sm.From("users"),
-		sm.Columns("id", "username", "is_active", "team_name"),
+		sm.Columns("id", "username", "is_active", "team_name", "role", "skills", "max_open_reviews", absentNow("users")),
 		sm.Where(psql.Quote("team_name").EQ(
 			psql.Select(
 				sm.Columns("team_name"),
@@ -72,7 +103,7 @@ func (p *pgxUserRepository) GetUserTeam(ctx context.Context, userID string) ([]*
 
 	members, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*User, error) {
 		user := &User{}
-		if err = row.Scan(&user.ID, &user.Username, &user.IsActive, &user.TeamName); err != nil {
+		if err = row.Scan(&user.ID, &user.Username, &user.IsActive, &user.TeamName, &user.Role, &user.Skills, &user.MaxOpenReviews, &user.Absent); err != nil {
 			return nil, err
 		}
 		return user, nil
@@ -105,7 +136,7 @@ func (p *pgxUserRepository) Patch(ctx context.Context, patch *UserPatch) (*User,
 	q := psql.Update(
 		um.Table("users"),
 		um.Where(psql.Quote("id").EQ(psql.Arg(patch.ID))),
-		um.Returning("id", "username", "is_active", "team_name"),
+		um.Returning("id", "username", "is_active", "team_name", "role", "skills", "max_open_reviews"),
 	)
 
 	q.Apply(sets...)
@@ -121,6 +152,83 @@ func (p *pgxUserRepository) Patch(ctx context.Context, patch *UserPatch) (*User,
 		&u.Username,
 		&u.IsActive,
 		&u.TeamName,
+		&u.Role,
+		&u.Skills,
+		&u.MaxOpenReviews,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// SetTeamMembersActive Updates is_active of the listed team members with a single statement.
+// Users that do not exist or belong to another team are left untouched and are missing from the result.
+func (p *pgxUserRepository) SetTeamMembersActive(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]*User, error) {
+	if len(userIDs) == 0 {
+		return []*User{}, nil
+	}
+
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Update(
+		um.Table("users"),
+		um.SetCol("is_active").ToArg(isActive),
+		um.Where(
+			psql.Quote("team_name").EQ(psql.Arg(teamName)).
+				And(psql.Quote("id").In(argList(userIDs))),
+		),
+		um.Returning("id", "username", "is_active", "team_name", "role", "skills", "max_open_reviews"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*User, error) {
+		user := &User{}
+		if err = row.Scan(&user.ID, &user.Username, &user.IsActive, &user.TeamName, &user.Role, &user.Skills, &user.MaxOpenReviews); err != nil {
+			return nil, err
+		}
+		return user, nil
+	})
+}
+
+// SetMaxOpenReviews Sets the user's cap of OPEN reviews, nil restores the global default
+func (p *pgxUserRepository) SetMaxOpenReviews(ctx context.Context, userID string, limit *int) (*User, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Update(
+		um.Table("users"),
+		um.SetCol("max_open_reviews").ToArg(limit),
+		um.Where(psql.Quote("id").EQ(psql.Arg(userID))),
+		um.Returning("id", "username", "is_active", "team_name", "role", "skills", "max_open_reviews"),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &User{}
+	if err = e.QueryRow(ctx, sql, args...).Scan(
+		&u.ID,
+		&u.Username,
+		&u.IsActive,
+		&u.TeamName,
+		&u.Role,
+		&u.Skills,
+		&u.MaxOpenReviews,
 	); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -135,12 +243,14 @@ func (p *pgxUserRepository) Upsert(ctx context.Context, user *User) error {
 	e := db.GetPgxExecutorFromContext(ctx, p.pool)
 
 	q := psql.Insert(
-		im.Into("users", "id", "username", "is_active", "team_name"),
-		im.Values(psql.Arg(user.ID), psql.Arg(user.Username), psql.Arg(user.IsActive), psql.Arg(user.TeamName)),
+		im.Into("users", "id", "username", "is_active", "team_name", "role", "skills"),
+		im.Values(psql.Arg(user.ID), psql.Arg(user.Username), psql.Arg(user.IsActive), psql.Arg(user.TeamName), psql.Arg(user.Role), psql.Arg(user.Skills)),
 		im.OnConflict(psql.Quote("id")).DoUpdate(
 			im.SetCol("username").ToArg(user.Username),
 			im.SetCol("is_active").ToArg(user.IsActive),
 			im.SetCol("team_name").ToArg(user.TeamName),
+			im.SetCol("role").ToArg(user.Role),
+			im.SetCol("skills").ToArg(user.Skills),
 		),
 	)
 	sql, args, err := q.Build(ctx)
@@ -159,7 +269,7 @@ func (p *pgxUserRepository) Get(ctx context.Context, userID string) (*User, erro
 	e := db.GetPgxExecutorFromContext(ctx, p.pool)
 
 	q := psql.Select(
-		sm.Columns("id", "username", "is_active", "team_name"),
+		sm.Columns("id", "username", "is_active", "team_name", "role", "skills", "max_open_reviews", absentNow("users")),
 		sm.From("users"),
 		sm.Where(psql.Quote("id").EQ(psql.Arg(userID))),
 	)
@@ -174,8 +284,162 @@ func (p *pgxUserRepository) Get(ctx context.Context, userID string) (*User, erro
 		&u.Username,
 		&u.IsActive,
 		&u.TeamName,
+		&u.Role,
+		&u.Skills,
+		&u.MaxOpenReviews,
+		&u.Absent,
 	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
 	return u, nil
 }
+
+// GetTeamNames Returns the team of every listed user keyed by user ID, unknown users are omitted
+func (p *pgxUserRepository) GetTeamNames(ctx context.Context, userIDs []string) (map[string]string, error) {
+	teams := make(map[string]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return teams, nil
+	}
+
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("id", "team_name"),
+		sm.From("users"),
+		sm.Where(psql.Quote("id").In(argList(userIDs))),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userID, teamName string
+	_, err = pgx.ForEachRow(rows, []any{&userID, &teamName}, func() error {
+		teams[userID] = teamName
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+// ReplaceAbsences Swaps all absence windows of the user for the given ones, returns ErrNotFound for an unknown user
+func (p *pgxUserRepository) ReplaceAbsences(ctx context.Context, userID string, absences []*Absence) error {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	del := psql.Delete(
+		dm.From("user_absence"),
+		dm.Where(psql.Quote("user_id").EQ(psql.Arg(userID))),
+	)
+
+	sql, args, err := del.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err = e.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	if len(absences) == 0 {
+		return nil
+	}
+
+	ins := psql.Insert(
+		im.Into("user_absence", "user_id", "starts_at", "ends_at"),
+	)
+	for _, a := range absences {
+		ins.Apply(im.Values(psql.Arg(userID), psql.Arg(a.StartsAt), psql.Arg(a.EndsAt)))
+	}
+
+	sql, args, err = ins.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.Exec(ctx, sql, args...)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// GetAbsences Returns all absence windows of the user ordered by start
+func (p *pgxUserRepository) GetAbsences(ctx context.Context, userID string) ([]*Absence, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns("user_id", "starts_at", "ends_at"),
+		sm.From("user_absence"),
+		sm.Where(psql.Quote("user_id").EQ(psql.Arg(userID))),
+		sm.OrderBy(psql.Quote("starts_at")),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Absence, error) {
+		a := &Absence{}
+		if err := row.Scan(&a.UserID, &a.StartsAt, &a.EndsAt); err != nil {
+			return nil, err
+		}
+		return a, nil
+	})
+}
+
+// GetTeamAbsences Returns absences of the team members that end after `after`, ordered by start
+func (p *pgxUserRepository) GetTeamAbsences(ctx context.Context, teamName string, after time.Time) ([]*Absence, error) {
+	e := db.GetPgxExecutorFromContext(ctx, p.pool)
+
+	q := psql.Select(
+		sm.Columns(psql.Quote("a", "user_id"), psql.Quote("a", "starts_at"), psql.Quote("a", "ends_at")),
+		sm.From("user_absence").As("a"),
+		sm.InnerJoin("users").As("u").OnEQ(psql.Quote("u", "id"), psql.Quote("a", "user_id")),
+		sm.Where(psql.Quote("u", "team_name").EQ(psql.Arg(teamName)).
+			And(psql.Quote("a", "ends_at").GT(psql.Arg(after)))),
+		sm.OrderBy(psql.Quote("a", "starts_at")),
+		sm.OrderBy(psql.Quote("a", "user_id")),
+	)
+
+	sql, args, err := q.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := e.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Absence, error) {
+		a := &Absence{}
+		if err := row.Scan(&a.UserID, &a.StartsAt, &a.EndsAt); err != nil {
+			return nil, err
+		}
+		return a, nil
+	})
+}
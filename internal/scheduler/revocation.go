@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/yakoovad/avito-winter-2025/internal/service"
+	"github.com/yakoovad/avito-winter-2025/pkg/logger"
+	"go.uber.org/zap"
+	"time"
+)
+
+type RevocationJob interface {
+	RefreshRevokedTokens(ctx context.Context) *service.Error
+}
+
+// RevocationRefresher Keeps the revoked token cache of this replica in sync with the database,
+// unlike the stale PR job it runs on every replica.
+type RevocationRefresher struct {
+	job      RevocationJob
+	interval time.Duration
+}
+
+func NewRevocationRefresher(job RevocationJob, interval time.Duration) *RevocationRefresher {
+	return &RevocationRefresher{
+		job:      job,
+		interval: interval,
+	}
+}
+
+// Run Refreshes the cache every interval until ctx is cancelled, a failed refresh keeps the previous cache
+func (r *RevocationRefresher) Run(ctx context.Context) {
+	l := logger.FromContext(ctx).With(zap.String("job", "revoked_tokens"))
+	ctx = logger.WithLogger(ctx, l)
+
+	l.Info("revoked token refresher started", zap.Duration("interval", r.interval))
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.Info("revoked token refresher stopped")
+			return
+		case <-ticker.C:
+			if err := r.job.RefreshRevokedTokens(ctx); err != nil {
+				l.Error("failed to refresh revoked tokens", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/yakoovad/avito-winter-2025/internal/service"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeRevocationJob struct {
+	calls atomic.Int32
+}
+
+func (f *fakeRevocationJob) RefreshRevokedTokens(context.Context) *service.Error {
+	f.calls.Add(1)
+	return service.NewError(service.ErrorCodeUnspecified, "db error")
+}
+
+func TestRevocationRefresher_Run(t *testing.T) {
+	job := &fakeRevocationJob{}
+	r := NewRevocationRefresher(job, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+
+	// A failed refresh does not stop the refresher
+	assert.Eventually(t, func() bool { return job.calls.Load() >= 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("refresher did not stop")
+	}
+}
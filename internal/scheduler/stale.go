@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"github.com/yakoovad/avito-winter-2025/internal/audit"
+	"github.com/yakoovad/avito-winter-2025/internal/service"
+	"github.com/yakoovad/avito-winter-2025/pkg/logger"
+	"go.uber.org/zap"
+	"time"
+)
+
+// StaleLockKey Advisory lock key electing the replica that runs the stale PR job
+const StaleLockKey int64 = 0x5354414c45
+
+// Actor Actor of the changes made by scheduled jobs in the audit log and PR history
+const Actor = "scheduler"
+
+type StaleJob interface {
+	ProcessStalePullRequests(ctx context.Context, now time.Time) (int, *service.Error)
+}
+
+// Locker Leader election, TryLock reports whether the caller is the leader
+type Locker interface {
+	TryLock(ctx context.Context) (bool, error)
+	Unlock(ctx context.Context) error
+}
+
+type StaleScheduler struct {
+	job      StaleJob
+	lock     Locker
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewStaleScheduler(job StaleJob, lock Locker, interval time.Duration) *StaleScheduler {
+	return &StaleScheduler{
+		job:      job,
+		lock:     lock,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run Looks for stale PRs every interval until ctx is cancelled. Only the replica holding the lock runs the job,
+// the others keep trying to take the lock over on every tick.
+func (s *StaleScheduler) Run(ctx context.Context) {
+	l := logger.FromContext(ctx).With(zap.String("job", "stale_pull_requests"))
+	ctx = audit.WithActor(logger.WithLogger(ctx, l), Actor)
+
+	l.Info("stale PR scheduler started", zap.Duration("interval", s.interval))
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.lock.Unlock(context.Background()); err != nil {
+				l.Error("failed to release scheduler lock", zap.Error(err))
+			}
+			l.Info("stale PR scheduler stopped")
+			return
+		case <-ticker.C:
+			s.tick(ctx)
+		}
+	}
+}
+
+func (s *StaleScheduler) tick(ctx context.Context) {
+	l := logger.FromContext(ctx)
+
+	leader, err := s.lock.TryLock(ctx)
+	if err != nil {
+		l.Error("failed to take scheduler lock", zap.Error(err))
+		return
+	}
+	if !leader {
+		l.Debug("stale PR job runs on another replica")
+		return
+	}
+
+	now := s.now()
+	ctx = audit.WithRequestID(ctx, fmt.Sprintf("%s-%d", Actor, now.Unix()))
+
+	handled, srvErr := s.job.ProcessStalePullRequests(ctx, now)
+	if srvErr != nil {
+		l.Error("stale PR job failed", zap.Error(srvErr))
+		return
+	}
+	if handled > 0 {
+		l.Info("stale PRs handled", zap.Int("count", handled))
+	}
+}
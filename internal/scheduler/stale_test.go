@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/yakoovad/avito-winter-2025/internal/audit"
+	"github.com/yakoovad/avito-winter-2025/internal/service"
+	"testing"
+	"time"
+)
+
+type fakeLock struct {
+	leader   bool
+	err      error
+	unlocked bool
+}
+
+func (f *fakeLock) TryLock(context.Context) (bool, error) {
+	return f.leader, f.err
+}
+
+func (f *fakeLock) Unlock(context.Context) error {
+	f.unlocked = true
+	return nil
+}
+
+type fakeJob struct {
+	calls []time.Time
+	actor string
+}
+
+func (f *fakeJob) ProcessStalePullRequests(ctx context.Context, now time.Time) (int, *service.Error) {
+	f.calls = append(f.calls, now)
+	f.actor = audit.ActorFromContext(ctx)
+	return 1, nil
+}
+
+func TestStaleScheduler_Tick(t *testing.T) {
+	now := time.Date(2025, time.January, 6, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		lock      *fakeLock
+		expectRun bool
+	}{
+		{name: "leader runs the job", lock: &fakeLock{leader: true}, expectRun: true},
+		{name: "follower skips the job", lock: &fakeLock{}},
+		{name: "lock error skips the job", lock: &fakeLock{leader: true, err: errors.New("db error")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &fakeJob{}
+			s := NewStaleScheduler(job, tt.lock, time.Minute)
+			s.now = func() time.Time { return now }
+
+			s.tick(audit.WithActor(context.Background(), Actor))
+
+			if tt.expectRun {
+				assert.Equal(t, []time.Time{now}, job.calls)
+				assert.Equal(t, Actor, job.actor)
+			} else {
+				assert.Empty(t, job.calls)
+			}
+		})
+	}
+}
+
+func TestStaleScheduler_RunReleasesLock(t *testing.T) {
+	lock := &fakeLock{leader: true}
+	s := NewStaleScheduler(&fakeJob{}, lock, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+	assert.True(t, lock.unlocked)
+}
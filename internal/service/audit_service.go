@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/yakoovad/avito-winter-2025/internal/audit"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"github.com/yakoovad/avito-winter-2025/pkg/logger"
+	"go.uber.org/zap"
+	"reflect"
+)
+
+const defaultAuditPageSize = 50
+
+type AuditService struct {
+	audits repository.AuditRepository
+}
+
+func NewAuditService() *AuditService {
+	return &AuditService{}
+}
+
+// ListEvents Returns one page of audit events newest first, next_cursor is set while older events remain
+func (s *AuditService) ListEvents(ctx context.Context, filter *model.AuditFilter) (*model.AuditEventPage, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("listing audit events",
+		zap.String("actor", filter.Actor),
+		zap.String("action", string(filter.Action)),
+		zap.String("entity_type", string(filter.EntityType)),
+		zap.String("entity_id", filter.EntityID),
+		zap.Int64("cursor", filter.Cursor))
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		return nil, NewError(ErrorCodeInvalidBody, "to must be after from")
+	}
+	if filter.Cursor < 0 {
+		return nil, NewError(ErrorCodeInvalidBody, "cursor must not be negative")
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditPageSize
+	}
+
+	// One extra row tells whether another page exists
+	repoEvents, err := s.audits.List(ctx, &repository.AuditFilter{
+		Actor:      filter.Actor,
+		Action:     filter.Action,
+		EntityType: filter.EntityType,
+		EntityID:   filter.EntityID,
+		From:       filter.From,
+		To:         filter.To,
+		BeforeID:   filter.Cursor,
+		Limit:      limit + 1,
+	})
+	if err != nil {
+		l.Error("failed to list audit events", zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to list audit events")
+	}
+
+	res := &model.AuditEventPage{Events: make([]*model.AuditEvent, 0, min(len(repoEvents), limit))}
+	for i, ev := range repoEvents {
+		if i == limit {
+			next := res.Events[limit-1].ID
+			res.NextCursor = &next
+			break
+		}
+		res.Events = append(res.Events, &model.AuditEvent{
+			ID:         ev.ID,
+			Actor:      ev.Actor,
+			Action:     ev.Action,
+			EntityType: ev.EntityType,
+			EntityID:   ev.EntityID,
+			Before:     ev.Before,
+			After:      ev.After,
+			RequestID:  ev.RequestID,
+			CreatedAt:  ev.CreatedAt,
+		})
+	}
+
+	return res, nil
+}
+
+func (s *AuditService) WithAuditRepo(r repository.AuditRepository) *AuditService {
+	s.audits = r
+	return s
+}
+
+// recordAudit Appends an audit event with the actor and request ID of ctx, it must run in the transaction
+// of the change so that both are committed or rolled back together. before and after are stored as JSON,
+// nil values as NULL. Nothing is recorded when the service has no audit repository configured.
+func recordAudit(ctx context.Context, audits repository.AuditRepository, action model.AuditAction, entityType model.AuditEntity, entityID string, before, after any) error {
+	if audits == nil {
+		return nil
+	}
+
+	l := logger.FromContext(ctx)
+
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		l.Error("failed to encode audit state", zap.String("action", string(action)), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to record audit event")
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		l.Error("failed to encode audit state", zap.String("action", string(action)), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to record audit event")
+	}
+
+	if err = audits.Append(ctx, &repository.AuditEvent{
+		Actor:      audit.ActorFromContext(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		RequestID:  audit.RequestIDFromContext(ctx),
+	}); err != nil {
+		l.Error("failed to record audit event",
+			zap.String("action", string(action)),
+			zap.String("entity_id", entityID),
+			zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to record audit event")
+	}
+
+	return nil
+}
+
+// auditJSON Encodes v, nil interfaces and nil pointers give nil so that they are stored as NULL
+func auditJSON(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yakoovad/avito-winter-2025/internal/audit"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestAuditService_ListEvents(t *testing.T) {
+	from := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name          string
+		filter        *model.AuditFilter
+		setupMocks    func(*MockAuditRepository)
+		expectedError bool
+		errorCode     ErrorCode
+		expectedIDs   []int64
+		expectedNext  *int64
+	}{
+		{
+			name:   "success: more pages remain",
+			filter: &model.AuditFilter{EntityType: model.AuditEntityPullRequest, EntityID: "pr1", Limit: 2},
+			setupMocks: func(ar *MockAuditRepository) {
+				ar.On("List", mock.Anything, &repository.AuditFilter{
+					EntityType: model.AuditEntityPullRequest, EntityID: "pr1", Limit: 3,
+				}).Return([]*repository.AuditEvent{{ID: 9}, {ID: 7}, {ID: 4}}, nil)
+			},
+			expectedIDs:  []int64{9, 7},
+			expectedNext: func() *int64 { v := int64(7); return &v }(),
+		},
+		{
+			name:   "success: last page with cursor and default limit",
+			filter: &model.AuditFilter{Actor: "admin", From: from, To: to, Cursor: 7},
+			setupMocks: func(ar *MockAuditRepository) {
+				ar.On("List", mock.Anything, &repository.AuditFilter{
+					Actor: "admin", From: from, To: to, BeforeID: 7, Limit: defaultAuditPageSize + 1,
+				}).Return([]*repository.AuditEvent{{ID: 4}}, nil)
+			},
+			expectedIDs: []int64{4},
+		},
+		{
+			name:          "failure: inverted time range",
+			filter:        &model.AuditFilter{From: to, To: from},
+			setupMocks:    func(ar *MockAuditRepository) {},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name:   "failure: query failed",
+			filter: &model.AuditFilter{},
+			setupMocks: func(ar *MockAuditRepository) {
+				ar.On("List", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUnspecified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuditRepo := new(MockAuditRepository)
+
+			tt.setupMocks(mockAuditRepo)
+
+			service := NewAuditService().
+				WithAuditRepo(mockAuditRepo)
+
+			got, err := service.ListEvents(context.Background(), tt.filter)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				ids := make([]int64, 0, len(got.Events))
+				for _, ev := range got.Events {
+					ids = append(ids, ev.ID)
+				}
+				assert.Equal(t, tt.expectedIDs, ids)
+				assert.Equal(t, tt.expectedNext, got.NextCursor)
+			}
+
+			mockAuditRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTeamService_AddTeam_RecordsAudit(t *testing.T) {
+	mockTx := new(MockTransactor)
+	mockTeamRepo := new(MockTeamRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockPRRepo := new(MockPullRequestRepository)
+	mockAuditRepo := new(MockAuditRepository)
+
+	mockTeamRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockUserRepo.On("Upsert", mock.Anything, mock.Anything).Return(nil)
+	mockTeamRepo.On("GetTeamsFallingBackTo", mock.Anything, "backend").Return([]string{}, nil)
+	mockPRRepo.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{}, nil)
+	mockAuditRepo.On("Append", mock.Anything, mock.MatchedBy(func(ev *repository.AuditEvent) bool {
+		var after model.Team
+		return ev.Actor == "admin" &&
+			ev.RequestID == "req-1" &&
+			ev.Action == model.AuditActionTeamAdd &&
+			ev.EntityType == model.AuditEntityTeam &&
+			ev.EntityID == "backend" &&
+			ev.Before == nil &&
+			json.Unmarshal(ev.After, &after) == nil && after.Name == "backend"
+	})).Return(nil).Once()
+
+	service := NewTeamService(mockTx).
+		WithTeamRepo(mockTeamRepo).
+		WithUserRepo(mockUserRepo).
+		WithAuditRepo(mockAuditRepo).
+		WithPullRequestService(NewPullRequestService(mockTx).WithTeamRepo(mockTeamRepo).WithPullRequestRepo(mockPRRepo))
+
+	ctx := audit.WithRequestID(audit.WithActor(context.Background(), "admin"), "req-1")
+	err := service.AddTeam(ctx, &model.Team{
+		Name:    "backend",
+		Members: []*model.TeamMember{{UserID: "user1", Username: "john", IsActive: true}},
+	})
+
+	assert.Nil(t, err)
+	mockAuditRepo.AssertExpectations(t)
+}
+
+func TestTeamService_AddTeam_AuditFailureAborts(t *testing.T) {
+	mockTx := new(MockTransactor)
+	mockTeamRepo := new(MockTeamRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockPRRepo := new(MockPullRequestRepository)
+	mockAuditRepo := new(MockAuditRepository)
+
+	mockTeamRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockUserRepo.On("Upsert", mock.Anything, mock.Anything).Return(nil)
+	mockTeamRepo.On("GetTeamsFallingBackTo", mock.Anything, "backend").Return([]string{}, nil).Maybe()
+	mockPRRepo.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{}, nil).Maybe()
+	mockAuditRepo.On("Append", mock.Anything, mock.Anything).Return(errors.New("db error"))
+
+	service := NewTeamService(mockTx).
+		WithTeamRepo(mockTeamRepo).
+		WithUserRepo(mockUserRepo).
+		WithAuditRepo(mockAuditRepo).
+		WithPullRequestService(NewPullRequestService(mockTx).WithTeamRepo(mockTeamRepo).WithPullRequestRepo(mockPRRepo))
+
+	err := service.AddTeam(context.Background(), &model.Team{Name: "backend"})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrorCodeUnspecified, err.Code)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/yakoovad/avito-winter-2025/internal/audit"
+	"github.com/yakoovad/avito-winter-2025/internal/auth"
+	"github.com/yakoovad/avito-winter-2025/internal/db"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"github.com/yakoovad/avito-winter-2025/pkg/logger"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+type AuthService struct {
+	tx db.Transactor
+
+	tokens repository.RevokedTokenRepository
+	audits repository.AuditRepository
+
+	// revoked Cache of revoked token IDs checked on every request. The value is when the ID was revoked
+	// by this replica, zero for IDs loaded from the database.
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+
+	now func() time.Time
+}
+
+func NewAuthService(tx db.Transactor) *AuthService {
+	return &AuthService{
+		tx:      tx,
+		revoked: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// RevokeToken Rejects the token from now on, before its exp. The token itself gives the jti, subject and expiry,
+// a bare jti is revoked without expiry. Other replicas pick the revocation up on their next cache refresh.
+func (a *AuthService) RevokeToken(ctx context.Context, req *model.TokenRevocation) (*model.RevokedToken, *Error) {
+	l := logger.FromContext(ctx)
+
+	repoToken := &repository.RevokedToken{
+		ID:        req.ID,
+		Reason:    req.Reason,
+		RevokedBy: audit.ActorFromContext(ctx),
+	}
+
+	if req.Token != "" {
+		claims, err := auth.DecodeToken(req.Token)
+		if err != nil {
+			return nil, NewError(ErrorCodeInvalidBody, "malformed token")
+		}
+		if claims.ID == "" {
+			return nil, NewError(ErrorCodeInvalidBody, "token has no jti and cannot be revoked")
+		}
+		if req.ID != "" && req.ID != claims.ID {
+			return nil, NewError(ErrorCodeInvalidBody, "jti does not match the token")
+		}
+
+		repoToken.ID = claims.ID
+		repoToken.Subject = claims.Subject
+		if claims.ExpiresAt != nil {
+			repoToken.ExpiresAt = &claims.ExpiresAt.Time
+		}
+	}
+
+	l.Info("revoking token", zap.String("jti", repoToken.ID), zap.String("sub", repoToken.Subject))
+
+	res := &model.RevokedToken{}
+
+	err := a.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		err := a.tokens.Revoke(txCtx, repoToken)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			l.Warn("token already revoked", zap.String("jti", repoToken.ID))
+			return NewError(ErrorCodeAlreadyRevoked, "token already revoked")
+		}
+		if err != nil {
+			l.Error("failed to revoke token", zap.String("jti", repoToken.ID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to revoke token")
+		}
+
+		*res = *toModelRevokedToken(repoToken)
+
+		return recordAudit(txCtx, a.audits, model.AuditActionTokenRevoke, model.AuditEntityToken, repoToken.ID, nil, res)
+	})
+
+	var resErr *Error
+	errors.As(err, &resErr)
+
+	if resErr != nil {
+		return nil, resErr
+	}
+
+	a.mu.Lock()
+	a.revoked[res.ID] = a.now()
+	a.mu.Unlock()
+
+	return res, nil
+}
+
+// ListRevokedTokens Returns the revoked tokens that have not expired yet, newest revocation first
+func (a *AuthService) ListRevokedTokens(ctx context.Context) (*model.RevokedTokenList, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("listing revoked tokens")
+
+	repoTokens, err := a.tokens.ListActive(ctx, a.now())
+	if err != nil {
+		l.Error("failed to list revoked tokens", zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to list revoked tokens")
+	}
+
+	res := &model.RevokedTokenList{Tokens: make([]*model.RevokedToken, 0, len(repoTokens))}
+	for _, t := range repoTokens {
+		res.Tokens = append(res.Tokens, toModelRevokedToken(t))
+	}
+
+	return res, nil
+}
+
+// RefreshRevokedTokens Replaces the cache with the revoked tokens from the database.
+// IDs revoked by this replica while the query ran are kept.
+func (a *AuthService) RefreshRevokedTokens(ctx context.Context) *Error {
+	l := logger.FromContext(ctx)
+
+	started := a.now()
+
+	repoTokens, err := a.tokens.ListActive(ctx, started)
+	if err != nil {
+		l.Error("failed to refresh revoked tokens", zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to refresh revoked tokens")
+	}
+
+	revoked := make(map[string]time.Time, len(repoTokens))
+	for _, t := range repoTokens {
+		revoked[t.ID] = time.Time{}
+	}
+
+	a.mu.Lock()
+	for id, revokedAt := range a.revoked {
+		if !revokedAt.Before(started) {
+			revoked[id] = revokedAt
+		}
+	}
+	a.revoked = revoked
+	a.mu.Unlock()
+
+	l.Debug("revoked tokens refreshed", zap.Int("count", len(revoked)))
+
+	return nil
+}
+
+// IsRevoked Checks the cache only, it never queries the database
+func (a *AuthService) IsRevoked(jti string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	_, ok := a.revoked[jti]
+	return ok
+}
+
+func (a *AuthService) WithRevokedTokenRepo(r repository.RevokedTokenRepository) *AuthService {
+	a.tokens = r
+	return a
+}
+
+func (a *AuthService) WithAuditRepo(r repository.AuditRepository) *AuthService {
+	a.audits = r
+	return a
+}
+
+func toModelRevokedToken(t *repository.RevokedToken) *model.RevokedToken {
+	return &model.RevokedToken{
+		ID:        t.ID,
+		Subject:   t.Subject,
+		Reason:    t.Reason,
+		RevokedBy: t.RevokedBy,
+		ExpiresAt: t.ExpiresAt,
+		RevokedAt: t.RevokedAt,
+	}
+}
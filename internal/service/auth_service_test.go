@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yakoovad/avito-winter-2025/internal/audit"
+	"github.com/yakoovad/avito-winter-2025/internal/auth"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestAuthService_RevokeToken(t *testing.T) {
+	auth.TokenSecretKey = "test-secret-key"
+
+	token, err := auth.GenerateToken(auth.TokenSpec{Type: auth.TokenTypeUser, Subject: "u1", Team: "backend", TTL: time.Hour})
+	require.NoError(t, err)
+	claims, err := auth.DecodeToken(token)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		req           *model.TokenRevocation
+		setupMocks    func(*MockRevokedTokenRepository, *MockAuditRepository)
+		expectedError bool
+		errorCode     ErrorCode
+		expectedJTI   string
+		expectedSub   string
+	}{
+		{
+			name: "success: revoke by token",
+			req:  &model.TokenRevocation{Token: token, Reason: "leaked"},
+			setupMocks: func(tr *MockRevokedTokenRepository, ar *MockAuditRepository) {
+				tr.On("Revoke", mock.Anything, mock.MatchedBy(func(rt *repository.RevokedToken) bool {
+					return rt.ID == claims.ID &&
+						rt.Subject == "u1" &&
+						rt.Reason == "leaked" &&
+						rt.RevokedBy == "admin" &&
+						rt.ExpiresAt != nil && rt.ExpiresAt.Equal(claims.ExpiresAt.Time)
+				})).Return(nil)
+				ar.On("Append", mock.Anything, mock.MatchedBy(func(ev *repository.AuditEvent) bool {
+					return ev.Action == model.AuditActionTokenRevoke &&
+						ev.EntityType == model.AuditEntityToken &&
+						ev.EntityID == claims.ID
+				})).Return(nil).Once()
+			},
+			expectedJTI: claims.ID,
+			expectedSub: "u1",
+		},
+		{
+			name: "success: revoke by jti",
+			req:  &model.TokenRevocation{ID: "abc"},
+			setupMocks: func(tr *MockRevokedTokenRepository, ar *MockAuditRepository) {
+				tr.On("Revoke", mock.Anything, &repository.RevokedToken{ID: "abc", RevokedBy: "admin"}).Return(nil)
+				ar.On("Append", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			expectedJTI: "abc",
+		},
+		{
+			name:          "failure: malformed token",
+			req:           &model.TokenRevocation{Token: "not-a-token"},
+			setupMocks:    func(tr *MockRevokedTokenRepository, ar *MockAuditRepository) {},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name:          "failure: jti does not match the token",
+			req:           &model.TokenRevocation{Token: token, ID: "abc"},
+			setupMocks:    func(tr *MockRevokedTokenRepository, ar *MockAuditRepository) {},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name: "failure: already revoked",
+			req:  &model.TokenRevocation{ID: "abc"},
+			setupMocks: func(tr *MockRevokedTokenRepository, ar *MockAuditRepository) {
+				tr.On("Revoke", mock.Anything, mock.Anything).Return(repository.ErrAlreadyExists)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeAlreadyRevoked,
+		},
+		{
+			name: "failure: audit failed",
+			req:  &model.TokenRevocation{ID: "abc"},
+			setupMocks: func(tr *MockRevokedTokenRepository, ar *MockAuditRepository) {
+				tr.On("Revoke", mock.Anything, mock.Anything).Return(nil)
+				ar.On("Append", mock.Anything, mock.Anything).Return(errors.New("db error"))
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUnspecified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokenRepo := new(MockRevokedTokenRepository)
+			mockAuditRepo := new(MockAuditRepository)
+
+			tt.setupMocks(mockTokenRepo, mockAuditRepo)
+
+			service := NewAuthService(new(MockTransactor)).
+				WithRevokedTokenRepo(mockTokenRepo).
+				WithAuditRepo(mockAuditRepo)
+
+			got, err := service.RevokeToken(audit.WithActor(context.Background(), "admin"), tt.req)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				assert.Nil(t, got)
+				assert.False(t, service.IsRevoked(tt.req.ID))
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedJTI, got.ID)
+				assert.Equal(t, tt.expectedSub, got.Subject)
+				assert.True(t, service.IsRevoked(tt.expectedJTI))
+			}
+
+			mockTokenRepo.AssertExpectations(t)
+			mockAuditRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthService_RefreshRevokedTokens(t *testing.T) {
+	now := time.Date(2025, time.January, 6, 12, 0, 0, 0, time.UTC)
+
+	mockTokenRepo := new(MockRevokedTokenRepository)
+	service := NewAuthService(new(MockTransactor)).
+		WithRevokedTokenRepo(mockTokenRepo)
+	service.now = func() time.Time { return now }
+
+	// "local" was revoked by this replica while the query ran, "gone" expired since the previous refresh
+	service.revoked["gone"] = time.Time{}
+	mockTokenRepo.On("ListActive", mock.Anything, now).Run(func(mock.Arguments) {
+		service.revoked["local"] = now
+	}).Return([]*repository.RevokedToken{{ID: "db"}}, nil).Once()
+
+	err := service.RefreshRevokedTokens(context.Background())
+
+	assert.Nil(t, err)
+	assert.True(t, service.IsRevoked("db"))
+	assert.True(t, service.IsRevoked("local"))
+	assert.False(t, service.IsRevoked("gone"))
+
+	mockTokenRepo.On("ListActive", mock.Anything, now).Return(nil, errors.New("db error")).Once()
+
+	err = service.RefreshRevokedTokens(context.Background())
+
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrorCodeUnspecified, err.Code)
+	assert.True(t, service.IsRevoked("db"))
+
+	mockTokenRepo.AssertExpectations(t)
+}
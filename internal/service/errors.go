@@ -3,16 +3,26 @@ package service
 type ErrorCode string
 
 const (
-	ErrorCodeTeamExists   ErrorCode = "TEAM_EXISTS"
-	ErrorCodePRExists     ErrorCode = "PR_EXISTS"
-	ErrorCodePRMerged     ErrorCode = "PR_MERGED"
-	ErrorCodeNotAssigned  ErrorCode = "NOT_ASSIGNED"
-	ErrorCodeNoCandidate  ErrorCode = "NO_CANDIDATE"
-	ErrorCodeNotFound     ErrorCode = "NOT_FOUND"
-	ErrorCodeUnspecified  ErrorCode = "UNSPECIFIED"
-	ErrorCodeInvalidBody  ErrorCode = "INVALID_BODY"
-	ErrorCodeUserInactive ErrorCode = "USER_INACTIVE"
-	ErrorCodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	ErrorCodeTeamExists        ErrorCode = "TEAM_EXISTS"
+	ErrorCodePRExists          ErrorCode = "PR_EXISTS"
+	ErrorCodePRMerged          ErrorCode = "PR_MERGED"
+	ErrorCodeNotAssigned       ErrorCode = "NOT_ASSIGNED"
+	ErrorCodeNoCandidate       ErrorCode = "NO_CANDIDATE"
+	ErrorCodeNotFound          ErrorCode = "NOT_FOUND"
+	ErrorCodeUnspecified       ErrorCode = "UNSPECIFIED"
+	ErrorCodeInvalidBody       ErrorCode = "INVALID_BODY"
+	ErrorCodeUserInactive      ErrorCode = "USER_INACTIVE"
+	ErrorCodeUnauthorized      ErrorCode = "UNAUTHORIZED"
+	ErrorCodeAlreadyApproved   ErrorCode = "ALREADY_APPROVED"
+	ErrorCodeNotApproved       ErrorCode = "NOT_APPROVED"
+	ErrorCodeInvalidTransition ErrorCode = "INVALID_TRANSITION"
+	ErrorCodePRNotOpen         ErrorCode = "PR_NOT_OPEN"
+	ErrorCodeAlreadyAssigned   ErrorCode = "ALREADY_ASSIGNED"
+	ErrorCodeNotEligible       ErrorCode = "NOT_ELIGIBLE"
+	ErrorCodeAtCapacity        ErrorCode = "AT_CAPACITY"
+	ErrorCodeForbidden         ErrorCode = "FORBIDDEN"
+	ErrorCodeAlreadyRevoked    ErrorCode = "ALREADY_REVOKED"
+	ErrorCodeUserAbsent        ErrorCode = "USER_ABSENT"
 )
 
 type Error struct {
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"github.com/stretchr/testify/mock"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
 	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"time"
 )
 
 type MockTransactor struct {
@@ -14,6 +16,10 @@ func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(context.
 	return fn(ctx)
 }
 
+func (m *MockTransactor) Ping(ctx context.Context) error {
+	return nil
+}
+
 type MockUserRepository struct {
 	mock.Mock
 }
@@ -33,9 +39,20 @@ func (m *MockUserRepository) GetUserTeam(ctx context.Context, userID string) ([]
 
 func (m *MockUserRepository) Get(ctx context.Context, userID string) (*repository.User, error) {
 	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(*repository.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetTeamNames(ctx context.Context, userIDs []string) (map[string]string, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 func (m *MockUserRepository) Patch(ctx context.Context, patch *repository.UserPatch) (*repository.User, error) {
 	args := m.Called(ctx, patch)
 	if args.Get(0) == nil {
@@ -44,6 +61,43 @@ func (m *MockUserRepository) Patch(ctx context.Context, patch *repository.UserPa
 	return args.Get(0).(*repository.User), args.Error(1)
 }
 
+func (m *MockUserRepository) SetTeamMembersActive(ctx context.Context, teamName string, userIDs []string, isActive bool) ([]*repository.User, error) {
+	args := m.Called(ctx, teamName, userIDs, isActive)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.User), args.Error(1)
+}
+
+func (m *MockUserRepository) SetMaxOpenReviews(ctx context.Context, userID string, limit *int) (*repository.User, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ReplaceAbsences(ctx context.Context, userID string, absences []*repository.Absence) error {
+	args := m.Called(ctx, userID, absences)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetAbsences(ctx context.Context, userID string) ([]*repository.Absence, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Absence), args.Error(1)
+}
+
+func (m *MockUserRepository) GetTeamAbsences(ctx context.Context, teamName string, after time.Time) ([]*repository.Absence, error) {
+	args := m.Called(ctx, teamName, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Absence), args.Error(1)
+}
+
 type MockTeamRepository struct {
 	mock.Mock
 }
@@ -69,6 +123,40 @@ func (m *MockTeamRepository) GetTeamMembers(ctx context.Context, name string) ([
 	return args.Get(0).([]*repository.User), args.Error(1)
 }
 
+func (m *MockTeamRepository) GetSettings(ctx context.Context, name string) (*repository.TeamSettings, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.TeamSettings), args.Error(1)
+}
+
+func (m *MockTeamRepository) UpsertSettings(ctx context.Context, settings *repository.TeamSettings) error {
+	args := m.Called(ctx, settings)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) GetTeamsFallingBackTo(ctx context.Context, name string) ([]string, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTeamRepository) GetReviewerRules(ctx context.Context, name string) ([]*repository.ReviewerRule, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.ReviewerRule), args.Error(1)
+}
+
+func (m *MockTeamRepository) ReplaceReviewerRules(ctx context.Context, name string, rules []*repository.ReviewerRule) error {
+	args := m.Called(ctx, name, rules)
+	return args.Error(0)
+}
+
 type MockPullRequestRepository struct {
 	mock.Mock
 }
@@ -94,6 +182,14 @@ func (m *MockPullRequestRepository) Get(ctx context.Context, prID string) (*repo
 	return args.Get(0).(*repository.PullRequest), args.Error(1)
 }
 
+func (m *MockPullRequestRepository) GetForUpdate(ctx context.Context, prID string) (*repository.PullRequest, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.PullRequest), args.Error(1)
+}
+
 func (m *MockPullRequestRepository) GetReviewers(ctx context.Context, prID string) ([]string, error) {
 	args := m.Called(ctx, prID)
 	if args.Get(0) == nil {
@@ -110,6 +206,43 @@ func (m *MockPullRequestRepository) GetReviewPRs(ctx context.Context, userID str
 	return args.Get(0).([]*repository.PullRequest), args.Error(1)
 }
 
+func (m *MockPullRequestRepository) GetNeedMoreReviewers(ctx context.Context, teamName string) ([]*repository.PullRequest, error) {
+	args := m.Called(ctx, teamName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.PullRequest), args.Error(1)
+}
+
+func (m *MockPullRequestRepository) GetOpenReviewPRs(ctx context.Context, userIDs []string) ([]*repository.PullRequest, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.PullRequest), args.Error(1)
+}
+
+func (m *MockPullRequestRepository) GetReviewersOf(ctx context.Context, prIDs []string) (map[string][]string, error) {
+	args := m.Called(ctx, prIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]string), args.Error(1)
+}
+
+func (m *MockPullRequestRepository) SetNeedMoreReviewers(ctx context.Context, prIDs []string, needMore bool) error {
+	args := m.Called(ctx, prIDs, needMore)
+	return args.Error(0)
+}
+
+func (m *MockPullRequestRepository) GetStale(ctx context.Context, now time.Time) ([]*repository.StalePullRequest, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.StalePullRequest), args.Error(1)
+}
+
 type MockReviewRepository struct {
 	mock.Mock
 }
@@ -119,7 +252,122 @@ func (m *MockReviewRepository) Assign(ctx context.Context, prID string, reviewer
 	return args.Error(0)
 }
 
+func (m *MockReviewRepository) AssignMany(ctx context.Context, reviews []*repository.Review) error {
+	args := m.Called(ctx, reviews)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) UnassignMany(ctx context.Context, prIDs []string, reviewerIDs []string) error {
+	args := m.Called(ctx, prIDs, reviewerIDs)
+	return args.Error(0)
+}
+
 func (m *MockReviewRepository) Unassign(ctx context.Context, prID string, reviewerIDs string) error {
 	args := m.Called(ctx, prID, reviewerIDs)
 	return args.Error(0)
 }
+
+func (m *MockReviewRepository) GetReviews(ctx context.Context, prID string) ([]*repository.Review, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) SetState(ctx context.Context, prID, reviewerID string, state model.ReviewState) error {
+	args := m.Called(ctx, prID, reviewerID, state)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) RecordReassignments(ctx context.Context, reassignments []*repository.Reassignment) error {
+	args := m.Called(ctx, reassignments)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) CountTeamOpenReviews(ctx context.Context, teamName string) (map[string]int, error) {
+	args := m.Called(ctx, teamName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockReviewRepository) PeekTeamOpenReviews(ctx context.Context, teamName string) (map[string]int, error) {
+	args := m.Called(ctx, teamName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+type MockStatsRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatsRepository) GetReviewerStats(ctx context.Context, filter *repository.StatsFilter) ([]*repository.ReviewerStats, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.ReviewerStats), args.Error(1)
+}
+
+func (m *MockStatsRepository) GetPullRequestStats(ctx context.Context, filter *repository.StatsFilter, bucket model.StatsBucket) ([]*repository.PullRequestStats, error) {
+	args := m.Called(ctx, filter, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.PullRequestStats), args.Error(1)
+}
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Append(ctx context.Context, event *repository.AuditEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter *repository.AuditFilter) ([]*repository.AuditEvent, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.AuditEvent), args.Error(1)
+}
+
+type MockPullRequestEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockPullRequestEventRepository) Append(ctx context.Context, events []*repository.PullRequestEvent) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *MockPullRequestEventRepository) List(ctx context.Context, prID string) ([]*repository.PullRequestEvent, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.PullRequestEvent), args.Error(1)
+}
+
+type MockRevokedTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRevokedTokenRepository) Revoke(ctx context.Context, token *repository.RevokedToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRevokedTokenRepository) ListActive(ctx context.Context, now time.Time) ([]*repository.RevokedToken, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.RevokedToken), args.Error(1)
+}
@@ -3,12 +3,15 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/yakoovad/avito-winter-2025/internal/audit"
 	"github.com/yakoovad/avito-winter-2025/internal/db"
 	"github.com/yakoovad/avito-winter-2025/internal/model"
 	"github.com/yakoovad/avito-winter-2025/internal/repository"
 	"github.com/yakoovad/avito-winter-2025/pkg/logger"
 	"go.uber.org/zap"
 	"slices"
+	"time"
 )
 
 type PullRequestService struct {
@@ -18,12 +21,28 @@ type PullRequestService struct {
 	teams   repository.TeamRepository
 	prs     repository.PullRequestRepository
 	reviews repository.ReviewRepository
+	audits  repository.AuditRepository
+	events  repository.PullRequestEventRepository
+
+	selector      ReviewerSelector
+	teamSelectors map[string]ReviewerSelector
+
+	// maxOpenReviews is the default cap of OPEN reviews per user, 0 means unlimited
+	maxOpenReviews int
 }
 
+// NewPullRequestService Selects reviewers least loaded first like NewReviewerSelector does by default,
+// WithReviewerSelector replaces the strategy
 func NewPullRequestService(tx db.Transactor) *PullRequestService {
-	return &PullRequestService{
-		tx: tx,
+	p := &PullRequestService{
+		tx:            tx,
+		teamSelectors: make(map[string]ReviewerSelector),
 	}
+	// The review repository is set later by WithReviewRepo, so the loader resolves it on every call
+	p.selector = NewLeastLoadedSelector(reviewLoaderFunc(func(ctx context.Context, teamName string) (map[string]int, error) {
+		return p.reviews.CountTeamOpenReviews(ctx, teamName)
+	}), time.Now().UnixNano())
+	return p
 }
 
 func (p *PullRequestService) GetUserReview(ctx context.Context, userID string) (*model.UserReviews, *Error) {
@@ -56,11 +75,85 @@ func (p *PullRequestService) GetUserReview(ctx context.Context, userID string) (
 	return res, nil
 }
 
-func (p *PullRequestService) ReassignPullRequest(ctx context.Context, prID, userID string) (*model.PullRequest, *Error) {
+// GetAuthorTeam Returns the team of the PR author, callers use it to check access to the PR
+func (p *PullRequestService) GetAuthorTeam(ctx context.Context, prID string) (string, *Error) {
+	l := logger.FromContext(ctx)
+
+	repoPR, err := p.prs.Get(ctx, prID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		l.Warn("PR not found", zap.String("pull_request_id", prID))
+		return "", NewError(ErrorCodeNotFound, "PR not found")
+	case err != nil:
+		l.Error("failed to get PR", zap.String("pull_request_id", prID), zap.Error(err))
+		return "", NewError(ErrorCodeUnspecified, "failed to get PR")
+	}
+
+	author, err := p.users.Get(ctx, repoPR.AuthorID)
+	if err != nil {
+		l.Error("failed to get PR author", zap.String("author_id", repoPR.AuthorID), zap.Error(err))
+		return "", NewError(ErrorCodeUnspecified, "failed to get PR author")
+	}
+
+	return author.TeamName, nil
+}
+
+// GetHistory Returns the timeline of the PR oldest first, changes made before the history was introduced are not included
+func (p *PullRequestService) GetHistory(ctx context.Context, prID string) (*model.PullRequestHistory, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("getting pull request history", zap.String("pull_request_id", prID))
+
+	_, err := p.prs.Get(ctx, prID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		l.Warn("PR not found", zap.String("pull_request_id", prID))
+		return nil, NewError(ErrorCodeNotFound, "PR not found")
+	case err != nil:
+		l.Error("failed to get PR", zap.String("pull_request_id", prID), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get PR")
+	}
+
+	repoEvents, err := p.events.List(ctx, prID)
+	if err != nil {
+		l.Error("failed to get PR history", zap.String("pull_request_id", prID), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get PR history")
+	}
+
+	res := &model.PullRequestHistory{
+		PullRequestID: prID,
+		Events:        make([]*model.PullRequestEvent, 0, len(repoEvents)),
+	}
+	for _, ev := range repoEvents {
+		res.Events = append(res.Events, &model.PullRequestEvent{
+			ID:            ev.ID,
+			Type:          ev.Type,
+			Actor:         ev.Actor,
+			ReviewerID:    ev.ReviewerID,
+			NewReviewerID: ev.NewReviewerID,
+			ReviewState:   ev.ReviewState,
+			FromStatus:    ev.FromStatus,
+			ToStatus:      ev.ToStatus,
+			Reason:        ev.Reason,
+			CreatedAt:     ev.CreatedAt,
+		})
+	}
+
+	return res, nil
+}
+
+// ReassignPullRequest Replaces the reviewer with another active teammate, or with newUserID when it is set.
+// The chosen replacement must pass the same checks as AddReviewer. Settings and rules of the author's team apply.
+// A reviewer who has already approved is kept unless force is set.
+func (p *PullRequestService) ReassignPullRequest(ctx context.Context, prID, userID, newUserID string, force bool) (*model.PullRequestReassignment, *Error) {
 	l := logger.FromContext(ctx)
-	l.Info("reassigning pull request", zap.String("pull_request_id", prID), zap.String("user_id", userID))
+	l.Info("reassigning pull request",
+		zap.String("pull_request_id", prID),
+		zap.String("user_id", userID),
+		zap.String("new_user_id", newUserID),
+		zap.Bool("force", force))
 
 	pr := &model.PullRequest{}
+	res := &model.PullRequestReassignment{PullRequest: pr}
 
 	err := p.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
 		repoTeam, err := p.users.GetUserTeam(txCtx, userID)
@@ -73,48 +166,81 @@ func (p *PullRequestService) ReassignPullRequest(ctx context.Context, prID, user
 			return NewError(ErrorCodeUnspecified, "failed to get user team")
 		}
 
-		team := make([]*model.User, 0, len(repoTeam))
-		for i := range repoTeam {
-			team = append(team, &model.User{
-				ID:       repoTeam[i].ID,
-				Username: repoTeam[i].Username,
-				IsActive: repoTeam[i].IsActive,
-				TeamName: repoTeam[i].TeamName,
-			})
+		team := toModelUsers(repoTeam)
+		teamName := repoTeam[0].TeamName
+
+		repoPR, reviews, err := p.getEditablePR(txCtx, prID)
+		if err != nil {
+			return err
 		}
+		reviewers := reviewerIDs(reviews)
 
-		repoPR, err := p.prs.Get(txCtx, prID)
-		switch {
-		case errors.Is(err, repository.ErrNotFound):
-			l.Warn("PR not found", zap.String("pull_request_id", prID))
-			return NewError(ErrorCodeNotFound, "PR not found")
-		case err != nil:
-			l.Error("failed to get PR", zap.String("pull_request_id", prID), zap.Error(err))
-			return NewError(ErrorCodeUnspecified, "failed to get PR")
+		idx := slices.IndexFunc(reviews, func(r *model.Review) bool { return r.UserID == userID })
+		if idx < 0 {
+			l.Warn("reviewer not assigned to PR", zap.String("pull_request_id", prID), zap.String("user_id", userID))
+			return NewError(ErrorCodeNotAssigned, "reviewer is not assigned to this PR")
 		}
 
-		if repoPR.Status == model.PRStatusMerged {
-			l.Warn("cannot reassign merged PR", zap.String("pull_request_id", prID))
-			return NewError(ErrorCodePRMerged, "cannot reassign on merged PR")
+		if reviews[idx].State == model.ReviewStateApproved && !force {
+			l.Warn("approved reviewer cannot be reassigned", zap.String("pull_request_id", prID), zap.String("user_id", userID))
+			return NewError(ErrorCodeAlreadyApproved, "reviewer has already approved this PR")
 		}
 
-		reviewers, err := p.prs.GetReviewers(txCtx, prID)
+		author, err := p.users.Get(txCtx, repoPR.AuthorID)
 		if err != nil {
-			l.Error("failed to get reviewers", zap.String("pull_request_id", prID), zap.Error(err))
-			return NewError(ErrorCodeUnspecified, "failed to get reviewers")
+			l.Error("failed to get PR author", zap.String("author_id", repoPR.AuthorID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get PR author")
 		}
 
-		if !slices.Contains(reviewers, userID) {
-			l.Warn("reviewer not assigned to PR", zap.String("pull_request_id", prID), zap.String("user_id", userID))
-			return NewError(ErrorCodeNotAssigned, "reviewer is not assigned to this PR")
+		// the rules of the author's team apply even when the replaced reviewer is external
+		settings, err := loadTeamSettings(txCtx, p.teams, author.TeamName)
+		if err != nil {
+			l.Error("failed to get team settings", zap.String("team_name", author.TeamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get team settings")
 		}
 
-		newReviewer := p.selectReplacementReviewer(repoPR.AuthorID, reviewers, team)
-		if newReviewer == "" {
-			l.Warn("no replacement candidate found", zap.String("pull_request_id", prID))
-			return NewError(ErrorCodeNoCandidate, "no active replacement candidate in team")
+		if settings.LeadMandatory && settings.LeadID == userID && isActiveMember(team, userID) {
+			l.Warn("mandatory team lead cannot be reassigned", zap.String("pull_request_id", prID), zap.String("user_id", userID))
+			return NewError(ErrorCodeNoCandidate, "team lead is a mandatory reviewer and cannot be replaced")
+		}
+
+		newReviewer := newUserID
+		if newReviewer != "" {
+			if _, err = p.checkReviewer(txCtx, repoPR, reviews, newReviewer, settings); err != nil {
+				return err
+			}
+		} else {
+			rules, err := loadReviewerRules(txCtx, p.teams, author.TeamName)
+			if err != nil {
+				l.Error("failed to get reviewer rules", zap.String("team_name", author.TeamName), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to get reviewer rules")
+			}
+			remaining := slices.DeleteFunc(slices.Clone(reviewers), func(id string) bool { return id == userID })
+			selectCtx, prRules := withReviewerRules(txCtx, rules, repoPR.AuthorID, remaining)
+
+			// candidates come from the replaced reviewer's team first, then from the author's fallback teams
+			exclude := append([]string{repoPR.AuthorID}, reviewers...)
+			replacement, err := p.selectReplacement(selectCtx, settings, teamName, exclude, remaining, team)
+			if err == nil && len(replacement) == 0 {
+				replacement, err = p.pickFallbackReviewers(selectCtx, settings, exclude, 1)
+			}
+			if err != nil {
+				l.Error("failed to select replacement reviewer", zap.String("pull_request_id", prID), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to select replacement reviewer")
+			}
+			if len(replacement) == 0 {
+				if reason := prRules.explain(); reason != "" {
+					l.Warn("reviewer rules eliminated all candidates", zap.String("pull_request_id", prID), zap.String("reason", reason))
+					return NewError(ErrorCodeNoCandidate, "no replacement satisfies the team rules: "+reason)
+				}
+				l.Warn("no replacement candidate found", zap.String("pull_request_id", prID))
+				return NewError(ErrorCodeNoCandidate, "no active replacement candidate in team or its fallback teams")
+			}
+			newReviewer = replacement[0]
 		}
 
+		before := snapshotPullRequest(repoPR, reviews)
+
 		if err = p.reviews.Unassign(txCtx, prID, userID); err != nil {
 			l.Error("failed to unassign old reviewer", zap.String("pull_request_id", prID), zap.String("user_id", userID), zap.Error(err))
 			return NewError(ErrorCodeUnspecified, "failed to unassign old reviewer")
@@ -125,150 +251,405 @@ func (p *PullRequestService) ReassignPullRequest(ctx context.Context, prID, user
 			return NewError(ErrorCodeUnspecified, "failed to assign new reviewer")
 		}
 
+		if err = p.reviews.RecordReassignments(txCtx, []*repository.Reassignment{
+			{PullRequestID: prID, OldReviewerID: userID, NewReviewerID: &newReviewer},
+		}); err != nil {
+			l.Error("failed to record reassignment", zap.String("pull_request_id", prID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to record reassignment")
+		}
+
+		if err = p.recordEvents(txCtx, &repository.PullRequestEvent{
+			PullRequestID: prID,
+			Type:          model.PREventReviewerReassigned,
+			ReviewerID:    userID,
+			NewReviewerID: newReviewer,
+			Reason:        model.PREventReasonManual,
+		}); err != nil {
+			return err
+		}
+
 		l.Debug("reviewer reassigned successfully",
 			zap.String("pull_request_id", prID),
 			zap.String("old_reviewer", userID),
 			zap.String("new_reviewer", newReviewer))
 
-		pr.CreatedAt = repoPR.CreatedAt
-		pr.MergedAt = repoPR.MergedAt
-		pr.Name = repoPR.Name
-		pr.Status = repoPR.Status
-		pr.AuthorID = repoPR.AuthorID
-		pr.Reviewers = reviewers
+		reviews[idx] = &model.Review{UserID: newReviewer, State: model.ReviewStatePending}
 
-		return nil
+		if err = p.fillPullRequest(txCtx, pr, repoPR, reviews); err != nil {
+			return err
+		}
+		res.ReplacedBy = newReviewer
+
+		return recordAudit(txCtx, p.audits, model.AuditActionPRReassign, model.AuditEntityPullRequest, prID, before, res)
+	})
+
+	var srvErr *Error
+	errors.As(err, &srvErr)
+
+	if srvErr != nil {
+		l.Error("reassign PR operation failed", zap.String("pull_request_id", prID), zap.Error(srvErr))
+		return res, srvErr
+	}
+
+	return res, nil
+}
+
+// AddReviewer Assigns the chosen user to an OPEN PR on top of the current reviewers.
+// The reviewer must be active, must not be the author and, unless the author's team allows
+// external reviewers, must belong to the author's team.
+func (p *PullRequestService) AddReviewer(ctx context.Context, prID, userID string) (*model.PullRequest, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("adding reviewer", zap.String("pull_request_id", prID), zap.String("user_id", userID))
+
+	pr := &model.PullRequest{}
+
+	err := p.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		repoPR, reviews, err := p.getEditablePR(txCtx, prID)
+		if err != nil {
+			return err
+		}
+
+		author, err := p.users.Get(txCtx, repoPR.AuthorID)
+		if err != nil {
+			l.Error("failed to get PR author", zap.String("author_id", repoPR.AuthorID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get PR author")
+		}
+
+		settings, err := loadTeamSettings(txCtx, p.teams, author.TeamName)
+		if err != nil {
+			l.Error("failed to get team settings", zap.String("team_name", author.TeamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get team settings")
+		}
+
+		if _, err = p.checkReviewer(txCtx, repoPR, reviews, userID, settings); err != nil {
+			return err
+		}
+		before := snapshotPullRequest(repoPR, reviews)
+
+		if err = p.reviews.Assign(txCtx, prID, []string{userID}); err != nil {
+			l.Error("failed to assign reviewer", zap.String("pull_request_id", prID), zap.String("user_id", userID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to assign reviewer")
+		}
+		reviews = append(reviews, &model.Review{UserID: userID, State: model.ReviewStatePending})
+
+		if err = p.recordEvents(txCtx, reviewerEvents(prID, model.PREventReviewerAssigned, []string{userID}, model.PREventReasonManual)...); err != nil {
+			return err
+		}
+
+		if repoPR, err = p.syncNeedMoreReviewers(txCtx, repoPR, settings, len(reviews)); err != nil {
+			return err
+		}
+
+		l.Debug("reviewer added", zap.String("pull_request_id", prID), zap.String("user_id", userID))
+
+		if err = p.fillPullRequest(txCtx, pr, repoPR, reviews); err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, p.audits, model.AuditActionPRAddReviewer, model.AuditEntityPullRequest, prID, before, pr)
 	})
 
 	var res *Error
 	errors.As(err, &res)
 
 	if res != nil {
-		l.Error("reassign PR operation failed", zap.String("pull_request_id", prID), zap.Error(res))
+		return nil, res
 	}
 
-	return pr, res
+	return pr, nil
 }
 
-func (p *PullRequestService) MergePullRequest(ctx context.Context, prID string) (*model.PullRequest, *Error) {
+// RemoveReviewer Unassigns the reviewer from an OPEN PR without picking a replacement,
+// the PR is flagged with need_more_reviewers when it drops below min_reviewers.
+func (p *PullRequestService) RemoveReviewer(ctx context.Context, prID, userID string) (*model.PullRequest, *Error) {
 	l := logger.FromContext(ctx)
-	l.Info("merging pull request", zap.String("pull_request_id", prID))
+	l.Info("removing reviewer", zap.String("pull_request_id", prID), zap.String("user_id", userID))
 
 	pr := &model.PullRequest{}
 
 	err := p.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
-		status := model.PRStatusMerged
-		repoPR, err := p.prs.Patch(txCtx, &repository.PullRequestPatch{
-			ID:     prID,
-			Status: &status,
-		})
-		switch {
-		case errors.Is(err, repository.ErrNotFound):
-			l.Warn("PR not found", zap.String("pull_request_id", prID))
-			return NewError(ErrorCodeNotFound, "PR not found")
-		case err != nil:
-			l.Error("failed to patch PR", zap.String("pull_request_id", prID), zap.Error(err))
-			return NewError(ErrorCodeUnspecified, "failed to get PR")
+		repoPR, reviews, err := p.getEditablePR(txCtx, prID)
+		if err != nil {
+			return err
+		}
+
+		idx := slices.IndexFunc(reviews, func(r *model.Review) bool { return r.UserID == userID })
+		if idx < 0 {
+			l.Warn("reviewer not assigned to PR", zap.String("pull_request_id", prID), zap.String("user_id", userID))
+			return NewError(ErrorCodeNotAssigned, "reviewer is not assigned to this PR")
+		}
+		before := snapshotPullRequest(repoPR, reviews)
+
+		if err = p.reviews.Unassign(txCtx, prID, userID); err != nil {
+			l.Error("failed to unassign reviewer", zap.String("pull_request_id", prID), zap.String("user_id", userID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to unassign reviewer")
+		}
+		reviews = slices.Delete(reviews, idx, idx+1)
+
+		if err = p.recordEvents(txCtx, reviewerEvents(prID, model.PREventReviewerUnassigned, []string{userID}, model.PREventReasonManual)...); err != nil {
+			return err
 		}
 
-		reviewers, err := p.prs.GetReviewers(txCtx, prID)
+		author, err := p.users.Get(txCtx, repoPR.AuthorID)
 		if err != nil {
-			l.Error("failed to get reviewers", zap.String("pull_request_id", prID), zap.Error(err))
-			return NewError(ErrorCodeUnspecified, "failed to get reviewers")
+			l.Error("failed to get PR author", zap.String("author_id", repoPR.AuthorID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get PR author")
 		}
 
-		l.Debug("PR merged successfully", zap.String("pull_request_id", prID))
+		settings, err := loadTeamSettings(txCtx, p.teams, author.TeamName)
+		if err != nil {
+			l.Error("failed to get team settings", zap.String("team_name", author.TeamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get team settings")
+		}
+
+		if repoPR, err = p.syncNeedMoreReviewers(txCtx, repoPR, settings, len(reviews)); err != nil {
+			return err
+		}
 
-		pr.CreatedAt = repoPR.CreatedAt
-		pr.MergedAt = repoPR.MergedAt
-		pr.Name = repoPR.Name
-		pr.Status = repoPR.Status
-		pr.AuthorID = repoPR.AuthorID
-		pr.Reviewers = reviewers
+		l.Debug("reviewer removed", zap.String("pull_request_id", prID), zap.String("user_id", userID))
 
-		return nil
+		if err = p.fillPullRequest(txCtx, pr, repoPR, reviews); err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, p.audits, model.AuditActionPRRemoveReviewer, model.AuditEntityPullRequest, prID, before, pr)
 	})
 
 	var res *Error
 	errors.As(err, &res)
 
-	return pr, res
+	if res != nil {
+		return nil, res
+	}
+
+	return pr, nil
 }
 
-// CreatePullRequest Create a new pull request and assign two team members as reviewers
-func (p *PullRequestService) CreatePullRequest(ctx context.Context, short *model.PullRequestShort) (*model.PullRequest, *Error) {
+// checkReviewer Validates a manually chosen reviewer: an existing active user other than the author, not absent,
+// not assigned yet, not excluded from the author's PRs by a team rule, below their cap of OPEN reviews
+// and a member of settings.TeamName or one of its fallback teams unless the team allows external reviewers
+func (p *PullRequestService) checkReviewer(ctx context.Context, repoPR *repository.PullRequest, reviews []*model.Review, userID string, settings *model.TeamSettings) (*repository.User, error) {
 	l := logger.FromContext(ctx)
-	l.Info("creating pull request",
-		zap.String("pull_request_id", short.ID),
-		zap.String("pr_name", short.Name),
-		zap.String("author_id", short.AuthorID))
+
+	if userID == repoPR.AuthorID {
+		l.Warn("author cannot review own PR", zap.String("pull_request_id", repoPR.ID), zap.String("user_id", userID))
+		return nil, NewError(ErrorCodeNotEligible, "author cannot review own PR")
+	}
+
+	if slices.ContainsFunc(reviews, func(r *model.Review) bool { return r.UserID == userID }) {
+		l.Warn("reviewer already assigned", zap.String("pull_request_id", repoPR.ID), zap.String("user_id", userID))
+		return nil, NewError(ErrorCodeAlreadyAssigned, "reviewer is already assigned to this PR")
+	}
+
+	reviewer, err := p.users.Get(ctx, userID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		l.Warn("reviewer not found", zap.String("user_id", userID))
+		return nil, NewError(ErrorCodeNotFound, "reviewer not found")
+	case err != nil:
+		l.Error("failed to get reviewer", zap.String("user_id", userID), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get reviewer")
+	}
+
+	if !reviewer.IsActive {
+		l.Warn("inactive user cannot review", zap.String("user_id", userID))
+		return nil, NewError(ErrorCodeUserInactive, "inactive user cannot be assigned as reviewer")
+	}
+
+	if reviewer.Absent {
+		l.Warn("absent user cannot review", zap.String("user_id", userID))
+		return nil, NewError(ErrorCodeUserAbsent, "user is out of office and cannot be assigned as reviewer")
+	}
+
+	if reviewer.TeamName != settings.TeamName && !slices.Contains(settings.FallbackTeams, reviewer.TeamName) && !settings.AllowExternalReviewers {
+		l.Warn("reviewer is outside the team",
+			zap.String("user_id", userID),
+			zap.String("team_name", settings.TeamName))
+		return nil, NewError(ErrorCodeNotEligible, fmt.Sprintf("reviewer is not a member of team %s", settings.TeamName))
+	}
+
+	rules, err := loadReviewerRules(ctx, p.teams, settings.TeamName)
+	if err != nil {
+		l.Error("failed to get reviewer rules", zap.String("team_name", settings.TeamName), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get reviewer rules")
+	}
+	if rules.isExcluded(repoPR.AuthorID, userID) {
+		l.Warn("reviewer excluded by team rule", zap.String("pull_request_id", repoPR.ID), zap.String("user_id", userID))
+		return nil, NewError(ErrorCodeNotEligible, fmt.Sprintf("%s may not review PRs of %s", userID, repoPR.AuthorID))
+	}
+
+	if limit := p.reviewCap(reviewer.MaxOpenReviews); limit > 0 {
+		load, err := p.reviews.CountTeamOpenReviews(ctx, reviewer.TeamName)
+		if err != nil {
+			l.Error("failed to count open reviews", zap.String("team_name", reviewer.TeamName), zap.Error(err))
+			return nil, NewError(ErrorCodeUnspecified, "failed to count open reviews")
+		}
+		if load[userID] >= limit {
+			l.Warn("reviewer at capacity", zap.String("user_id", userID), zap.Int("open_reviews", load[userID]), zap.Int("limit", limit))
+			return nil, NewError(ErrorCodeAtCapacity, fmt.Sprintf("reviewer already has %d of %d allowed open reviews", load[userID], limit))
+		}
+	}
+
+	return reviewer, nil
+}
+
+// getEditablePR Locks the PR and returns it with its reviews, reviewers may only be changed while it is OPEN
+func (p *PullRequestService) getEditablePR(ctx context.Context, prID string) (*repository.PullRequest, []*model.Review, error) {
+	l := logger.FromContext(ctx)
+
+	repoPR, err := p.prs.GetForUpdate(ctx, prID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		l.Warn("PR not found", zap.String("pull_request_id", prID))
+		return nil, nil, NewError(ErrorCodeNotFound, "PR not found")
+	case err != nil:
+		l.Error("failed to get PR", zap.String("pull_request_id", prID), zap.Error(err))
+		return nil, nil, NewError(ErrorCodeUnspecified, "failed to get PR")
+	}
+
+	switch repoPR.Status {
+	case model.PRStatusOpen:
+	case model.PRStatusMerged:
+		l.Warn("cannot change reviewers of merged PR", zap.String("pull_request_id", prID))
+		return nil, nil, NewError(ErrorCodePRMerged, "cannot change reviewers on merged PR")
+	default:
+		l.Warn("cannot change reviewers of PR", zap.String("pull_request_id", prID), zap.String("status", string(repoPR.Status)))
+		return nil, nil, NewError(ErrorCodePRNotOpen, fmt.Sprintf("cannot change reviewers on %s PR", repoPR.Status))
+	}
+
+	repoReviews, err := p.reviews.GetReviews(ctx, prID)
+	if err != nil {
+		l.Error("failed to get reviews", zap.String("pull_request_id", prID), zap.Error(err))
+		return nil, nil, NewError(ErrorCodeUnspecified, "failed to get reviews")
+	}
+
+	return repoPR, toModelReviews(repoReviews), nil
+}
+
+// syncNeedMoreReviewers Updates need_more_reviewers when the reviewer count crossed min_reviewers
+func (p *PullRequestService) syncNeedMoreReviewers(ctx context.Context, repoPR *repository.PullRequest, settings *model.TeamSettings, count int) (*repository.PullRequest, error) {
+	needMore := count < settings.MinReviewers
+	if needMore == repoPR.NeedMoreReviewers {
+		return repoPR, nil
+	}
+
+	updated, err := p.prs.Patch(ctx, &repository.PullRequestPatch{
+		ID:                repoPR.ID,
+		NeedMoreReviewers: &needMore,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to update need_more_reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to update PR")
+	}
+	return updated, nil
+}
+
+// MergePullRequest Marks the PR as MERGED once settings.RequiredApprovals assigned reviewers approved it
+// and nobody has outstanding CHANGES_REQUESTED. With force the check is skipped and the PR is flagged force_merged.
+// The PR row is locked before the check, so decisions submitted concurrently wait until the merge is done.
+func (p *PullRequestService) MergePullRequest(ctx context.Context, prID string, force bool) (*model.PullRequest, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("merging pull request", zap.String("pull_request_id", prID), zap.Bool("force", force))
 
 	pr := &model.PullRequest{}
 
 	err := p.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
-		repoTeam, err := p.users.GetUserTeam(ctx, short.AuthorID)
+		repoPR, err := p.prs.GetForUpdate(txCtx, prID)
 		switch {
 		case errors.Is(err, repository.ErrNotFound):
-			l.Warn("author not found", zap.String("author_id", short.AuthorID))
-			return NewError(ErrorCodeNotFound, "author or PR not found")
+			l.Warn("PR not found", zap.String("pull_request_id", prID))
+			return NewError(ErrorCodeNotFound, "PR not found")
 		case err != nil:
-			l.Error("failed to get author team", zap.String("author_id", short.AuthorID), zap.Error(err))
-			return NewError(ErrorCodeUnspecified, "failed to get author team")
+			l.Error("failed to get PR", zap.String("pull_request_id", prID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get PR")
 		}
 
-		team := make([]*model.User, 0, len(repoTeam))
-		for i := range repoTeam {
-			if repoTeam[i].ID == short.AuthorID && !repoTeam[i].IsActive {
-				l.Warn("inactive user cannot create PR", zap.String("author_id", short.AuthorID))
-				return NewError(ErrorCodeUserInactive, "inactive user cannot create PR")
-			}
+		repoReviews, err := p.reviews.GetReviews(txCtx, prID)
+		if err != nil {
+			l.Error("failed to get reviews", zap.String("pull_request_id", prID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get reviews")
+		}
+		reviews := toModelReviews(repoReviews)
+		reviewers := reviewerIDs(reviews)
 
-			team = append(team, &model.User{
-				ID:       repoTeam[i].ID,
-				Username: repoTeam[i].Username,
-				IsActive: repoTeam[i].IsActive,
-				TeamName: repoTeam[i].TeamName,
-			})
+		// Repeated merges return the stored record unchanged, including the original merged_at
+		if repoPR.Status == model.PRStatusMerged {
+			l.Debug("PR already merged", zap.String("pull_request_id", prID))
+			return p.fillPullRequest(txCtx, pr, repoPR, reviews)
 		}
 
-		repoPR := &repository.PullRequest{
-			ID:                short.ID,
-			AuthorID:          short.AuthorID,
-			Name:              short.Name,
-			NeedMoreReviewers: false,
-			Status:            model.PRStatusOpen,
+		if repoPR.Status != model.PRStatusOpen {
+			l.Warn("cannot merge PR", zap.String("pull_request_id", prID), zap.String("status", string(repoPR.Status)))
+			return NewError(ErrorCodeInvalidTransition, fmt.Sprintf("cannot merge %s PR", repoPR.Status))
 		}
-		err = p.prs.Create(txCtx, repoPR)
-		switch {
-		case errors.Is(err, repository.ErrAlreadyExists):
-			l.Warn("PR already exists", zap.String("pull_request_id", short.ID))
-			return NewError(ErrorCodePRExists, "PR id already exists")
-		case err != nil:
-			l.Error("failed to create PR", zap.String("pull_request_id", short.ID), zap.Error(err))
-			return NewError(ErrorCodeUnspecified, "failed to create PR")
+
+		author, err := p.users.Get(txCtx, repoPR.AuthorID)
+		if err != nil {
+			l.Error("failed to get PR author", zap.String("author_id", repoPR.AuthorID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get PR author")
+		}
+
+		settings, err := loadTeamSettings(txCtx, p.teams, author.TeamName)
+		if err != nil {
+			l.Error("failed to get team settings", zap.String("team_name", author.TeamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get team settings")
+		}
+
+		before := snapshotPullRequest(repoPR, reviews)
+
+		// inactive reviewers are released before the check, so their decisions do not count
+		if !settings.AllowInactiveReviewers {
+			if reviewers, err = p.releaseInactiveReviewers(txCtx, prID, reviewers); err != nil {
+				return err
+			}
+			reviews = slices.DeleteFunc(reviews, func(r *model.Review) bool {
+				return !slices.Contains(reviewers, r.UserID)
+			})
+		}
+
+		status := model.PRStatusMerged
+		patch := &repository.PullRequestPatch{
+			ID:     prID,
+			Status: &status,
 		}
 
-		reviewers := p.selectReviewers(short.AuthorID, team, 2)
+		if reason := mergeBlocker(settings, reviews); reason != "" {
+			if !force {
+				l.Warn("PR is not approved", zap.String("pull_request_id", prID), zap.String("reason", reason))
+				return NewError(ErrorCodeNotApproved, reason)
+			}
+
+			l.Warn("PR force merged", zap.String("pull_request_id", prID), zap.String("reason", reason))
+			forceMerged := true
+			patch.ForceMerged = &forceMerged
+		}
 
-		err = p.reviews.Assign(txCtx, repoPR.ID, reviewers)
+		repoPR, err = p.prs.Patch(txCtx, patch)
 		if err != nil {
-			l.Error("failed to assign reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
-			return NewError(ErrorCodeUnspecified, "failed to assign PR")
+			l.Error("failed to patch PR", zap.String("pull_request_id", prID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to update PR")
 		}
 
-		l.Info("PR created successfully",
-			zap.String("pull_request_id", repoPR.ID),
-			zap.Strings("reviewers", reviewers))
+		merged := &repository.PullRequestEvent{
+			PullRequestID: prID,
+			Type:          model.PREventMerged,
+			FromStatus:    model.PRStatusOpen,
+			ToStatus:      model.PRStatusMerged,
+		}
+		if repoPR.ForceMerged {
+			merged.Reason = model.PREventReasonForce
+		}
+		if err = p.recordEvents(txCtx, merged); err != nil {
+			return err
+		}
+
+		l.Debug("PR merged successfully", zap.String("pull_request_id", prID))
 
-		pr.CreatedAt = repoPR.CreatedAt
-		pr.MergedAt = repoPR.MergedAt
-		pr.Name = repoPR.Name
-		pr.Status = repoPR.Status
-		pr.AuthorID = repoPR.AuthorID
-		pr.Reviewers = reviewers
-		pr.ID = repoPR.ID
+		if err = p.fillPullRequest(txCtx, pr, repoPR, reviews); err != nil {
+			return err
+		}
 
-		return nil
+		return recordAudit(txCtx, p.audits, model.AuditActionPRMerge, model.AuditEntityPullRequest, prID, before, pr)
 	})
 
 	var res *Error
@@ -277,44 +658,1221 @@ func (p *PullRequestService) CreatePullRequest(ctx context.Context, short *model
 	return pr, res
 }
 
-func (p *PullRequestService) selectReplacementReviewer(authorID string, reviewers []string, team []*model.User) string {
-	for _, member := range team {
-		if member.ID == authorID {
-			continue
-		}
+// snapshotPullRequest Copy of the PR state used as audit "before", later changes of reviews do not affect it
+func snapshotPullRequest(repoPR *repository.PullRequest, reviews []*model.Review) *model.PullRequest {
+	pr := &model.PullRequest{}
+	copyPullRequest(pr, repoPR, slices.Clone(reviews))
+	return pr
+}
 
-		if !member.IsActive {
-			continue
-		}
+// fillPullRequest Copies the PR for a response and lists as external the reviewers who are not members
+// of the author's team, no matter which call assigned them
+func (p *PullRequestService) fillPullRequest(ctx context.Context, pr *model.PullRequest, repoPR *repository.PullRequest, reviews []*model.Review) error {
+	copyPullRequest(pr, repoPR, reviews)
+	if len(reviews) == 0 {
+		return nil
+	}
 
-		if slices.Contains(reviewers, member.ID) {
-			continue
-		}
+	teams, err := p.users.GetTeamNames(ctx, append([]string{repoPR.AuthorID}, pr.Reviewers...))
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get reviewer teams", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get reviewer teams")
+	}
 
-		return member.ID
+	for _, reviewerID := range pr.Reviewers {
+		if teams[reviewerID] != teams[repoPR.AuthorID] {
+			pr.ExternalReviewers = append(pr.ExternalReviewers, reviewerID)
+		}
 	}
+	return nil
+}
 
-	return ""
+func copyPullRequest(pr *model.PullRequest, repoPR *repository.PullRequest, reviews []*model.Review) {
+	pr.ID = repoPR.ID
+	pr.CreatedAt = repoPR.CreatedAt
+	pr.MergedAt = repoPR.MergedAt
+	pr.Name = repoPR.Name
+	pr.Status = repoPR.Status
+	pr.AuthorID = repoPR.AuthorID
+	pr.Reviewers = reviewerIDs(reviews)
+	pr.Reviews = reviews
+	pr.NeedMoreReviewers = repoPR.NeedMoreReviewers
+	pr.ForceMerged = repoPR.ForceMerged
 }
 
-// selectReviewers Selects up to `max` active reviewers from team and returns their IDs
-func (p *PullRequestService) selectReviewers(author string, team []*model.User, max int) []string {
-	reviewers := make([]string, 0, max)
-	for _, member := range team {
-		if member.ID == author || !member.IsActive {
-			continue
-		}
+// recordEvents Appends the events to the PR history with the actor of ctx, it must run in the transaction of the change.
+// Nothing is recorded when the service has no event repository configured.
+func (p *PullRequestService) recordEvents(ctx context.Context, events ...*repository.PullRequestEvent) error {
+	if p.events == nil || len(events) == 0 {
+		return nil
+	}
 
-		reviewers = append(reviewers, member.ID)
+	actor := audit.ActorFromContext(ctx)
+	for _, ev := range events {
+		ev.Actor = actor
+	}
 
-		if len(reviewers) == max {
-			break
-		}
+	if err := p.events.Append(ctx, events); err != nil {
+		logger.FromContext(ctx).Error("failed to record PR history",
+			zap.String("pull_request_id", events[0].PullRequestID),
+			zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to record PR history")
 	}
-	return reviewers
+	return nil
 }
 
-func (p *PullRequestService) WithUserRepo(r repository.UserRepository) *PullRequestService {
+// reviewerEvents One event of eventType per reviewer
+func reviewerEvents(prID string, eventType model.PullRequestEventType, reviewers []string, reason model.PullRequestEventReason) []*repository.PullRequestEvent {
+	events := make([]*repository.PullRequestEvent, 0, len(reviewers))
+	for _, reviewerID := range reviewers {
+		events = append(events, &repository.PullRequestEvent{
+			PullRequestID: prID,
+			Type:          eventType,
+			ReviewerID:    reviewerID,
+			Reason:        reason,
+		})
+	}
+	return events
+}
+
+// mergeBlocker Explains why the reviews do not allow a merge yet, empty when the PR may be merged
+func mergeBlocker(settings *model.TeamSettings, reviews []*model.Review) string {
+	approved := 0
+	for _, r := range reviews {
+		switch r.State {
+		case model.ReviewStateChangesRequested:
+			return fmt.Sprintf("reviewer %s requested changes", r.UserID)
+		case model.ReviewStateApproved:
+			approved++
+		}
+	}
+
+	if approved < settings.RequiredApprovals {
+		return fmt.Sprintf("PR has %d of %d required approvals", approved, settings.RequiredApprovals)
+	}
+	return ""
+}
+
+// CreatePullRequest Create a new pull request and assign up to max_reviewers team members as reviewers.
+// When the team cannot supply min_reviewers the rest is drawn from its fallback teams,
+// and if that is still not enough the PR is flagged with need_more_reviewers.
+// A PR created with status DRAFT gets no reviewers until it is marked ready.
+func (p *PullRequestService) CreatePullRequest(ctx context.Context, short *model.PullRequestShort) (*model.PullRequest, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("creating pull request",
+		zap.String("pull_request_id", short.ID),
+		zap.String("pr_name", short.Name),
+		zap.String("author_id", short.AuthorID))
+
+	pr := &model.PullRequest{}
+
+	err := p.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		repoTeam, err := p.users.GetUserTeam(txCtx, short.AuthorID)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			l.Warn("author not found", zap.String("author_id", short.AuthorID))
+			return NewError(ErrorCodeNotFound, "author or PR not found")
+		case err != nil:
+			l.Error("failed to get author team", zap.String("author_id", short.AuthorID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get author team")
+		}
+
+		if slices.ContainsFunc(repoTeam, func(u *repository.User) bool { return u.ID == short.AuthorID && !u.IsActive }) {
+			l.Warn("inactive user cannot create PR", zap.String("author_id", short.AuthorID))
+			return NewError(ErrorCodeUserInactive, "inactive user cannot create PR")
+		}
+
+		team := toModelUsers(repoTeam)
+		teamName := repoTeam[0].TeamName
+
+		settings, err := loadTeamSettings(txCtx, p.teams, teamName)
+		if err != nil {
+			l.Error("failed to get team settings", zap.String("team_name", teamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get team settings")
+		}
+
+		status := model.PRStatusOpen
+		reviewers := []string{}
+		var external []string
+		if short.Status == model.PRStatusDraft {
+			status = model.PRStatusDraft
+		} else {
+			rules, err := loadReviewerRules(txCtx, p.teams, teamName)
+			if err != nil {
+				l.Error("failed to get reviewer rules", zap.String("team_name", teamName), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to get reviewer rules")
+			}
+			selectCtx, prRules := withReviewerRules(txCtx, rules, short.AuthorID, nil)
+
+			reviewers, err = p.pickReviewers(selectCtx, settings, short.AuthorID, nil, team, settings.MaxReviewers)
+			if err != nil {
+				l.Error("failed to select reviewers", zap.String("pull_request_id", short.ID), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to select reviewers")
+			}
+
+			external, err = p.pickFallbackReviewers(selectCtx, settings, append([]string{short.AuthorID}, reviewers...), settings.MinReviewers-len(reviewers))
+			if err != nil {
+				l.Error("failed to select fallback reviewers", zap.String("pull_request_id", short.ID), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to select reviewers")
+			}
+			reviewers = append(reviewers, external...)
+
+			if reason := prRules.explain(); len(reviewers) == 0 && reason != "" {
+				l.Warn("reviewer rules eliminated all candidates", zap.String("pull_request_id", short.ID), zap.String("reason", reason))
+				return NewError(ErrorCodeNoCandidate, "no reviewer satisfies the team rules: "+reason)
+			}
+		}
+
+		repoPR := &repository.PullRequest{
+			ID:                short.ID,
+			AuthorID:          short.AuthorID,
+			Name:              short.Name,
+			NeedMoreReviewers: status == model.PRStatusOpen && len(reviewers) < settings.MinReviewers,
+			Status:            status,
+		}
+		err = p.prs.Create(txCtx, repoPR)
+		switch {
+		case errors.Is(err, repository.ErrAlreadyExists):
+			l.Warn("PR already exists", zap.String("pull_request_id", short.ID))
+			return NewError(ErrorCodePRExists, "PR id already exists")
+		case err != nil:
+			l.Error("failed to create PR", zap.String("pull_request_id", short.ID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to create PR")
+		}
+
+		if len(reviewers) > 0 {
+			if err = p.reviews.Assign(txCtx, repoPR.ID, reviewers); err != nil {
+				l.Error("failed to assign reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to assign PR")
+			}
+		}
+
+		created := &repository.PullRequestEvent{PullRequestID: repoPR.ID, Type: model.PREventCreated, ToStatus: status}
+		events := append([]*repository.PullRequestEvent{created}, reviewerEvents(repoPR.ID, model.PREventReviewerAssigned, reviewers, model.PREventReasonAuto)...)
+		if err = p.recordEvents(txCtx, events...); err != nil {
+			return err
+		}
+
+		if repoPR.NeedMoreReviewers {
+			l.Warn("not enough reviewers in team, PR flagged",
+				zap.String("pull_request_id", repoPR.ID),
+				zap.Int("assigned", len(reviewers)))
+		}
+
+		l.Info("PR created successfully",
+			zap.String("pull_request_id", repoPR.ID),
+			zap.Strings("reviewers", reviewers))
+
+		if err = p.fillPullRequest(txCtx, pr, repoPR, pendingReviews(reviewers)); err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, p.audits, model.AuditActionPRCreate, model.AuditEntityPullRequest, pr.ID, nil, pr)
+	})
+
+	var res *Error
+	errors.As(err, &res)
+
+	return pr, res
+}
+
+// prTransition Describes a lifecycle change: the statuses a PR may leave and the status it enters.
+// Merging is handled separately by MergePullRequest because of the approval check.
+type prTransition struct {
+	name   string
+	action model.AuditAction
+	from   []model.PRStatus
+	to     model.PRStatus
+}
+
+var (
+	transitionMarkReady = prTransition{name: "mark ready", action: model.AuditActionPRMarkReady, from: []model.PRStatus{model.PRStatusDraft}, to: model.PRStatusOpen}
+	transitionClose     = prTransition{name: "close", action: model.AuditActionPRClose, from: []model.PRStatus{model.PRStatusDraft, model.PRStatusOpen}, to: model.PRStatusClosed}
+	transitionReopen    = prTransition{name: "reopen", action: model.AuditActionPRReopen, from: []model.PRStatus{model.PRStatusClosed}, to: model.PRStatusOpen}
+)
+
+// MarkReady Moves a DRAFT PR to OPEN and assigns its reviewers
+func (p *PullRequestService) MarkReady(ctx context.Context, prID string) (*model.PullRequest, *Error) {
+	return p.transition(ctx, prID, transitionMarkReady)
+}
+
+// ClosePullRequest Abandons a DRAFT or OPEN PR, its reviewers are released
+func (p *PullRequestService) ClosePullRequest(ctx context.Context, prID string) (*model.PullRequest, *Error) {
+	return p.transition(ctx, prID, transitionClose)
+}
+
+// ReopenPullRequest Moves a CLOSED PR back to OPEN and assigns reviewers again
+func (p *PullRequestService) ReopenPullRequest(ctx context.Context, prID string) (*model.PullRequest, *Error) {
+	return p.transition(ctx, prID, transitionReopen)
+}
+
+// transition Applies the lifecycle change under the PR row lock, illegal changes fail with INVALID_TRANSITION.
+// Entering OPEN assigns reviewers like CreatePullRequest does, entering CLOSED unassigns everybody.
+func (p *PullRequestService) transition(ctx context.Context, prID string, t prTransition) (*model.PullRequest, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("changing pull request status",
+		zap.String("pull_request_id", prID),
+		zap.String("transition", t.name))
+
+	pr := &model.PullRequest{}
+
+	err := p.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		repoPR, err := p.prs.GetForUpdate(txCtx, prID)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			l.Warn("PR not found", zap.String("pull_request_id", prID))
+			return NewError(ErrorCodeNotFound, "PR not found")
+		case err != nil:
+			l.Error("failed to get PR", zap.String("pull_request_id", prID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get PR")
+		}
+
+		if !slices.Contains(t.from, repoPR.Status) {
+			l.Warn("illegal status transition",
+				zap.String("pull_request_id", prID),
+				zap.String("status", string(repoPR.Status)),
+				zap.String("transition", t.name))
+			return NewError(ErrorCodeInvalidTransition, fmt.Sprintf("cannot %s %s PR", t.name, repoPR.Status))
+		}
+
+		repoReviews, err := p.reviews.GetReviews(txCtx, prID)
+		if err != nil {
+			l.Error("failed to get reviews", zap.String("pull_request_id", prID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get reviews")
+		}
+		reviews := toModelReviews(repoReviews)
+		before := snapshotPullRequest(repoPR, reviews)
+
+		status := t.to
+		needMore := false
+		patch := &repository.PullRequestPatch{
+			ID:                prID,
+			Status:            &status,
+			NeedMoreReviewers: &needMore,
+		}
+
+		events := []*repository.PullRequestEvent{{
+			PullRequestID: prID,
+			Type:          model.PREventStatusChanged,
+			FromStatus:    repoPR.Status,
+			ToStatus:      t.to,
+		}}
+
+		switch t.to {
+		case model.PRStatusOpen:
+			added, enough, err := p.assignInitialReviewers(txCtx, repoPR, reviewerIDs(reviews))
+			if err != nil {
+				return err
+			}
+			reviews = append(reviews, pendingReviews(added)...)
+			needMore = !enough
+			events = append(events, reviewerEvents(prID, model.PREventReviewerAssigned, added, model.PREventReasonAuto)...)
+		case model.PRStatusClosed:
+			if len(reviews) > 0 {
+				if err = p.reviews.UnassignMany(txCtx, []string{prID}, reviewerIDs(reviews)); err != nil {
+					l.Error("failed to unassign reviewers", zap.String("pull_request_id", prID), zap.Error(err))
+					return NewError(ErrorCodeUnspecified, "failed to unassign reviewers")
+				}
+			}
+			events = append(events, reviewerEvents(prID, model.PREventReviewerUnassigned, reviewerIDs(reviews), model.PREventReasonAuto)...)
+			reviews = []*model.Review{}
+		}
+
+		if err = p.recordEvents(txCtx, events...); err != nil {
+			return err
+		}
+
+		repoPR, err = p.prs.Patch(txCtx, patch)
+		if err != nil {
+			l.Error("failed to patch PR", zap.String("pull_request_id", prID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to update PR")
+		}
+
+		l.Debug("PR status changed",
+			zap.String("pull_request_id", prID),
+			zap.String("status", string(repoPR.Status)))
+
+		if err = p.fillPullRequest(txCtx, pr, repoPR, reviews); err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, p.audits, t.action, model.AuditEntityPullRequest, prID, before, pr)
+	})
+
+	var res *Error
+	errors.As(err, &res)
+
+	if res != nil {
+		return nil, res
+	}
+
+	return pr, nil
+}
+
+// assignInitialReviewers Tops the PR up to max_reviewers from the author's team, falling back to partner teams
+// while min_reviewers is not reached. Returns the added reviewers and whether min_reviewers is reached.
+// Fails with NO_CANDIDATE when reviewer rules leave the PR without reviewers.
+func (p *PullRequestService) assignInitialReviewers(ctx context.Context, repoPR *repository.PullRequest, reviewers []string) ([]string, bool, error) {
+	l := logger.FromContext(ctx)
+
+	repoTeam, err := p.users.GetUserTeam(ctx, repoPR.AuthorID)
+	if err != nil {
+		l.Error("failed to get author team", zap.String("author_id", repoPR.AuthorID), zap.Error(err))
+		return nil, false, NewError(ErrorCodeUnspecified, "failed to get author team")
+	}
+	team := toModelUsers(repoTeam)
+	teamName := repoTeam[0].TeamName
+
+	settings, err := loadTeamSettings(ctx, p.teams, teamName)
+	if err != nil {
+		l.Error("failed to get team settings", zap.String("team_name", teamName), zap.Error(err))
+		return nil, false, NewError(ErrorCodeUnspecified, "failed to get team settings")
+	}
+
+	rules, err := loadReviewerRules(ctx, p.teams, teamName)
+	if err != nil {
+		l.Error("failed to get reviewer rules", zap.String("team_name", teamName), zap.Error(err))
+		return nil, false, NewError(ErrorCodeUnspecified, "failed to get reviewer rules")
+	}
+
+	added, reason, err := p.chooseReviewers(ctx, repoPR, reviewers, team, settings, rules)
+	if err != nil {
+		l.Error("failed to select reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+		return nil, false, NewError(ErrorCodeUnspecified, "failed to select reviewers")
+	}
+
+	if len(reviewers)+len(added) == 0 && reason != "" {
+		l.Warn("reviewer rules eliminated all candidates", zap.String("pull_request_id", repoPR.ID), zap.String("reason", reason))
+		return nil, false, NewError(ErrorCodeNoCandidate, "no reviewer satisfies the team rules: "+reason)
+	}
+
+	if len(added) > 0 {
+		if err = p.reviews.Assign(ctx, repoPR.ID, added); err != nil {
+			l.Error("failed to assign reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+			return nil, false, NewError(ErrorCodeUnspecified, "failed to assign reviewers")
+		}
+	}
+
+	enough := len(reviewers)+len(added) >= settings.MinReviewers
+	if !enough {
+		l.Warn("not enough reviewers in team, PR flagged",
+			zap.String("pull_request_id", repoPR.ID),
+			zap.Int("assigned", len(reviewers)+len(added)))
+	}
+
+	return added, enough, nil
+}
+
+// chooseReviewers Picks reviewers to top the PR up to max_reviewers from the author's team, falling back to partner
+// teams while min_reviewers is not reached. Also returns which candidates reviewer rules eliminated.
+func (p *PullRequestService) chooseReviewers(ctx context.Context, repoPR *repository.PullRequest, reviewers []string, team []*model.User, settings *model.TeamSettings, rules *reviewerRules) ([]string, string, error) {
+	selectCtx, prRules := withReviewerRules(ctx, rules, repoPR.AuthorID, reviewers)
+
+	added, err := p.pickReviewers(selectCtx, settings, repoPR.AuthorID, reviewers, team, settings.MaxReviewers-len(reviewers))
+	if err != nil {
+		return nil, "", err
+	}
+
+	external, err := p.pickFallbackReviewers(selectCtx, settings, append(append([]string{repoPR.AuthorID}, reviewers...), added...), settings.MinReviewers-len(reviewers)-len(added))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return append(added, external...), prRules.explain(), nil
+}
+
+// SubmitReview Records the decision of an assigned reviewer, PENDING withdraws a previous decision
+func (p *PullRequestService) SubmitReview(ctx context.Context, prID, userID string, state model.ReviewState) (*model.PullRequest, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("submitting review",
+		zap.String("pull_request_id", prID),
+		zap.String("user_id", userID),
+		zap.String("state", string(state)))
+
+	switch state {
+	case model.ReviewStatePending, model.ReviewStateApproved, model.ReviewStateChangesRequested:
+	default:
+		return nil, NewError(ErrorCodeInvalidBody, "unknown review state")
+	}
+
+	pr := &model.PullRequest{}
+
+	err := p.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		repoPR, err := p.prs.Get(txCtx, prID)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			l.Warn("PR not found", zap.String("pull_request_id", prID))
+			return NewError(ErrorCodeNotFound, "PR not found")
+		case err != nil:
+			l.Error("failed to get PR", zap.String("pull_request_id", prID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get PR")
+		}
+
+		if repoPR.Status == model.PRStatusMerged {
+			l.Warn("cannot review merged PR", zap.String("pull_request_id", prID))
+			return NewError(ErrorCodePRMerged, "cannot review merged PR")
+		}
+
+		repoReviews, err := p.reviews.GetReviews(txCtx, prID)
+		if err != nil {
+			l.Error("failed to get reviews", zap.String("pull_request_id", prID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get reviews")
+		}
+		reviews := toModelReviews(repoReviews)
+		before := snapshotPullRequest(repoPR, reviews)
+
+		err = p.reviews.SetState(txCtx, prID, userID, state)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			l.Warn("reviewer not assigned to PR", zap.String("pull_request_id", prID), zap.String("user_id", userID))
+			return NewError(ErrorCodeNotAssigned, "reviewer is not assigned to this PR")
+		case err != nil:
+			l.Error("failed to set review state", zap.String("pull_request_id", prID), zap.String("user_id", userID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to save review")
+		}
+
+		if idx := slices.IndexFunc(reviews, func(r *model.Review) bool { return r.UserID == userID }); idx >= 0 {
+			reviews[idx] = &model.Review{UserID: userID, State: state}
+		}
+
+		if err = p.recordEvents(txCtx, &repository.PullRequestEvent{
+			PullRequestID: prID,
+			Type:          model.PREventReviewSubmitted,
+			ReviewerID:    userID,
+			ReviewState:   state,
+		}); err != nil {
+			return err
+		}
+
+		l.Debug("review submitted successfully", zap.String("pull_request_id", prID), zap.String("user_id", userID))
+
+		if err = p.fillPullRequest(txCtx, pr, repoPR, reviews); err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, p.audits, model.AuditActionPRReview, model.AuditEntityPullRequest, prID, before, pr)
+	})
+
+	var res *Error
+	errors.As(err, &res)
+
+	if res != nil {
+		return nil, res
+	}
+
+	return pr, nil
+}
+
+// TopUpReviewers Assigns missing reviewers to the team's OPEN pull requests flagged with need_more_reviewers,
+// then does the same for the teams listing it among their fallback_teams. It is triggered when a team gets
+// new active members, the flag is cleared once a PR has enough reviewers.
+func (p *PullRequestService) TopUpReviewers(ctx context.Context, teamName string) *Error {
+	l := logger.FromContext(ctx)
+	l.Info("topping up reviewers", zap.String("team_name", teamName))
+
+	var dependents []string
+	err := p.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		if dependents, err = p.teams.GetTeamsFallingBackTo(txCtx, teamName); err != nil {
+			l.Error("failed to get teams falling back to team", zap.String("team_name", teamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get dependent teams")
+		}
+		return p.topUpReviewers(txCtx, teamName)
+	})
+
+	// every team gets its own transaction, so that only one team is locked at a time
+	for _, dependent := range dependents {
+		if err != nil {
+			break
+		}
+		l.Info("topping up reviewers of dependent team", zap.String("team_name", dependent), zap.String("fallback_team", teamName))
+		err = p.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+			return p.topUpReviewers(txCtx, dependent)
+		})
+	}
+
+	var res *Error
+	errors.As(err, &res)
+
+	return res
+}
+
+func (p *PullRequestService) topUpReviewers(ctx context.Context, teamName string) error {
+	l := logger.FromContext(ctx)
+
+	flagged, err := p.prs.GetNeedMoreReviewers(ctx, teamName)
+	if err != nil {
+		l.Error("failed to get PRs needing reviewers", zap.String("team_name", teamName), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get PRs needing reviewers")
+	}
+	if len(flagged) == 0 {
+		return nil
+	}
+
+	repoTeam, err := p.teams.GetTeamMembers(ctx, teamName)
+	if err != nil {
+		l.Error("failed to get team members", zap.String("team_name", teamName), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get team members")
+	}
+	team := toModelUsers(repoTeam)
+
+	settings, err := loadTeamSettings(ctx, p.teams, teamName)
+	if err != nil {
+		l.Error("failed to get team settings", zap.String("team_name", teamName), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get team settings")
+	}
+
+	rules, err := loadReviewerRules(ctx, p.teams, teamName)
+	if err != nil {
+		l.Error("failed to get reviewer rules", zap.String("team_name", teamName), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get reviewer rules")
+	}
+
+	for _, repoPR := range flagged {
+		reviewers, err := p.prs.GetReviewers(ctx, repoPR.ID)
+		if err != nil {
+			l.Error("failed to get reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get reviewers")
+		}
+
+		added, reason, err := p.chooseReviewers(ctx, repoPR, reviewers, team, settings, rules)
+		if err != nil {
+			l.Error("failed to select reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to select reviewers")
+		}
+		if len(reviewers)+len(added) < settings.MinReviewers && reason != "" {
+			l.Warn("reviewer rules eliminated candidates", zap.String("pull_request_id", repoPR.ID), zap.String("reason", reason))
+		}
+		if len(added) > 0 {
+			if err = p.reviews.Assign(ctx, repoPR.ID, added); err != nil {
+				l.Error("failed to assign reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to assign reviewers")
+			}
+
+			if err = p.recordEvents(ctx, reviewerEvents(repoPR.ID, model.PREventReviewerAssigned, added, model.PREventReasonTopUp)...); err != nil {
+				return err
+			}
+		}
+
+		// the flag is also cleared when the PR got enough reviewers in the meantime, e.g. after min_reviewers was lowered
+		if len(reviewers)+len(added) >= settings.MinReviewers {
+			needMore := false
+			if _, err = p.prs.Patch(ctx, &repository.PullRequestPatch{
+				ID:                repoPR.ID,
+				NeedMoreReviewers: &needMore,
+			}); err != nil {
+				l.Error("failed to clear need_more_reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to update PR")
+			}
+		}
+
+		l.Info("reviewers topped up",
+			zap.String("pull_request_id", repoPR.ID),
+			zap.Strings("added", added))
+	}
+
+	return nil
+}
+
+// releaseReviewers Moves every OPEN review of the users to other active teammates using batched queries.
+// As in ReassignPullRequest, settings and rules of the author's team apply and its fallback teams are tried
+// when no teammate is left. The users are unassigned even when no replacement exists, PRs left with fewer than
+// min_reviewers are flagged with need_more_reviewers. Users must be deactivated beforehand, otherwise they could be
+// picked to replace each other.
+func (p *PullRequestService) releaseReviewers(ctx context.Context, teamName string, userIDs []string) ([]*model.ReviewReassignment, error) {
+	l := logger.FromContext(ctx)
+
+	repoPRs, err := p.prs.GetOpenReviewPRs(ctx, userIDs)
+	if err != nil {
+		l.Error("failed to get open review PRs", zap.Strings("user_ids", userIDs), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get user reviews")
+	}
+
+	res := make([]*model.ReviewReassignment, 0, len(repoPRs))
+	if len(repoPRs) == 0 {
+		return res, nil
+	}
+
+	prIDs := make([]string, 0, len(repoPRs))
+	authorIDs := make([]string, 0, len(repoPRs))
+	for _, repoPR := range repoPRs {
+		prIDs = append(prIDs, repoPR.ID)
+		if !slices.Contains(authorIDs, repoPR.AuthorID) {
+			authorIDs = append(authorIDs, repoPR.AuthorID)
+		}
+	}
+
+	reviewersOf, err := p.prs.GetReviewersOf(ctx, prIDs)
+	if err != nil {
+		l.Error("failed to get reviewers", zap.Strings("pull_request_ids", prIDs), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get reviewers")
+	}
+
+	authorTeams, err := p.users.GetTeamNames(ctx, authorIDs)
+	if err != nil {
+		l.Error("failed to get author teams", zap.Strings("author_ids", authorIDs), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get PR authors")
+	}
+
+	repoTeam, err := p.teams.GetTeamMembers(ctx, teamName)
+	if err != nil {
+		l.Error("failed to get team members", zap.String("team_name", teamName), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get team members")
+	}
+	team := toModelUsers(repoTeam)
+
+	// Counting once also locks the team, the counts are then updated locally
+	// so that least loaded selection spreads the whole batch across the team
+	load, err := p.reviews.CountTeamOpenReviews(ctx, teamName)
+	if err != nil {
+		l.Error("failed to count open reviews", zap.String("team_name", teamName), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to count open reviews")
+	}
+	selectCtx := withReviewLoad(ctx, teamName, load)
+
+	// PRs are grouped by the author's team, whose settings and rules apply to all of them
+	byTeam := make(map[string][]*repository.PullRequest)
+	authorTeamNames := make([]string, 0)
+	for _, repoPR := range repoPRs {
+		authorTeam := authorTeams[repoPR.AuthorID]
+		if _, ok := byTeam[authorTeam]; !ok {
+			authorTeamNames = append(authorTeamNames, authorTeam)
+		}
+		byTeam[authorTeam] = append(byTeam[authorTeam], repoPR)
+	}
+
+	released := make(map[string]struct{}, len(userIDs))
+	for _, userID := range userIDs {
+		released[userID] = struct{}{}
+	}
+
+	assigned := make([]*repository.Review, 0, len(repoPRs))
+	flagged := make([]string, 0)
+
+	for _, authorTeam := range authorTeamNames {
+		settings, err := loadTeamSettings(ctx, p.teams, authorTeam)
+		if err != nil {
+			l.Error("failed to get team settings", zap.String("team_name", authorTeam), zap.Error(err))
+			return nil, NewError(ErrorCodeUnspecified, "failed to get team settings")
+		}
+
+		rules, err := loadReviewerRules(ctx, p.teams, authorTeam)
+		if err != nil {
+			l.Error("failed to get reviewer rules", zap.String("team_name", authorTeam), zap.Error(err))
+			return nil, NewError(ErrorCodeUnspecified, "failed to get reviewer rules")
+		}
+
+		// fallback teams are read once as well, so the batch keeps counting its own picks
+		for _, fallbackTeam := range settings.FallbackTeams {
+			if _, ok := reviewLoadFromContext(selectCtx, fallbackTeam); ok {
+				continue
+			}
+			fallbackLoad, err := p.reviews.PeekTeamOpenReviews(ctx, fallbackTeam)
+			if err != nil {
+				l.Error("failed to count open reviews", zap.String("team_name", fallbackTeam), zap.Error(err))
+				return nil, NewError(ErrorCodeUnspecified, "failed to count open reviews")
+			}
+			selectCtx = withReviewLoad(selectCtx, fallbackTeam, fallbackLoad)
+		}
+
+		for _, repoPR := range byTeam[authorTeam] {
+			reviewers := reviewersOf[repoPR.ID]
+			exclude := append([]string{repoPR.AuthorID}, reviewers...)
+
+			kept := slices.DeleteFunc(slices.Clone(reviewers), func(id string) bool {
+				_, ok := released[id]
+				return ok
+			})
+			prCtx, prRules := withReviewerRules(selectCtx, rules, repoPR.AuthorID, kept)
+
+			for _, reviewerID := range reviewers {
+				if _, ok := released[reviewerID]; !ok {
+					continue
+				}
+
+				reassignment := &model.ReviewReassignment{
+					PullRequestID: repoPR.ID,
+					OldReviewerID: reviewerID,
+				}
+				res = append(res, reassignment)
+
+				// candidates come from the released reviewer's team first, then from the author's fallback teams
+				replacement, err := p.selectReplacement(prCtx, settings, teamName, exclude, kept, team)
+				if err == nil && len(replacement) > 0 {
+					load[replacement[0]]++
+				}
+				if err == nil && len(replacement) == 0 {
+					replacement, err = p.pickFallbackReviewers(prCtx, settings, exclude, 1)
+				}
+				if err != nil {
+					l.Error("failed to select replacement reviewer", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+					return nil, NewError(ErrorCodeUnspecified, "failed to select replacement reviewer")
+				}
+
+				if len(replacement) == 0 {
+					l.Warn("no replacement candidate",
+						zap.String("pull_request_id", repoPR.ID),
+						zap.String("user_id", reviewerID),
+						zap.String("reason", prRules.explain()))
+					reassignment.Status = model.ReassignmentStatusNoCandidate
+					continue
+				}
+
+				newReviewer := replacement[0]
+				exclude = append(exclude, newReviewer)
+				kept = append(kept, newReviewer)
+				assigned = append(assigned, &repository.Review{UserID: newReviewer, PullRequestID: repoPR.ID})
+
+				reassignment.NewReviewerID = newReviewer
+				reassignment.Status = model.ReassignmentStatusReassigned
+			}
+
+			if len(kept) < settings.MinReviewers {
+				flagged = append(flagged, repoPR.ID)
+			}
+		}
+	}
+
+	if err = p.reviews.UnassignMany(ctx, prIDs, userIDs); err != nil {
+		l.Error("failed to unassign reviewers", zap.Strings("pull_request_ids", prIDs), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to unassign reviewers")
+	}
+
+	if err = p.reviews.AssignMany(ctx, assigned); err != nil {
+		l.Error("failed to assign new reviewers", zap.Int("count", len(assigned)), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to assign new reviewers")
+	}
+
+	if err = p.prs.SetNeedMoreReviewers(ctx, flagged, true); err != nil {
+		l.Error("failed to flag PRs", zap.Strings("pull_request_ids", flagged), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to update PRs")
+	}
+
+	recorded := make([]*repository.Reassignment, 0, len(res))
+	events := make([]*repository.PullRequestEvent, 0, len(res))
+	for _, r := range res {
+		var newReviewer *string
+		event := &repository.PullRequestEvent{
+			PullRequestID: r.PullRequestID,
+			Type:          model.PREventReviewerUnassigned,
+			ReviewerID:    r.OldReviewerID,
+			Reason:        model.PREventReasonDeactivation,
+		}
+		if r.NewReviewerID != "" {
+			newReviewer = &r.NewReviewerID
+			event.Type = model.PREventReviewerReassigned
+			event.NewReviewerID = r.NewReviewerID
+		}
+		recorded = append(recorded, &repository.Reassignment{
+			PullRequestID: r.PullRequestID,
+			OldReviewerID: r.OldReviewerID,
+			NewReviewerID: newReviewer,
+		})
+		events = append(events, event)
+	}
+	if err = p.reviews.RecordReassignments(ctx, recorded); err != nil {
+		l.Error("failed to record reassignments", zap.Int("count", len(recorded)), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to record reassignments")
+	}
+	if err = p.recordEvents(ctx, events...); err != nil {
+		return nil, err
+	}
+
+	l.Info("open reviews released",
+		zap.Strings("user_ids", userIDs),
+		zap.Int("reassigned", len(assigned)),
+		zap.Int("flagged", len(flagged)))
+
+	return res, nil
+}
+
+// pickReviewers Selects up to `count` more reviewers for the PR of authorID that already has `reviewers`,
+// honoring team settings: a mandatory lead takes the first slot unless a reviewer rule excludes them
+// or they are at capacity, and with require_senior the next slot goes to a SENIOR or LEAD member while the PR has none
+func (p *PullRequestService) pickReviewers(ctx context.Context, settings *model.TeamSettings, authorID string, reviewers []string, team []*model.User, count int) ([]string, error) {
+	if count <= 0 {
+		return []string{}, nil
+	}
+
+	exclude := append([]string{authorID}, reviewers...)
+	picked := make([]string, 0, count)
+	if settings.LeadMandatory && !slices.Contains(exclude, settings.LeadID) && isActiveMember(team, settings.LeadID) {
+		lead, err := p.withinCapacity(ctx, settings.TeamName, slices.DeleteFunc(slices.Clone(team), func(u *model.User) bool {
+			return u.ID != settings.LeadID
+		}))
+		if err != nil {
+			return nil, err
+		}
+		rules, ok := reviewerRulesFromContext(ctx)
+		if len(lead) > 0 && (!ok || rules.allowed(settings.LeadID)) {
+			picked = append(picked, settings.LeadID)
+			if ok {
+				rules.assigned = append(rules.assigned, settings.LeadID)
+			}
+		}
+		exclude = append(exclude, settings.LeadID)
+	}
+
+	if settings.RequireSenior && len(picked) < count && !hasSenior(team, append(slices.Clone(reviewers), picked...)) {
+		senior, err := p.selectReviewers(ctx, settings.TeamName, exclude, seniorMembers(team), 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(senior) == 0 {
+			logger.FromContext(ctx).Warn("no senior reviewer available", zap.String("team_name", settings.TeamName))
+		}
+		picked = append(picked, senior...)
+		exclude = append(exclude, senior...)
+	}
+
+	others, err := p.selectReviewers(ctx, settings.TeamName, exclude, team, count-len(picked))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(picked, others...), nil
+}
+
+// selectReplacement Picks one reviewer from team to take over a review. With require_senior a SENIOR or LEAD member
+// is preferred when none of the remaining reviewers is senior.
+func (p *PullRequestService) selectReplacement(ctx context.Context, settings *model.TeamSettings, teamName string, exclude, remaining []string, team []*model.User) ([]string, error) {
+	if settings.RequireSenior && !hasSenior(team, remaining) {
+		senior, err := p.selectReviewers(ctx, teamName, exclude, seniorMembers(team), 1)
+		if err != nil || len(senior) > 0 {
+			return senior, err
+		}
+	}
+
+	return p.selectReviewers(ctx, teamName, exclude, team, 1)
+}
+
+// pickFallbackReviewers Draws up to `count` reviewers from the fallback teams in their configured order.
+// Fallback teams are not locked, so their caps of OPEN reviews are best effort under concurrent assignments.
+// Counts attached with withReviewLoad are used when present and updated with the picks.
+func (p *PullRequestService) pickFallbackReviewers(ctx context.Context, settings *model.TeamSettings, exclude []string, count int) ([]string, error) {
+	picked := make([]string, 0)
+	for _, teamName := range settings.FallbackTeams {
+		if len(picked) >= count {
+			break
+		}
+
+		repoTeam, err := p.teams.GetTeamMembers(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+
+		// The caller may already hold the lock of its own team, locking a fallback team as well could
+		// deadlock with a transaction of that team falling back the other way, so its load is only read
+		load, ok := reviewLoadFromContext(ctx, teamName)
+		if !ok {
+			if load, err = p.reviews.PeekTeamOpenReviews(ctx, teamName); err != nil {
+				return nil, err
+			}
+		}
+		teamCtx := withReviewLoad(ctx, teamName, load)
+
+		others, err := p.selectReviewers(teamCtx, teamName, append(slices.Clone(exclude), picked...), toModelUsers(repoTeam), count-len(picked))
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range others {
+			load[id]++
+		}
+		picked = append(picked, others...)
+	}
+
+	if len(picked) > 0 {
+		logger.FromContext(ctx).Info("reviewers drawn from fallback teams",
+			zap.String("team_name", settings.TeamName),
+			zap.Strings("reviewers", picked))
+	}
+
+	return picked, nil
+}
+
+// unassignDeactivated Applies allow_inactive_reviewers to the OPEN reviews of a user deactivated without reassignment:
+// the user is unassigned from PRs whose author's team forbids inactive reviewers, PRs left with fewer than
+// min_reviewers are flagged with need_more_reviewers.
+func (p *PullRequestService) unassignDeactivated(ctx context.Context, userID string) error {
+	l := logger.FromContext(ctx)
+
+	repoPRs, err := p.prs.GetOpenReviewPRs(ctx, []string{userID})
+	if err != nil {
+		l.Error("failed to get open review PRs", zap.String("user_id", userID), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get user reviews")
+	}
+	if len(repoPRs) == 0 {
+		return nil
+	}
+
+	authorIDs := make([]string, 0, len(repoPRs))
+	for _, repoPR := range repoPRs {
+		if !slices.Contains(authorIDs, repoPR.AuthorID) {
+			authorIDs = append(authorIDs, repoPR.AuthorID)
+		}
+	}
+
+	authorTeams, err := p.users.GetTeamNames(ctx, authorIDs)
+	if err != nil {
+		l.Error("failed to get author teams", zap.Strings("author_ids", authorIDs), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get PR authors")
+	}
+
+	settingsOf := make(map[string]*model.TeamSettings)
+	prIDs := make([]string, 0, len(repoPRs))
+	for _, repoPR := range repoPRs {
+		teamName := authorTeams[repoPR.AuthorID]
+		if _, ok := settingsOf[teamName]; !ok {
+			settings, err := loadTeamSettings(ctx, p.teams, teamName)
+			if err != nil {
+				l.Error("failed to get team settings", zap.String("team_name", teamName), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to get team settings")
+			}
+			settingsOf[teamName] = settings
+		}
+		if !settingsOf[teamName].AllowInactiveReviewers {
+			prIDs = append(prIDs, repoPR.ID)
+		}
+	}
+	if len(prIDs) == 0 {
+		return nil
+	}
+
+	reviewersOf, err := p.prs.GetReviewersOf(ctx, prIDs)
+	if err != nil {
+		l.Error("failed to get reviewers", zap.Strings("pull_request_ids", prIDs), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get reviewers")
+	}
+
+	flagged := make([]string, 0)
+	events := make([]*repository.PullRequestEvent, 0, len(prIDs))
+	for _, repoPR := range repoPRs {
+		if !slices.Contains(prIDs, repoPR.ID) {
+			continue
+		}
+		if len(reviewersOf[repoPR.ID])-1 < settingsOf[authorTeams[repoPR.AuthorID]].MinReviewers {
+			flagged = append(flagged, repoPR.ID)
+		}
+		events = append(events, reviewerEvents(repoPR.ID, model.PREventReviewerUnassigned, []string{userID}, model.PREventReasonInactive)...)
+	}
+
+	if err = p.reviews.UnassignMany(ctx, prIDs, []string{userID}); err != nil {
+		l.Error("failed to unassign inactive reviewer", zap.String("user_id", userID), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to unassign inactive reviewer")
+	}
+
+	if err = p.prs.SetNeedMoreReviewers(ctx, flagged, true); err != nil {
+		l.Error("failed to flag PRs", zap.Strings("pull_request_ids", flagged), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to update PRs")
+	}
+
+	if err = p.recordEvents(ctx, events...); err != nil {
+		return err
+	}
+
+	l.Info("inactive reviewer released",
+		zap.String("user_id", userID),
+		zap.Strings("pull_request_ids", prIDs),
+		zap.Int("flagged", len(flagged)))
+
+	return nil
+}
+
+// releaseInactiveReviewers Unassigns deactivated users from the PR and returns the remaining reviewers
+func (p *PullRequestService) releaseInactiveReviewers(ctx context.Context, prID string, reviewers []string) ([]string, error) {
+	l := logger.FromContext(ctx)
+
+	remaining := make([]string, 0, len(reviewers))
+	released := make([]string, 0)
+	for _, reviewerID := range reviewers {
+		reviewer, err := p.users.Get(ctx, reviewerID)
+		if err != nil {
+			l.Error("failed to get reviewer", zap.String("user_id", reviewerID), zap.Error(err))
+			return nil, NewError(ErrorCodeUnspecified, "failed to get reviewer")
+		}
+
+		if reviewer.IsActive {
+			remaining = append(remaining, reviewerID)
+			continue
+		}
+
+		if err = p.reviews.Unassign(ctx, prID, reviewerID); err != nil {
+			l.Error("failed to unassign inactive reviewer", zap.String("pull_request_id", prID), zap.String("user_id", reviewerID), zap.Error(err))
+			return nil, NewError(ErrorCodeUnspecified, "failed to unassign inactive reviewer")
+		}
+
+		l.Info("inactive reviewer released", zap.String("pull_request_id", prID), zap.String("user_id", reviewerID))
+		released = append(released, reviewerID)
+	}
+
+	if err := p.recordEvents(ctx, reviewerEvents(prID, model.PREventReviewerUnassigned, released, model.PREventReasonInactive)...); err != nil {
+		return nil, err
+	}
+
+	return remaining, nil
+}
+
+func isSenior(u *model.User) bool {
+	return u.Role == model.UserRoleSenior || u.Role == model.UserRoleLead
+}
+
+// hasSenior Reports whether any of the users is a senior member of team, users from other teams are not counted
+func hasSenior(team []*model.User, userIDs []string) bool {
+	return slices.ContainsFunc(team, func(u *model.User) bool {
+		return isSenior(u) && slices.Contains(userIDs, u.ID)
+	})
+}
+
+func seniorMembers(team []*model.User) []*model.User {
+	seniors := make([]*model.User, 0, len(team))
+	for _, member := range team {
+		if isSenior(member) {
+			seniors = append(seniors, member)
+		}
+	}
+	return seniors
+}
+
+// isAvailable Reports whether the user may be picked as reviewer: active and not out of office
+func isAvailable(u *model.User) bool {
+	return u.IsActive && !u.Absent
+}
+
+// isActiveMember Reports whether the user is an available member of team
+func isActiveMember(team []*model.User, userID string) bool {
+	for _, member := range team {
+		if member.ID == userID {
+			return isAvailable(member)
+		}
+	}
+	return false
+}
+
+// reviewCap Returns the cap of OPEN reviews for a user with the given override, 0 means unlimited
+func (p *PullRequestService) reviewCap(override *int) int {
+	if override != nil {
+		return *override
+	}
+	return p.maxOpenReviews
+}
+
+// withinCapacity Drops the users of team who already review as many OPEN PRs as their cap allows.
+// Counts attached with withReviewLoad are used when present, otherwise the team is counted only if a cap applies.
+func (p *PullRequestService) withinCapacity(ctx context.Context, teamName string, users []*model.User) ([]*model.User, error) {
+	if !slices.ContainsFunc(users, func(u *model.User) bool { return p.reviewCap(u.MaxOpenReviews) > 0 }) {
+		return users, nil
+	}
+
+	load, ok := reviewLoadFromContext(ctx, teamName)
+	if !ok {
+		var err error
+		if load, err = p.reviews.CountTeamOpenReviews(ctx, teamName); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]*model.User, 0, len(users))
+	for _, u := range users {
+		if limit := p.reviewCap(u.MaxOpenReviews); limit > 0 && load[u.ID] >= limit {
+			logger.FromContext(ctx).Debug("reviewer at capacity skipped", zap.String("user_id", u.ID), zap.Int("limit", limit))
+			continue
+		}
+		res = append(res, u)
+	}
+	return res, nil
+}
+
+// selectReviewers Selects up to `count` active reviewers from team who are not out of office and below their
+// cap of OPEN reviews, skipping users listed in exclude. Reviewer rules attached with withReviewerRules are honored.
+func (p *PullRequestService) selectReviewers(ctx context.Context, teamName string, exclude []string, team []*model.User, count int) ([]string, error) {
+	candidates := make([]*model.User, 0, len(team))
+	for _, member := range team {
+		if !isAvailable(member) || slices.Contains(exclude, member.ID) {
+			continue
+		}
+		candidates = append(candidates, member)
+	}
+
+	candidates, err := p.withinCapacity(ctx, teamName, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candidates) == 0 || count <= 0 {
+		return []string{}, nil
+	}
+
+	if rules, ok := reviewerRulesFromContext(ctx); ok {
+		return p.selectWithRules(ctx, teamName, rules, candidates, count)
+	}
+
+	return p.selectorFor(teamName).Select(ctx, teamName, candidates, count)
+}
+
+func toModelUsers(repoUsers []*repository.User) []*model.User {
+	users := make([]*model.User, 0, len(repoUsers))
+	for _, u := range repoUsers {
+		users = append(users, &model.User{
+			ID:             u.ID,
+			Username:       u.Username,
+			IsActive:       u.IsActive,
+			TeamName:       u.TeamName,
+			Role:           u.Role,
+			Skills:         u.Skills,
+			Absent:         u.Absent,
+			MaxOpenReviews: u.MaxOpenReviews,
+		})
+	}
+	return users
+}
+
+func toModelReviews(repoReviews []*repository.Review) []*model.Review {
+	reviews := make([]*model.Review, 0, len(repoReviews))
+	for _, r := range repoReviews {
+		reviews = append(reviews, &model.Review{
+			UserID: r.UserID,
+			State:  r.State,
+		})
+	}
+	return reviews
+}
+
+func pendingReviews(reviewers []string) []*model.Review {
+	reviews := make([]*model.Review, 0, len(reviewers))
+	for _, id := range reviewers {
+		reviews = append(reviews, &model.Review{UserID: id, State: model.ReviewStatePending})
+	}
+	return reviews
+}
+
+func reviewerIDs(reviews []*model.Review) []string {
+	ids := make([]string, 0, len(reviews))
+	for _, r := range reviews {
+		ids = append(ids, r.UserID)
+	}
+	return ids
+}
+
+func (p *PullRequestService) selectorFor(teamName string) ReviewerSelector {
+	if s, ok := p.teamSelectors[teamName]; ok {
+		return s
+	}
+	return p.selector
+}
+
+// WithReviewerSelector Sets the selector used for teams without their own selector
+func (p *PullRequestService) WithReviewerSelector(s ReviewerSelector) *PullRequestService {
+	p.selector = s
+	return p
+}
+
+// WithTeamReviewerSelector Overrides the selector for a single team
+func (p *PullRequestService) WithTeamReviewerSelector(teamName string, s ReviewerSelector) *PullRequestService {
+	p.teamSelectors[teamName] = s
+	return p
+}
+
+func (p *PullRequestService) WithUserRepo(r repository.UserRepository) *PullRequestService {
 	p.users = r
 	return p
 }
@@ -333,3 +1891,19 @@ func (p *PullRequestService) WithReviewRepo(r repository.ReviewRepository) *Pull
 	p.reviews = r
 	return p
 }
+
+func (p *PullRequestService) WithAuditRepo(r repository.AuditRepository) *PullRequestService {
+	p.audits = r
+	return p
+}
+
+func (p *PullRequestService) WithEventRepo(r repository.PullRequestEventRepository) *PullRequestService {
+	p.events = r
+	return p
+}
+
+// WithMaxOpenReviews Sets the default cap of OPEN reviews per user, users may override it. 0 disables the cap.
+func (p *PullRequestService) WithMaxOpenReviews(n int) *PullRequestService {
+	p.maxOpenReviews = n
+	return p
+}
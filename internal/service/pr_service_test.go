@@ -8,10 +8,49 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/yakoovad/avito-winter-2025/internal/audit"
 	"github.com/yakoovad/avito-winter-2025/internal/model"
 	"github.com/yakoovad/avito-winter-2025/internal/repository"
 )
 
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// testReviews Reviews of the PR in the given state, ordered like the repository returns them
+func testReviews(prID string, state model.ReviewState, userIDs ...string) []*repository.Review {
+	reviews := make([]*repository.Review, 0, len(userIDs))
+	for _, id := range userIDs {
+		reviews = append(reviews, &repository.Review{UserID: id, PullRequestID: prID, State: state})
+	}
+	return reviews
+}
+
+// newMockTeamSettings Team repository returning the given settings, nil means the team uses defaults
+func newMockTeamSettings(settings *repository.TeamSettings) *MockTeamRepository {
+	return newMockTeamRules(settings, nil)
+}
+
+// newMockTeamRules Same as newMockTeamSettings, every team additionally has the given reviewer rules
+func newMockTeamRules(settings *repository.TeamSettings, rules []*repository.ReviewerRule) *MockTeamRepository {
+	tr := new(MockTeamRepository)
+	if settings != nil {
+		tr.On("GetSettings", mock.Anything, settings.TeamName).Return(settings, nil).Maybe()
+	} else {
+		tr.On("GetSettings", mock.Anything, mock.Anything).Return(nil, repository.ErrNotFound).Maybe()
+	}
+	if rules == nil {
+		rules = []*repository.ReviewerRule{}
+	}
+	tr.On("GetReviewerRules", mock.Anything, mock.Anything).Return(rules, nil).Maybe()
+	return tr
+}
+
+// withTeamNames Answers GetTeamNames from teams, users missing from it count as one team, so none of them is external
+func withTeamNames(ur *MockUserRepository, teams map[string]string) {
+	ur.On("GetTeamNames", mock.Anything, mock.Anything).Return(teams, nil).Maybe()
+}
+
 func TestPullRequestService_GetUserReview(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -96,8 +135,10 @@ func TestPullRequestService_CreatePullRequest(t *testing.T) {
 		name          string
 		prShort       *model.PullRequestShort
 		setupMocks    func(*MockUserRepository, *MockPullRequestRepository, *MockReviewRepository)
+		settings      *repository.TeamSettings
 		expectedError bool
 		errorCode     ErrorCode
+		needMore      bool
 	}{
 		{
 			name: "success: create PR with 2 reviewers",
@@ -122,6 +163,113 @@ func TestPullRequestService_CreatePullRequest(t *testing.T) {
 			},
 			expectedError: false,
 		},
+		{
+			name: "success: not enough teammates flags PR",
+			prShort: &model.PullRequestShort{
+				ID:       "pr-1004",
+				AuthorID: "u1",
+				Name:     "feat: lonely",
+				Status:   model.PRStatusOpen,
+			},
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u1").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "reviewer1", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "inactive", IsActive: false, TeamName: "backend"},
+				}, nil)
+
+				pr.On("Create", mock.Anything, mock.MatchedBy(func(p *repository.PullRequest) bool {
+					return p.ID == "pr-1004" && p.NeedMoreReviewers
+				})).Return(nil)
+
+				rr.On("Assign", mock.Anything, "pr-1004", []string{"u2"}).Return(nil)
+			},
+			expectedError: false,
+			needMore:      true,
+		},
+		{
+			name: "success: out of office teammates are skipped",
+			prShort: &model.PullRequestShort{
+				ID:       "pr-1006",
+				AuthorID: "u1",
+				Name:     "feat: vacation",
+				Status:   model.PRStatusOpen,
+			},
+			settings: &repository.TeamSettings{
+				TeamName:      "backend",
+				MinReviewers:  1,
+				MaxReviewers:  2,
+				LeadID:        ptr("u9"),
+				LeadMandatory: true,
+			},
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u1").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "away", IsActive: true, TeamName: "backend", Absent: true},
+					{ID: "u3", Username: "reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u9", Username: "lead", IsActive: true, TeamName: "backend", Absent: true},
+				}, nil)
+
+				pr.On("Create", mock.Anything, mock.MatchedBy(func(p *repository.PullRequest) bool {
+					return p.ID == "pr-1006" && !p.NeedMoreReviewers
+				})).Return(nil)
+
+				rr.On("Assign", mock.Anything, "pr-1006", []string{"u3"}).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name: "success: mandatory lead and custom reviewer count",
+			prShort: &model.PullRequestShort{
+				ID:       "pr-1005",
+				AuthorID: "u1",
+				Name:     "feat: with lead",
+				Status:   model.PRStatusOpen,
+			},
+			settings: &repository.TeamSettings{
+				TeamName:      "backend",
+				MinReviewers:  1,
+				MaxReviewers:  3,
+				LeadID:        ptr("u9"),
+				LeadMandatory: true,
+			},
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u1").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "reviewer1", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "reviewer2", IsActive: true, TeamName: "backend"},
+					{ID: "u4", Username: "reviewer3", IsActive: true, TeamName: "backend"},
+					{ID: "u9", Username: "lead", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("Create", mock.Anything, mock.MatchedBy(func(p *repository.PullRequest) bool {
+					return p.ID == "pr-1005" && !p.NeedMoreReviewers
+				})).Return(nil)
+
+				rr.On("Assign", mock.Anything, "pr-1005", []string{"u9", "u2", "u3"}).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name: "success: draft gets no reviewers",
+			prShort: &model.PullRequestShort{
+				ID:       "pr-1006",
+				AuthorID: "u1",
+				Name:     "wip: feature",
+				Status:   model.PRStatusDraft,
+			},
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u1").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "reviewer1", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("Create", mock.Anything, mock.MatchedBy(func(p *repository.PullRequest) bool {
+					return p.ID == "pr-1006" && p.Status == model.PRStatusDraft && !p.NeedMoreReviewers
+				})).Return(nil)
+			},
+			expectedError: false,
+		},
 		{
 			name: "failure: inactive author",
 			prShort: &model.PullRequestShort{
@@ -178,13 +326,17 @@ func TestPullRequestService_CreatePullRequest(t *testing.T) {
 			mockUserRepo := new(MockUserRepository)
 			mockPRRepo := new(MockPullRequestRepository)
 			mockReviewRepo := new(MockReviewRepository)
+			mockTeamRepo := newMockTeamSettings(tt.settings)
 
 			tt.setupMocks(mockUserRepo, mockPRRepo, mockReviewRepo)
+			withTeamNames(mockUserRepo, nil)
 
 			service := NewPullRequestService(mockTx).
 				WithUserRepo(mockUserRepo).
+				WithTeamRepo(mockTeamRepo).
 				WithPullRequestRepo(mockPRRepo).
-				WithReviewRepo(mockReviewRepo)
+				WithReviewRepo(mockReviewRepo).
+				WithReviewerSelector(NewRoundRobinSelector())
 
 			got, err := service.CreatePullRequest(context.Background(), tt.prShort)
 
@@ -196,6 +348,7 @@ func TestPullRequestService_CreatePullRequest(t *testing.T) {
 				assert.NotNil(t, got)
 				assert.Equal(t, tt.prShort.ID, got.ID)
 				assert.Equal(t, tt.prShort.AuthorID, got.AuthorID)
+				assert.Equal(t, tt.needMore, got.NeedMoreReviewers)
 			}
 
 			mockTx.AssertExpectations(t)
@@ -206,115 +359,187 @@ func TestPullRequestService_CreatePullRequest(t *testing.T) {
 	}
 }
 
-func TestPullRequestService_ReassignPullRequest(t *testing.T) {
-	tests := []struct {
-		name          string
-		prID          string
-		userID        string
-		setupMocks    func(*MockUserRepository, *MockPullRequestRepository, *MockReviewRepository)
-		expectedError bool
-		errorCode     ErrorCode
-	}{
-		{
-			name:   "success: reassign to new reviewer",
-			prID:   "pr-1001",
-			userID: "u2",
-			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
-				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
-					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
-					{ID: "u2", Username: "old_reviewer", IsActive: true, TeamName: "backend"},
-					{ID: "u3", Username: "new_reviewer", IsActive: true, TeamName: "backend"},
-				}, nil)
+func TestPullRequestService_CreatePullRequest_LeastLoaded(t *testing.T) {
+	mockTx := new(MockTransactor)
+	mockUserRepo := new(MockUserRepository)
+	mockPRRepo := new(MockPullRequestRepository)
+	mockReviewRepo := new(MockReviewRepository)
 
-				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{
-					ID:       "pr-1001",
-					AuthorID: "u1",
-					Name:     "feat: feature",
-					Status:   model.PRStatusOpen,
-				}, nil)
+	mockUserRepo.On("GetUserTeam", mock.Anything, "u1").Return([]*repository.User{
+		{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+		{ID: "u2", Username: "busy", IsActive: true, TeamName: "backend"},
+		{ID: "u3", Username: "free", IsActive: true, TeamName: "backend"},
+		{ID: "u4", Username: "medium", IsActive: true, TeamName: "backend"},
+	}, nil)
+	mockPRRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockReviewRepo.On("CountTeamOpenReviews", mock.Anything, "backend").Return(map[string]int{"u1": 9, "u2": 4, "u4": 1}, nil)
+	mockReviewRepo.On("Assign", mock.Anything, "pr-1001", []string{"u3", "u4"}).Return(nil)
 
-				pr.On("GetReviewers", mock.Anything, "pr-1001").Return([]string{"u2"}, nil)
+	withTeamNames(mockUserRepo, nil)
 
-				rr.On("Unassign", mock.Anything, "pr-1001", "u2").Return(nil)
-				rr.On("Assign", mock.Anything, "pr-1001", []string{"u3"}).Return(nil)
+	service := NewPullRequestService(mockTx).
+		WithUserRepo(mockUserRepo).
+		WithTeamRepo(newMockTeamSettings(nil)).
+		WithPullRequestRepo(mockPRRepo).
+		WithReviewRepo(mockReviewRepo).
+		WithReviewerSelector(NewLeastLoadedSelector(mockReviewRepo, 1))
+
+	got, err := service.CreatePullRequest(context.Background(), &model.PullRequestShort{
+		ID:       "pr-1001",
+		AuthorID: "u1",
+		Name:     "feat: feature",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"u3", "u4"}, got.Reviewers)
+
+	mockUserRepo.AssertExpectations(t)
+	mockPRRepo.AssertExpectations(t)
+	mockReviewRepo.AssertExpectations(t)
+}
+
+func TestPullRequestService_CreatePullRequest_FallbackTeams(t *testing.T) {
+	mockTx := new(MockTransactor)
+	mockUserRepo := new(MockUserRepository)
+	mockPRRepo := new(MockPullRequestRepository)
+	mockReviewRepo := new(MockReviewRepository)
+	mockTeamRepo := newMockTeamSettings(&repository.TeamSettings{
+		TeamName:          "backend",
+		MinReviewers:      2,
+		MaxReviewers:      2,
+		RequiredApprovals: 1,
+		FallbackTeams:     []string{"platform", "frontend"},
+	})
+
+	mockUserRepo.On("GetUserTeam", mock.Anything, "u1").Return([]*repository.User{
+		{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+		{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
+	}, nil)
+	mockTeamRepo.On("GetTeamMembers", mock.Anything, "platform").Return([]*repository.User{
+		{ID: "p1", Username: "away", IsActive: false, TeamName: "platform"},
+	}, nil)
+	mockTeamRepo.On("GetTeamMembers", mock.Anything, "frontend").Return([]*repository.User{
+		{ID: "f1", Username: "partner", IsActive: true, TeamName: "frontend"},
+		{ID: "f2", Username: "busy", IsActive: true, TeamName: "frontend", MaxOpenReviews: ptr(1)},
+	}, nil)
+	// fallback teams are read without the team lock of CountTeamOpenReviews
+	mockReviewRepo.On("PeekTeamOpenReviews", mock.Anything, "platform").Return(map[string]int{}, nil)
+	mockReviewRepo.On("PeekTeamOpenReviews", mock.Anything, "frontend").Return(map[string]int{"f2": 1}, nil)
+	mockPRRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *repository.PullRequest) bool {
+		return !p.NeedMoreReviewers
+	})).Return(nil)
+	mockReviewRepo.On("Assign", mock.Anything, "pr-1001", []string{"u2", "f1"}).Return(nil)
+
+	mockUserRepo.On("GetTeamNames", mock.Anything, []string{"u1", "u2", "f1"}).Return(map[string]string{"u1": "backend", "u2": "backend", "f1": "frontend"}, nil)
+
+	service := NewPullRequestService(mockTx).
+		WithUserRepo(mockUserRepo).
+		WithTeamRepo(mockTeamRepo).
+		WithPullRequestRepo(mockPRRepo).
+		WithReviewRepo(mockReviewRepo).
+		WithReviewerSelector(NewRoundRobinSelector())
+
+	got, err := service.CreatePullRequest(context.Background(), &model.PullRequestShort{
+		ID:       "pr-1001",
+		AuthorID: "u1",
+		Name:     "feat: feature",
+		Status:   model.PRStatusOpen,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"u2", "f1"}, got.Reviewers)
+	assert.Equal(t, []string{"f1"}, got.ExternalReviewers)
+	assert.False(t, got.NeedMoreReviewers)
+
+	mockUserRepo.AssertExpectations(t)
+	mockTeamRepo.AssertExpectations(t)
+	mockPRRepo.AssertExpectations(t)
+	mockReviewRepo.AssertExpectations(t)
+}
+
+func TestPullRequestService_CreatePullRequest_ReviewerRules(t *testing.T) {
+	exclude := func(reviewer, author string) *repository.ReviewerRule {
+		return &repository.ReviewerRule{Kind: model.ReviewerRuleExclude, ReviewerID: reviewer, TargetID: author}
+	}
+	pair := func(reviewer, partner string) *repository.ReviewerRule {
+		return &repository.ReviewerRule{Kind: model.ReviewerRulePair, ReviewerID: reviewer, TargetID: partner}
+	}
+
+	tests := []struct {
+		name              string
+		team              []*repository.User
+		settings          *repository.TeamSettings
+		rules             []*repository.ReviewerRule
+		expectedReviewers []string
+		expectedCode      ErrorCode
+		expectedMessage   string
+	}{
+		{
+			name: "excluded reviewer is skipped",
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend"},
+				{ID: "u2", IsActive: true, TeamName: "backend"},
+				{ID: "u3", IsActive: true, TeamName: "backend"},
 			},
-			expectedError: false,
+			settings:          &repository.TeamSettings{TeamName: "backend", MinReviewers: 1, MaxReviewers: 2, RequiredApprovals: 1},
+			rules:             []*repository.ReviewerRule{exclude("u2", "u1")},
+			expectedReviewers: []string{"u3"},
 		},
 		{
-			name:   "failure: PR not found",
-			prID:   "unknown",
-			userID: "u2",
-			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
-				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
-					{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
-				}, nil)
-
-				pr.On("Get", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			name: "junior is assigned together with a senior",
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend"},
+				{ID: "j1", IsActive: true, TeamName: "backend"},
+				{ID: "s1", IsActive: true, TeamName: "backend"},
 			},
-			expectedError: true,
-			errorCode:     ErrorCodeNotFound,
+			settings:          &repository.TeamSettings{TeamName: "backend", MinReviewers: 2, MaxReviewers: 2, RequiredApprovals: 1},
+			rules:             []*repository.ReviewerRule{pair("j1", "s1")},
+			expectedReviewers: []string{"j1", "s1"},
 		},
 		{
-			name:   "failure: PR already merged",
-			prID:   "pr-1001",
-			userID: "u2",
-			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
-				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
-					{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
-				}, nil)
-
-				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{
-					ID:       "pr-1001",
-					AuthorID: "u1",
-					Name:     "feat: feature",
-					Status:   model.PRStatusMerged,
-				}, nil)
+			name: "junior is skipped without a slot for the senior",
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend"},
+				{ID: "j1", IsActive: true, TeamName: "backend"},
+				{ID: "s1", IsActive: true, TeamName: "backend"},
 			},
-			expectedError: true,
-			errorCode:     ErrorCodePRMerged,
+			settings:          &repository.TeamSettings{TeamName: "backend", MinReviewers: 1, MaxReviewers: 1, RequiredApprovals: 1},
+			rules:             []*repository.ReviewerRule{pair("j1", "s1")},
+			expectedReviewers: []string{"s1"},
 		},
 		{
-			name:   "failure: reviewer not assigned",
-			prID:   "pr-1001",
-			userID: "u5",
-			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
-				ur.On("GetUserTeam", mock.Anything, "u5").Return([]*repository.User{
-					{ID: "u5", Username: "other", IsActive: true, TeamName: "backend"},
-				}, nil)
-
-				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{
-					ID:       "pr-1001",
-					AuthorID: "u1",
-					Name:     "feat: feature",
-					Status:   model.PRStatusOpen,
-				}, nil)
-
-				pr.On("GetReviewers", mock.Anything, "pr-1001").Return([]string{"u2", "u3"}, nil)
+			name: "excluded mandatory lead is skipped",
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend"},
+				{ID: "u2", IsActive: true, TeamName: "backend"},
+				{ID: "u9", IsActive: true, TeamName: "backend"},
 			},
-			expectedError: true,
-			errorCode:     ErrorCodeNotAssigned,
+			settings:          &repository.TeamSettings{TeamName: "backend", MinReviewers: 1, MaxReviewers: 1, LeadID: ptr("u9"), LeadMandatory: true, RequiredApprovals: 1},
+			rules:             []*repository.ReviewerRule{exclude("u9", "u1")},
+			expectedReviewers: []string{"u2"},
 		},
 		{
-			name:   "failure: no replacement candidate",
-			prID:   "pr-1001",
-			userID: "u2",
-			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
-				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
-					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
-					{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
-				}, nil)
-
-				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{
-					ID:       "pr-1001",
-					AuthorID: "u1",
-					Name:     "feat: feature",
-					Status:   model.PRStatusOpen,
-				}, nil)
-
-				pr.On("GetReviewers", mock.Anything, "pr-1001").Return([]string{"u2"}, nil)
+			name: "exclusion leaves no candidate",
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend"},
+				{ID: "u2", IsActive: true, TeamName: "backend"},
 			},
-			expectedError: true,
-			errorCode:     ErrorCodeNoCandidate,
+			settings:        &repository.TeamSettings{TeamName: "backend", MinReviewers: 1, MaxReviewers: 2, RequiredApprovals: 1},
+			rules:           []*repository.ReviewerRule{exclude("u2", "u1")},
+			expectedCode:    ErrorCodeNoCandidate,
+			expectedMessage: "EXCLUDE u2: u2 may not review PRs of u1",
+		},
+		{
+			name: "junior without an available senior leaves no candidate",
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend"},
+				{ID: "j1", IsActive: true, TeamName: "backend"},
+				{ID: "s1", IsActive: false, TeamName: "backend"},
+			},
+			settings:        &repository.TeamSettings{TeamName: "backend", MinReviewers: 1, MaxReviewers: 2, RequiredApprovals: 1},
+			rules:           []*repository.ReviewerRule{pair("j1", "s1")},
+			expectedCode:    ErrorCodeNoCandidate,
+			expectedMessage: "PAIR j1: must be paired with one of s1",
 		},
 	}
 
@@ -322,27 +547,41 @@ func TestPullRequestService_ReassignPullRequest(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockTx := new(MockTransactor)
 			mockUserRepo := new(MockUserRepository)
+			withTeamNames(mockUserRepo, nil)
 			mockPRRepo := new(MockPullRequestRepository)
 			mockReviewRepo := new(MockReviewRepository)
+			mockTeamRepo := newMockTeamRules(tt.settings, tt.rules)
 
-			tt.setupMocks(mockUserRepo, mockPRRepo, mockReviewRepo)
+			mockUserRepo.On("GetUserTeam", mock.Anything, "u1").Return(tt.team, nil)
+			if tt.expectedCode == "" {
+				mockPRRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+				mockReviewRepo.On("Assign", mock.Anything, "pr-1001", tt.expectedReviewers).Return(nil)
+			}
 
 			service := NewPullRequestService(mockTx).
 				WithUserRepo(mockUserRepo).
+				WithTeamRepo(mockTeamRepo).
 				WithPullRequestRepo(mockPRRepo).
-				WithReviewRepo(mockReviewRepo)
+				WithReviewRepo(mockReviewRepo).
+				WithReviewerSelector(NewRoundRobinSelector())
 
-			got, err := service.ReassignPullRequest(context.Background(), tt.prID, tt.userID)
+			got, err := service.CreatePullRequest(context.Background(), &model.PullRequestShort{
+				ID:       "pr-1001",
+				AuthorID: "u1",
+				Name:     "feat: feature",
+				Status:   model.PRStatusOpen,
+			})
 
-			if tt.expectedError {
-				assert.NotNil(t, err)
-				assert.Equal(t, tt.errorCode, err.Code)
+			if tt.expectedCode != "" {
+				if assert.NotNil(t, err) {
+					assert.Equal(t, tt.expectedCode, err.Code)
+					assert.Contains(t, err.Message, tt.expectedMessage)
+				}
 			} else {
 				assert.Nil(t, err)
-				assert.NotNil(t, got)
+				assert.Equal(t, tt.expectedReviewers, got.Reviewers)
 			}
 
-			mockTx.AssertExpectations(t)
 			mockUserRepo.AssertExpectations(t)
 			mockPRRepo.AssertExpectations(t)
 			mockReviewRepo.AssertExpectations(t)
@@ -350,68 +589,1930 @@ func TestPullRequestService_ReassignPullRequest(t *testing.T) {
 	}
 }
 
-func TestPullRequestService_MergePullRequest(t *testing.T) {
-	now := time.Now()
-
+func TestPullRequestService_CreatePullRequest_RequireSenior(t *testing.T) {
 	tests := []struct {
-		name          string
-		prID          string
-		setupMocks    func(*MockPullRequestRepository)
-		expectedError bool
-		errorCode     ErrorCode
+		name              string
+		team              []*repository.User
+		settings          *repository.TeamSettings
+		expectedReviewers []string
 	}{
 		{
-			name: "success: - merge PR",
-			prID: "pr-1001",
-			setupMocks: func(pr *MockPullRequestRepository) {
-				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
-					return p.ID == "pr-1001" && *p.Status == model.PRStatusMerged
-				})).Return(&repository.PullRequest{
-					ID:       "pr-1001",
-					AuthorID: "u1",
-					Name:     "feat: feature",
-					Status:   model.PRStatusMerged,
-					MergedAt: &now,
-				}, nil)
-
-				pr.On("GetReviewers", mock.Anything, "pr-1001").Return([]string{"u2", "u3"}, nil)
+			name: "slot kept for a senior",
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend", Role: model.UserRoleJunior},
+				{ID: "m1", IsActive: true, TeamName: "backend", Role: model.UserRoleMiddle},
+				{ID: "m2", IsActive: true, TeamName: "backend", Role: model.UserRoleJunior},
+				{ID: "s1", IsActive: true, TeamName: "backend", Role: model.UserRoleSenior},
 			},
-			expectedError: false,
+			settings:          &repository.TeamSettings{TeamName: "backend", MinReviewers: 2, MaxReviewers: 2, RequiredApprovals: 1, RequireSenior: true},
+			expectedReviewers: []string{"s1", "m2"},
 		},
 		{
-			name: "failure: PR not found",
-			prID: "unknown",
-			setupMocks: func(pr *MockPullRequestRepository) {
-				pr.On("Patch", mock.Anything, mock.Anything).Return(nil, repository.ErrNotFound)
+			name: "mandatory lead counts as senior",
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend", Role: model.UserRoleJunior},
+				{ID: "m1", IsActive: true, TeamName: "backend", Role: model.UserRoleMiddle},
+				{ID: "s1", IsActive: true, TeamName: "backend", Role: model.UserRoleSenior},
+				{ID: "u9", IsActive: true, TeamName: "backend", Role: model.UserRoleLead},
 			},
-			expectedError: true,
-			errorCode:     ErrorCodeNotFound,
+			settings:          &repository.TeamSettings{TeamName: "backend", MinReviewers: 2, MaxReviewers: 2, LeadID: ptr("u9"), LeadMandatory: true, RequiredApprovals: 1, RequireSenior: true},
+			expectedReviewers: []string{"u9", "m1"},
+		},
+		{
+			name: "no senior available",
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend", Role: model.UserRoleJunior},
+				{ID: "m1", IsActive: true, TeamName: "backend", Role: model.UserRoleMiddle},
+				{ID: "m2", IsActive: true, TeamName: "backend", Role: model.UserRoleMiddle},
+				{ID: "s1", IsActive: false, TeamName: "backend", Role: model.UserRoleSenior},
+			},
+			settings:          &repository.TeamSettings{TeamName: "backend", MinReviewers: 2, MaxReviewers: 2, RequiredApprovals: 1, RequireSenior: true},
+			expectedReviewers: []string{"m1", "m2"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockTx := new(MockTransactor)
+			mockUserRepo := new(MockUserRepository)
+			withTeamNames(mockUserRepo, nil)
 			mockPRRepo := new(MockPullRequestRepository)
+			mockReviewRepo := new(MockReviewRepository)
 
-			tt.setupMocks(mockPRRepo)
+			mockUserRepo.On("GetUserTeam", mock.Anything, "u1").Return(tt.team, nil)
+			mockPRRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+			mockReviewRepo.On("Assign", mock.Anything, "pr-1001", tt.expectedReviewers).Return(nil)
 
 			service := NewPullRequestService(mockTx).
-				WithPullRequestRepo(mockPRRepo)
+				WithUserRepo(mockUserRepo).
+				WithTeamRepo(newMockTeamSettings(tt.settings)).
+				WithPullRequestRepo(mockPRRepo).
+				WithReviewRepo(mockReviewRepo).
+				WithReviewerSelector(NewRoundRobinSelector())
 
-			got, err := service.MergePullRequest(context.Background(), tt.prID)
+			got, err := service.CreatePullRequest(context.Background(), &model.PullRequestShort{
+				ID:       "pr-1001",
+				AuthorID: "u1",
+				Name:     "feat: feature",
+				Status:   model.PRStatusOpen,
+			})
 
-			if tt.expectedError {
-				assert.NotNil(t, err)
-				assert.Equal(t, tt.errorCode, err.Code)
-			} else {
-				assert.Nil(t, err)
-				assert.NotNil(t, got)
-				assert.Equal(t, model.PRStatusMerged, got.Status)
-			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedReviewers, got.Reviewers)
+
+			mockUserRepo.AssertExpectations(t)
+			mockPRRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_CreatePullRequest_ReviewCap(t *testing.T) {
+	tests := []struct {
+		name              string
+		maxOpenReviews    int
+		team              []*repository.User
+		settings          *repository.TeamSettings
+		load              map[string]int
+		expectedReviewers []string
+		needMore          bool
+	}{
+		{
+			name:           "users at the global cap are skipped",
+			maxOpenReviews: 2,
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend"},
+				{ID: "u2", IsActive: true, TeamName: "backend"},
+				{ID: "u3", IsActive: true, TeamName: "backend"},
+				{ID: "u4", IsActive: true, TeamName: "backend"},
+			},
+			load:              map[string]int{"u2": 2, "u3": 1},
+			expectedReviewers: []string{"u3", "u4"},
+		},
+		{
+			name:           "user override raises the cap",
+			maxOpenReviews: 2,
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend"},
+				{ID: "u2", IsActive: true, TeamName: "backend", MaxOpenReviews: ptr(5)},
+				{ID: "u3", IsActive: true, TeamName: "backend"},
+				{ID: "u4", IsActive: true, TeamName: "backend"},
+			},
+			load:              map[string]int{"u2": 2, "u3": 2},
+			expectedReviewers: []string{"u2", "u4"},
+		},
+		{
+			name:           "cap leaves the PR short",
+			maxOpenReviews: 1,
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend"},
+				{ID: "u2", IsActive: true, TeamName: "backend"},
+				{ID: "u3", IsActive: true, TeamName: "backend"},
+				{ID: "u4", IsActive: true, TeamName: "backend"},
+			},
+			load:              map[string]int{"u2": 1, "u3": 1},
+			expectedReviewers: []string{"u4"},
+			needMore:          true,
+		},
+		{
+			name: "mandatory lead at capacity is skipped",
+			team: []*repository.User{
+				{ID: "u1", IsActive: true, TeamName: "backend"},
+				{ID: "u2", IsActive: true, TeamName: "backend"},
+				{ID: "u3", IsActive: true, TeamName: "backend"},
+				{ID: "u9", IsActive: true, TeamName: "backend", MaxOpenReviews: ptr(1)},
+			},
+			settings:          &repository.TeamSettings{TeamName: "backend", MinReviewers: 2, MaxReviewers: 2, LeadID: ptr("u9"), LeadMandatory: true, RequiredApprovals: 1},
+			load:              map[string]int{"u9": 1},
+			expectedReviewers: []string{"u2", "u3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockUserRepo := new(MockUserRepository)
+			withTeamNames(mockUserRepo, nil)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockReviewRepo := new(MockReviewRepository)
+
+			mockUserRepo.On("GetUserTeam", mock.Anything, "u1").Return(tt.team, nil)
+			mockPRRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *repository.PullRequest) bool {
+				return p.NeedMoreReviewers == tt.needMore
+			})).Return(nil)
+			mockReviewRepo.On("CountTeamOpenReviews", mock.Anything, "backend").Return(tt.load, nil)
+			mockReviewRepo.On("Assign", mock.Anything, "pr-1001", tt.expectedReviewers).Return(nil)
+
+			service := NewPullRequestService(mockTx).
+				WithUserRepo(mockUserRepo).
+				WithTeamRepo(newMockTeamSettings(tt.settings)).
+				WithPullRequestRepo(mockPRRepo).
+				WithReviewRepo(mockReviewRepo).
+				WithReviewerSelector(NewRoundRobinSelector()).
+				WithMaxOpenReviews(tt.maxOpenReviews)
+
+			got, err := service.CreatePullRequest(context.Background(), &model.PullRequestShort{
+				ID:       "pr-1001",
+				AuthorID: "u1",
+				Name:     "feat: feature",
+				Status:   model.PRStatusOpen,
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedReviewers, got.Reviewers)
+			assert.Equal(t, tt.needMore, got.NeedMoreReviewers)
+
+			mockUserRepo.AssertExpectations(t)
+			mockPRRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_ReassignPullRequest_RequireSenior(t *testing.T) {
+	mockTx := new(MockTransactor)
+	mockUserRepo := new(MockUserRepository)
+	mockPRRepo := new(MockPullRequestRepository)
+	mockReviewRepo := new(MockReviewRepository)
+
+	mockUserRepo.On("GetUserTeam", mock.Anything, "s1").Return([]*repository.User{
+		{ID: "u1", IsActive: true, TeamName: "backend", Role: model.UserRoleJunior},
+		{ID: "m1", IsActive: true, TeamName: "backend", Role: model.UserRoleMiddle},
+		{ID: "m2", IsActive: true, TeamName: "backend", Role: model.UserRoleMiddle},
+		{ID: "s1", IsActive: true, TeamName: "backend", Role: model.UserRoleSenior},
+		{ID: "s2", IsActive: true, TeamName: "backend", Role: model.UserRoleSenior},
+	}, nil)
+	mockPRRepo.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+		ID:       "pr-1001",
+		AuthorID: "u1",
+		Status:   model.PRStatusOpen,
+	}, nil)
+	mockReviewRepo.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "s1", "m1"), nil)
+	mockUserRepo.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+	mockReviewRepo.On("Unassign", mock.Anything, "pr-1001", "s1").Return(nil)
+	mockReviewRepo.On("Assign", mock.Anything, "pr-1001", []string{"s2"}).Return(nil)
+	mockReviewRepo.On("RecordReassignments", mock.Anything, []*repository.Reassignment{
+		{PullRequestID: "pr-1001", OldReviewerID: "s1", NewReviewerID: ptr("s2")},
+	}).Return(nil)
+
+	withTeamNames(mockUserRepo, nil)
+
+	service := NewPullRequestService(mockTx).
+		WithUserRepo(mockUserRepo).
+		WithTeamRepo(newMockTeamSettings(&repository.TeamSettings{
+			TeamName:          "backend",
+			MinReviewers:      2,
+			MaxReviewers:      2,
+			RequiredApprovals: 1,
+			RequireSenior:     true,
+		})).
+		WithPullRequestRepo(mockPRRepo).
+		WithReviewRepo(mockReviewRepo).
+		WithReviewerSelector(NewRoundRobinSelector())
+
+	got, err := service.ReassignPullRequest(context.Background(), "pr-1001", "s1", "", false)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "s2", got.ReplacedBy)
+
+	mockUserRepo.AssertExpectations(t)
+	mockPRRepo.AssertExpectations(t)
+	mockReviewRepo.AssertExpectations(t)
+}
+
+func TestPullRequestService_ReassignPullRequest_FallbackTeams(t *testing.T) {
+	mockTx := new(MockTransactor)
+	mockUserRepo := new(MockUserRepository)
+	mockPRRepo := new(MockPullRequestRepository)
+	mockReviewRepo := new(MockReviewRepository)
+	mockTeamRepo := newMockTeamSettings(&repository.TeamSettings{
+		TeamName:          "backend",
+		MinReviewers:      1,
+		MaxReviewers:      2,
+		RequiredApprovals: 1,
+		FallbackTeams:     []string{"frontend"},
+	})
+
+	mockUserRepo.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+		{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+		{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
+	}, nil)
+	mockPRRepo.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+		ID:       "pr-1001",
+		AuthorID: "u1",
+		Status:   model.PRStatusOpen,
+	}, nil)
+	mockReviewRepo.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+	mockUserRepo.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+	mockTeamRepo.On("GetTeamMembers", mock.Anything, "frontend").Return([]*repository.User{
+		{ID: "f1", Username: "partner", IsActive: true, TeamName: "frontend"},
+	}, nil)
+	mockReviewRepo.On("PeekTeamOpenReviews", mock.Anything, "frontend").Return(map[string]int{}, nil)
+	mockReviewRepo.On("Unassign", mock.Anything, "pr-1001", "u2").Return(nil)
+	mockReviewRepo.On("Assign", mock.Anything, "pr-1001", []string{"f1"}).Return(nil)
+	mockReviewRepo.On("RecordReassignments", mock.Anything, []*repository.Reassignment{
+		{PullRequestID: "pr-1001", OldReviewerID: "u2", NewReviewerID: ptr("f1")},
+	}).Return(nil)
+
+	mockUserRepo.On("GetTeamNames", mock.Anything, []string{"u1", "f1"}).Return(map[string]string{"u1": "backend", "f1": "frontend"}, nil)
+
+	service := NewPullRequestService(mockTx).
+		WithUserRepo(mockUserRepo).
+		WithTeamRepo(mockTeamRepo).
+		WithPullRequestRepo(mockPRRepo).
+		WithReviewRepo(mockReviewRepo).
+		WithReviewerSelector(NewRoundRobinSelector())
+
+	got, err := service.ReassignPullRequest(context.Background(), "pr-1001", "u2", "", false)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "f1", got.ReplacedBy)
+	assert.Equal(t, []string{"f1"}, got.ExternalReviewers)
+
+	mockUserRepo.AssertExpectations(t)
+	mockTeamRepo.AssertExpectations(t)
+	mockPRRepo.AssertExpectations(t)
+	mockReviewRepo.AssertExpectations(t)
+}
+
+func TestPullRequestService_ReassignPullRequest_ExternalReviewer(t *testing.T) {
+	mockTx := new(MockTransactor)
+	mockUserRepo := new(MockUserRepository)
+	mockPRRepo := new(MockPullRequestRepository)
+	mockReviewRepo := new(MockReviewRepository)
+	// only the author's team has settings, looking up the reviewer's team would fail the test
+	mockTeamRepo := newMockTeamSettings(&repository.TeamSettings{
+		TeamName:          "backend",
+		MinReviewers:      1,
+		MaxReviewers:      2,
+		RequiredApprovals: 1,
+	})
+
+	mockUserRepo.On("GetUserTeam", mock.Anything, "f1").Return([]*repository.User{
+		{ID: "f1", Username: "partner", IsActive: true, TeamName: "frontend"},
+		{ID: "f2", Username: "other_partner", IsActive: true, TeamName: "frontend"},
+	}, nil)
+	mockPRRepo.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+		ID:       "pr-1001",
+		AuthorID: "u1",
+		Status:   model.PRStatusOpen,
+	}, nil)
+	mockReviewRepo.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "f1"), nil)
+	mockUserRepo.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+	mockReviewRepo.On("Unassign", mock.Anything, "pr-1001", "f1").Return(nil)
+	mockReviewRepo.On("Assign", mock.Anything, "pr-1001", []string{"f2"}).Return(nil)
+	mockReviewRepo.On("RecordReassignments", mock.Anything, mock.Anything).Return(nil)
+
+	mockUserRepo.On("GetTeamNames", mock.Anything, []string{"u1", "f2"}).Return(map[string]string{"u1": "backend", "f2": "frontend"}, nil)
+
+	service := NewPullRequestService(mockTx).
+		WithUserRepo(mockUserRepo).
+		WithTeamRepo(mockTeamRepo).
+		WithPullRequestRepo(mockPRRepo).
+		WithReviewRepo(mockReviewRepo).
+		WithReviewerSelector(NewRoundRobinSelector())
+
+	got, err := service.ReassignPullRequest(context.Background(), "pr-1001", "f1", "", false)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "f2", got.ReplacedBy)
+	assert.Equal(t, []string{"f2"}, got.ExternalReviewers)
+
+	mockUserRepo.AssertExpectations(t)
+	mockTeamRepo.AssertExpectations(t)
+	mockPRRepo.AssertExpectations(t)
+	mockReviewRepo.AssertExpectations(t)
+}
+
+func TestPullRequestService_ReassignPullRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		prID          string
+		userID        string
+		newUserID     string
+		force         bool
+		setupMocks    func(*MockUserRepository, *MockPullRequestRepository, *MockReviewRepository)
+		settings      *repository.TeamSettings
+		expectedError bool
+		errorCode     ErrorCode
+		replacedBy    string
+	}{
+		{
+			name:   "success: reassign to new reviewer",
+			prID:   "pr-1001",
+			userID: "u2",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "old_reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "new_reviewer", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusOpen,
+				}, nil)
+
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+
+				rr.On("Unassign", mock.Anything, "pr-1001", "u2").Return(nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u3"}).Return(nil)
+				rr.On("RecordReassignments", mock.Anything, []*repository.Reassignment{
+					{PullRequestID: "pr-1001", OldReviewerID: "u2", NewReviewerID: ptr("u3")},
+				}).Return(nil)
+			},
+			expectedError: false,
+			replacedBy:    "u3",
+		},
+		{
+			name:   "failure: approved reviewer without force",
+			prID:   "pr-1001",
+			userID: "u2",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "old_reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "new_reviewer", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusOpen,
+				}, nil)
+
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStateApproved, "u2"), nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeAlreadyApproved,
+		},
+		{
+			name:   "success: approved reviewer replaced with force",
+			prID:   "pr-1001",
+			userID: "u2",
+			force:  true,
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "old_reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "new_reviewer", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusOpen,
+				}, nil)
+
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStateApproved, "u2"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				rr.On("Unassign", mock.Anything, "pr-1001", "u2").Return(nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u3"}).Return(nil)
+				rr.On("RecordReassignments", mock.Anything, []*repository.Reassignment{
+					{PullRequestID: "pr-1001", OldReviewerID: "u2", NewReviewerID: ptr("u3")},
+				}).Return(nil)
+			},
+			expectedError: false,
+			replacedBy:    "u3",
+		},
+		{
+			name:      "success: targeted reassignment",
+			prID:      "pr-1001",
+			userID:    "u2",
+			newUserID: "u4",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "old_reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u4", Username: "chosen", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusOpen,
+				}, nil)
+
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2", "u3"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				ur.On("Get", mock.Anything, "u4").Return(&repository.User{ID: "u4", IsActive: true, TeamName: "backend"}, nil)
+				rr.On("Unassign", mock.Anything, "pr-1001", "u2").Return(nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u4"}).Return(nil)
+				rr.On("RecordReassignments", mock.Anything, []*repository.Reassignment{
+					{PullRequestID: "pr-1001", OldReviewerID: "u2", NewReviewerID: ptr("u4")},
+				}).Return(nil)
+			},
+			expectedError: false,
+			replacedBy:    "u4",
+		},
+		{
+			name:      "failure: targeted replacement already assigned",
+			prID:      "pr-1001",
+			userID:    "u2",
+			newUserID: "u3",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "old_reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "reviewer", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusOpen,
+				}, nil)
+
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2", "u3"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeAlreadyAssigned,
+		},
+		{
+			name:      "failure: targeted replacement is the author",
+			prID:      "pr-1001",
+			userID:    "u2",
+			newUserID: "u1",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "old_reviewer", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusOpen,
+				}, nil)
+
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotEligible,
+		},
+		{
+			name:      "failure: targeted replacement inactive",
+			prID:      "pr-1001",
+			userID:    "u2",
+			newUserID: "u4",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "old_reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u4", Username: "away", IsActive: false, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusOpen,
+				}, nil)
+
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				ur.On("Get", mock.Anything, "u4").Return(&repository.User{ID: "u4", IsActive: false, TeamName: "backend"}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUserInactive,
+		},
+		{
+			name:   "failure: mandatory lead cannot be replaced",
+			prID:   "pr-1001",
+			userID: "u9",
+			settings: &repository.TeamSettings{
+				TeamName:      "backend",
+				MinReviewers:  2,
+				MaxReviewers:  2,
+				LeadID:        ptr("u9"),
+				LeadMandatory: true,
+			},
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u9").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u9", Username: "lead", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusOpen,
+				}, nil)
+
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u9"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNoCandidate,
+		},
+		{
+			name:   "failure: PR not found",
+			prID:   "unknown",
+			userID: "u2",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+		{
+			name:   "failure: PR already merged",
+			prID:   "pr-1001",
+			userID: "u2",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusMerged,
+				}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodePRMerged,
+		},
+		{
+			name:   "failure: PR closed",
+			prID:   "pr-1001",
+			userID: "u2",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusClosed,
+				}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodePRNotOpen,
+		},
+		{
+			name:   "failure: reviewer not assigned",
+			prID:   "pr-1001",
+			userID: "u5",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u5").Return([]*repository.User{
+					{ID: "u5", Username: "other", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusOpen,
+				}, nil)
+
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2", "u3"), nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotAssigned,
+		},
+		{
+			name:   "failure: no replacement candidate",
+			prID:   "pr-1001",
+			userID: "u2",
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Name:     "feat: feature",
+					Status:   model.PRStatusOpen,
+				}, nil)
+
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNoCandidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockUserRepo := new(MockUserRepository)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockReviewRepo := new(MockReviewRepository)
+			mockTeamRepo := newMockTeamSettings(tt.settings)
+
+			tt.setupMocks(mockUserRepo, mockPRRepo, mockReviewRepo)
+			withTeamNames(mockUserRepo, nil)
+
+			service := NewPullRequestService(mockTx).
+				WithUserRepo(mockUserRepo).
+				WithTeamRepo(mockTeamRepo).
+				WithPullRequestRepo(mockPRRepo).
+				WithReviewRepo(mockReviewRepo).
+				WithReviewerSelector(NewRoundRobinSelector())
+
+			got, err := service.ReassignPullRequest(context.Background(), tt.prID, tt.userID, tt.newUserID, tt.force)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.NotNil(t, got)
+				assert.NotContains(t, got.Reviewers, tt.userID)
+				assert.Contains(t, got.Reviewers, tt.replacedBy)
+				assert.Equal(t, tt.replacedBy, got.ReplacedBy)
+			}
+
+			mockTx.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+			mockPRRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_MergePullRequest(t *testing.T) {
+	now := time.Now()
+
+	openPR := &repository.PullRequest{
+		ID:       "pr-1001",
+		AuthorID: "u1",
+		Name:     "feat: feature",
+		Status:   model.PRStatusOpen,
+	}
+	mergedPR := &repository.PullRequest{
+		ID:       "pr-1001",
+		AuthorID: "u1",
+		Name:     "feat: feature",
+		Status:   model.PRStatusMerged,
+		MergedAt: &now,
+	}
+
+	tests := []struct {
+		name              string
+		prID              string
+		force             bool
+		settings          *repository.TeamSettings
+		setupMocks        func(*MockPullRequestRepository, *MockUserRepository, *MockReviewRepository)
+		expectedError     bool
+		errorCode         ErrorCode
+		expectedReviewers []string
+		expectedMergedAt  *time.Time
+	}{
+		{
+			name: "success: - merge PR",
+			prID: "pr-1001",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{
+					{UserID: "u2", PullRequestID: "pr-1001", State: model.ReviewStateApproved},
+					{UserID: "u3", PullRequestID: "pr-1001", State: model.ReviewStatePending},
+				}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return p.ID == "pr-1001" && *p.Status == model.PRStatusMerged && p.ForceMerged == nil
+				})).Return(mergedPR, nil)
+			},
+			expectedError:     false,
+			expectedReviewers: []string{"u2", "u3"},
+		},
+		{
+			name: "success: inactive reviewers released when policy forbids them",
+			prID: "pr-1001",
+			settings: &repository.TeamSettings{
+				TeamName:               "backend",
+				MinReviewers:           2,
+				MaxReviewers:           2,
+				AllowInactiveReviewers: false,
+				RequiredApprovals:      1,
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStateApproved, "u2", "u3"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				pr.On("Patch", mock.Anything, mock.Anything).Return(mergedPR, nil)
+				ur.On("Get", mock.Anything, "u2").Return(&repository.User{ID: "u2", IsActive: false, TeamName: "backend"}, nil)
+				ur.On("Get", mock.Anything, "u3").Return(&repository.User{ID: "u3", IsActive: true, TeamName: "backend"}, nil)
+				rr.On("Unassign", mock.Anything, "pr-1001", "u2").Return(nil)
+			},
+			expectedError:     false,
+			expectedReviewers: []string{"u3"},
+		},
+		{
+			name: "failure: approval of inactive reviewer does not count when policy forbids them",
+			prID: "pr-1001",
+			settings: &repository.TeamSettings{
+				TeamName:               "backend",
+				MinReviewers:           2,
+				MaxReviewers:           2,
+				AllowInactiveReviewers: false,
+				RequiredApprovals:      1,
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{
+					{UserID: "u2", PullRequestID: "pr-1001", State: model.ReviewStateApproved},
+					{UserID: "u3", PullRequestID: "pr-1001", State: model.ReviewStatePending},
+				}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				ur.On("Get", mock.Anything, "u2").Return(&repository.User{ID: "u2", IsActive: false, TeamName: "backend"}, nil)
+				ur.On("Get", mock.Anything, "u3").Return(&repository.User{ID: "u3", IsActive: true, TeamName: "backend"}, nil)
+				rr.On("Unassign", mock.Anything, "pr-1001", "u2").Return(nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotApproved,
+		},
+		{
+			name: "failure: not enough approvals",
+			prID: "pr-1001",
+			settings: &repository.TeamSettings{
+				TeamName:               "backend",
+				MinReviewers:           2,
+				MaxReviewers:           2,
+				AllowInactiveReviewers: true,
+				RequiredApprovals:      2,
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{
+					{UserID: "u2", PullRequestID: "pr-1001", State: model.ReviewStateApproved},
+					{UserID: "u3", PullRequestID: "pr-1001", State: model.ReviewStatePending},
+				}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotApproved,
+		},
+		{
+			name: "failure: no reviewers",
+			prID: "pr-1001",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotApproved,
+		},
+		{
+			name: "failure: changes requested",
+			prID: "pr-1001",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{
+					{UserID: "u2", PullRequestID: "pr-1001", State: model.ReviewStateApproved},
+					{UserID: "u3", PullRequestID: "pr-1001", State: model.ReviewStateChangesRequested},
+				}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotApproved,
+		},
+		{
+			name:  "success: force merge is recorded",
+			prID:  "pr-1001",
+			force: true,
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStateChangesRequested, "u2"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return p.ForceMerged != nil && *p.ForceMerged
+				})).Return(&repository.PullRequest{
+					ID:          "pr-1001",
+					AuthorID:    "u1",
+					Status:      model.PRStatusMerged,
+					ForceMerged: true,
+				}, nil)
+			},
+			expectedError:     false,
+			expectedReviewers: []string{"u2"},
+		},
+		{
+			name: "success: repeated merge returns stored record unchanged",
+			prID: "pr-1001",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(mergedPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+			},
+			expectedError:     false,
+			expectedReviewers: []string{"u2"},
+			expectedMergedAt:  &now,
+		},
+		{
+			name: "failure: draft PR cannot be merged",
+			prID: "pr-1001",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Status:   model.PRStatusDraft,
+				}, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidTransition,
+		},
+		{
+			name: "failure: PR not found",
+			prID: "unknown",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockUserRepo := new(MockUserRepository)
+			mockReviewRepo := new(MockReviewRepository)
+
+			tt.setupMocks(mockPRRepo, mockUserRepo, mockReviewRepo)
+			withTeamNames(mockUserRepo, nil)
+
+			service := NewPullRequestService(mockTx).
+				WithPullRequestRepo(mockPRRepo).
+				WithUserRepo(mockUserRepo).
+				WithTeamRepo(newMockTeamSettings(tt.settings)).
+				WithReviewRepo(mockReviewRepo)
+
+			got, err := service.MergePullRequest(context.Background(), tt.prID, tt.force)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.NotNil(t, got)
+				assert.Equal(t, model.PRStatusMerged, got.Status)
+				assert.Equal(t, tt.expectedReviewers, got.Reviewers)
+				assert.Equal(t, tt.force, got.ForceMerged)
+				if tt.expectedMergedAt != nil {
+					assert.Equal(t, tt.expectedMergedAt, got.MergedAt)
+				}
+			}
+
+			mockTx.AssertExpectations(t)
+			mockPRRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_AddReviewer(t *testing.T) {
+	openPR := &repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen, NeedMoreReviewers: true}
+	author := &repository.User{ID: "u1", IsActive: true, TeamName: "backend"}
+
+	tests := []struct {
+		name              string
+		userID            string
+		settings          *repository.TeamSettings
+		rules             []*repository.ReviewerRule
+		setupMocks        func(*MockPullRequestRepository, *MockUserRepository, *MockReviewRepository)
+		expectedError     bool
+		errorCode         ErrorCode
+		expectedReviewers []string
+		needMore          bool
+	}{
+		{
+			name:   "success: teammate added and flag cleared",
+			userID: "u3",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+				ur.On("Get", mock.Anything, "u3").Return(&repository.User{ID: "u3", IsActive: true, TeamName: "backend"}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(author, nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u3"}).Return(nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return p.Status == nil && !*p.NeedMoreReviewers
+				})).Return(&repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen}, nil)
+			},
+			expectedReviewers: []string{"u2", "u3"},
+		},
+		{
+			name:   "success: external reviewer allowed by policy",
+			userID: "f1",
+			settings: &repository.TeamSettings{
+				TeamName:               "backend",
+				MinReviewers:           1,
+				MaxReviewers:           2,
+				RequiredApprovals:      1,
+				AllowExternalReviewers: true,
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("Get", mock.Anything, "f1").Return(&repository.User{ID: "f1", IsActive: true, TeamName: "frontend"}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(author, nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"f1"}).Return(nil)
+				pr.On("Patch", mock.Anything, mock.Anything).Return(&repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen}, nil)
+			},
+			expectedReviewers: []string{"f1"},
+		},
+		{
+			name:   "failure: external reviewer without policy",
+			userID: "f1",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("Get", mock.Anything, "f1").Return(&repository.User{ID: "f1", IsActive: true, TeamName: "frontend"}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(author, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotEligible,
+		},
+		{
+			name:   "failure: excluded by team rule",
+			userID: "u3",
+			rules: []*repository.ReviewerRule{
+				{Kind: model.ReviewerRuleExclude, ReviewerID: "u3", TargetID: "u1"},
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("Get", mock.Anything, "u3").Return(&repository.User{ID: "u3", IsActive: true, TeamName: "backend"}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(author, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotEligible,
+		},
+		{
+			name:   "failure: author",
+			userID: "u1",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(author, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotEligible,
+		},
+		{
+			name:   "failure: already assigned",
+			userID: "u2",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(author, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeAlreadyAssigned,
+		},
+		{
+			name:   "failure: inactive reviewer",
+			userID: "u3",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(author, nil)
+				ur.On("Get", mock.Anything, "u3").Return(&repository.User{ID: "u3", IsActive: false, TeamName: "backend"}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUserInactive,
+		},
+		{
+			name:   "failure: absent reviewer",
+			userID: "u3",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(author, nil)
+				ur.On("Get", mock.Anything, "u3").Return(&repository.User{ID: "u3", IsActive: true, TeamName: "backend", Absent: true}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUserAbsent,
+		},
+		{
+			name:   "failure: reviewer at capacity",
+			userID: "u3",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(author, nil)
+				ur.On("Get", mock.Anything, "u3").Return(&repository.User{ID: "u3", IsActive: true, TeamName: "backend", MaxOpenReviews: ptr(2)}, nil)
+				rr.On("CountTeamOpenReviews", mock.Anything, "backend").Return(map[string]int{"u3": 2}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeAtCapacity,
+		},
+		{
+			name:   "failure: reviewer not found",
+			userID: "unknown",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(author, nil)
+				ur.On("Get", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+		{
+			name:   "failure: PR already merged",
+			userID: "u3",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Status:   model.PRStatusMerged,
+				}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodePRMerged,
+		},
+		{
+			name:   "failure: draft PR",
+			userID: "u3",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Status:   model.PRStatusDraft,
+				}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodePRNotOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockUserRepo := new(MockUserRepository)
+			mockReviewRepo := new(MockReviewRepository)
+
+			tt.setupMocks(mockPRRepo, mockUserRepo, mockReviewRepo)
+			withTeamNames(mockUserRepo, nil)
+
+			service := NewPullRequestService(mockTx).
+				WithPullRequestRepo(mockPRRepo).
+				WithUserRepo(mockUserRepo).
+				WithTeamRepo(newMockTeamRules(tt.settings, tt.rules)).
+				WithReviewRepo(mockReviewRepo)
+
+			got, err := service.AddReviewer(context.Background(), "pr-1001", tt.userID)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedReviewers, got.Reviewers)
+				assert.Equal(t, tt.needMore, got.NeedMoreReviewers)
+			}
+
+			mockPRRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_RemoveReviewer(t *testing.T) {
+	openPR := &repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen}
+
+	tests := []struct {
+		name              string
+		userID            string
+		setupMocks        func(*MockPullRequestRepository, *MockUserRepository, *MockReviewRepository)
+		expectedError     bool
+		errorCode         ErrorCode
+		expectedReviewers []string
+		needMore          bool
+	}{
+		{
+			name:   "success: removed without replacement flags PR",
+			userID: "u2",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStateApproved, "u2", "u3"), nil)
+				rr.On("Unassign", mock.Anything, "pr-1001", "u2").Return(nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return p.Status == nil && *p.NeedMoreReviewers
+				})).Return(&repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen, NeedMoreReviewers: true}, nil)
+			},
+			expectedReviewers: []string{"u3"},
+			needMore:          true,
+		},
+		{
+			name:   "failure: reviewer not assigned",
+			userID: "u5",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotAssigned,
+		},
+		{
+			name:   "failure: PR already merged",
+			userID: "u2",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Status:   model.PRStatusMerged,
+				}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodePRMerged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockUserRepo := new(MockUserRepository)
+			mockReviewRepo := new(MockReviewRepository)
+
+			tt.setupMocks(mockPRRepo, mockUserRepo, mockReviewRepo)
+			withTeamNames(mockUserRepo, nil)
+
+			service := NewPullRequestService(mockTx).
+				WithPullRequestRepo(mockPRRepo).
+				WithUserRepo(mockUserRepo).
+				WithTeamRepo(newMockTeamSettings(nil)).
+				WithReviewRepo(mockReviewRepo)
+
+			got, err := service.RemoveReviewer(context.Background(), "pr-1001", tt.userID)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedReviewers, got.Reviewers)
+				assert.Equal(t, tt.needMore, got.NeedMoreReviewers)
+			}
+
+			mockPRRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_Transitions(t *testing.T) {
+	prWithStatus := func(status model.PRStatus) *repository.PullRequest {
+		return &repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Name: "feat: feature", Status: status}
+	}
+
+	tests := []struct {
+		name              string
+		transition        func(*PullRequestService) (*model.PullRequest, *Error)
+		setupMocks        func(*MockPullRequestRepository, *MockUserRepository, *MockReviewRepository)
+		expectedError     bool
+		errorCode         ErrorCode
+		expectedStatus    model.PRStatus
+		expectedReviewers []string
+		needMore          bool
+	}{
+		{
+			name: "success: mark ready assigns reviewers",
+			transition: func(s *PullRequestService) (*model.PullRequest, *Error) {
+				return s.MarkReady(context.Background(), "pr-1001")
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(prWithStatus(model.PRStatusDraft), nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("GetUserTeam", mock.Anything, "u1").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "reviewer1", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "reviewer2", IsActive: true, TeamName: "backend"},
+				}, nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u2", "u3"}).Return(nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return *p.Status == model.PRStatusOpen && !*p.NeedMoreReviewers
+				})).Return(prWithStatus(model.PRStatusOpen), nil)
+			},
+			expectedStatus:    model.PRStatusOpen,
+			expectedReviewers: []string{"u2", "u3"},
+		},
+		{
+			name: "success: reopen flags PR when team is short",
+			transition: func(s *PullRequestService) (*model.PullRequest, *Error) {
+				return s.ReopenPullRequest(context.Background(), "pr-1001")
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(prWithStatus(model.PRStatusClosed), nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{}, nil)
+				ur.On("GetUserTeam", mock.Anything, "u1").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "reviewer1", IsActive: true, TeamName: "backend"},
+				}, nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u2"}).Return(nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return *p.Status == model.PRStatusOpen && *p.NeedMoreReviewers
+				})).Return(&repository.PullRequest{
+					ID:                "pr-1001",
+					AuthorID:          "u1",
+					Status:            model.PRStatusOpen,
+					NeedMoreReviewers: true,
+				}, nil)
+			},
+			expectedStatus:    model.PRStatusOpen,
+			expectedReviewers: []string{"u2"},
+			needMore:          true,
+		},
+		{
+			name: "success: close releases reviewers",
+			transition: func(s *PullRequestService) (*model.PullRequest, *Error) {
+				return s.ClosePullRequest(context.Background(), "pr-1001")
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(prWithStatus(model.PRStatusOpen), nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStateApproved, "u2", "u3"), nil)
+				rr.On("UnassignMany", mock.Anything, []string{"pr-1001"}, []string{"u2", "u3"}).Return(nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return *p.Status == model.PRStatusClosed && !*p.NeedMoreReviewers
+				})).Return(prWithStatus(model.PRStatusClosed), nil)
+			},
+			expectedStatus:    model.PRStatusClosed,
+			expectedReviewers: []string{},
+		},
+		{
+			name: "failure: open PR cannot be reopened",
+			transition: func(s *PullRequestService) (*model.PullRequest, *Error) {
+				return s.ReopenPullRequest(context.Background(), "pr-1001")
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(prWithStatus(model.PRStatusOpen), nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidTransition,
+		},
+		{
+			name: "failure: merged PR cannot be closed",
+			transition: func(s *PullRequestService) (*model.PullRequest, *Error) {
+				return s.ClosePullRequest(context.Background(), "pr-1001")
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(prWithStatus(model.PRStatusMerged), nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidTransition,
+		},
+		{
+			name: "failure: closed PR cannot be marked ready",
+			transition: func(s *PullRequestService) (*model.PullRequest, *Error) {
+				return s.MarkReady(context.Background(), "pr-1001")
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(prWithStatus(model.PRStatusClosed), nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidTransition,
+		},
+		{
+			name: "failure: PR not found",
+			transition: func(s *PullRequestService) (*model.PullRequest, *Error) {
+				return s.ClosePullRequest(context.Background(), "pr-1001")
+			},
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockUserRepo := new(MockUserRepository)
+			mockReviewRepo := new(MockReviewRepository)
+
+			tt.setupMocks(mockPRRepo, mockUserRepo, mockReviewRepo)
+			withTeamNames(mockUserRepo, nil)
+
+			service := NewPullRequestService(mockTx).
+				WithPullRequestRepo(mockPRRepo).
+				WithUserRepo(mockUserRepo).
+				WithTeamRepo(newMockTeamSettings(nil)).
+				WithReviewRepo(mockReviewRepo).
+				WithReviewerSelector(NewRoundRobinSelector())
+
+			got, err := tt.transition(service)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedStatus, got.Status)
+				assert.Equal(t, tt.expectedReviewers, got.Reviewers)
+				assert.Equal(t, tt.needMore, got.NeedMoreReviewers)
+			}
+
+			mockPRRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_SubmitReview(t *testing.T) {
+	tests := []struct {
+		name            string
+		state           model.ReviewState
+		setupMocks      func(*MockPullRequestRepository, *MockReviewRepository)
+		expectedError   bool
+		errorCode       ErrorCode
+		expectedReviews []*model.Review
+	}{
+		{
+			name:  "success: approve",
+			state: model.ReviewStateApproved,
+			setupMocks: func(pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Status:   model.PRStatusOpen,
+				}, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{
+					{UserID: "u2", PullRequestID: "pr-1001", State: model.ReviewStatePending},
+					{UserID: "u3", PullRequestID: "pr-1001", State: model.ReviewStatePending},
+				}, nil)
+				rr.On("SetState", mock.Anything, "pr-1001", "u2", model.ReviewStateApproved).Return(nil)
+			},
+			expectedError: false,
+			expectedReviews: []*model.Review{
+				{UserID: "u2", State: model.ReviewStateApproved},
+				{UserID: "u3", State: model.ReviewStatePending},
+			},
+		},
+		{
+			name:  "failure: reviewer not assigned",
+			state: model.ReviewStateChangesRequested,
+			setupMocks: func(pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Status:   model.PRStatusOpen,
+				}, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u3"), nil)
+				rr.On("SetState", mock.Anything, "pr-1001", "u2", model.ReviewStateChangesRequested).Return(repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotAssigned,
+		},
+		{
+			name:  "failure: PR already merged",
+			state: model.ReviewStateApproved,
+			setupMocks: func(pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+					ID:       "pr-1001",
+					AuthorID: "u1",
+					Status:   model.PRStatusMerged,
+				}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodePRMerged,
+		},
+		{
+			name:          "failure: unknown state",
+			state:         "LGTM",
+			setupMocks:    func(pr *MockPullRequestRepository, rr *MockReviewRepository) {},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockUserRepo := new(MockUserRepository)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockReviewRepo := new(MockReviewRepository)
+
+			tt.setupMocks(mockPRRepo, mockReviewRepo)
+			withTeamNames(mockUserRepo, nil)
+
+			service := NewPullRequestService(mockTx).
+				WithUserRepo(mockUserRepo).
+				WithPullRequestRepo(mockPRRepo).
+				WithReviewRepo(mockReviewRepo)
+
+			got, err := service.SubmitReview(context.Background(), "pr-1001", "u2", tt.state)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedReviews, got.Reviews)
+				assert.Equal(t, []string{"u2", "u3"}, got.Reviewers)
+			}
+
+			mockPRRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_SubmitReview_ExternalReviewers(t *testing.T) {
+	mockTx := new(MockTransactor)
+	mockUserRepo := new(MockUserRepository)
+	mockPRRepo := new(MockPullRequestRepository)
+	mockReviewRepo := new(MockReviewRepository)
+
+	// f1 was drawn from a fallback team by an earlier call, the response still reports it
+	mockPRRepo.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{
+		ID:       "pr-1001",
+		AuthorID: "u1",
+		Status:   model.PRStatusOpen,
+	}, nil)
+	mockReviewRepo.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "f1", "u2"), nil)
+	mockReviewRepo.On("SetState", mock.Anything, "pr-1001", "u2", model.ReviewStateApproved).Return(nil)
+	mockUserRepo.On("GetTeamNames", mock.Anything, []string{"u1", "f1", "u2"}).Return(map[string]string{"u1": "backend", "f1": "frontend", "u2": "backend"}, nil)
+
+	service := NewPullRequestService(mockTx).
+		WithUserRepo(mockUserRepo).
+		WithPullRequestRepo(mockPRRepo).
+		WithReviewRepo(mockReviewRepo)
+
+	got, err := service.SubmitReview(context.Background(), "pr-1001", "u2", model.ReviewStateApproved)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"f1"}, got.ExternalReviewers)
+
+	mockUserRepo.AssertExpectations(t)
+	mockPRRepo.AssertExpectations(t)
+	mockReviewRepo.AssertExpectations(t)
+}
+
+func TestPullRequestService_TopUpReviewers(t *testing.T) {
+	tests := []struct {
+		name          string
+		settings      *repository.TeamSettings
+		setupMocks    func(*MockTeamRepository, *MockPullRequestRepository, *MockReviewRepository)
+		expectedError bool
+		errorCode     ErrorCode
+	}{
+		{
+			name: "success: missing reviewer assigned and flag cleared",
+			setupMocks: func(tr *MockTeamRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{
+					{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen, NeedMoreReviewers: true},
+				}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "backend").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "returned", IsActive: true, TeamName: "backend"},
+				}, nil)
+				pr.On("GetReviewers", mock.Anything, "pr-1001").Return([]string{"u2"}, nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u3"}).Return(nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return p.ID == "pr-1001" && p.NeedMoreReviewers != nil && !*p.NeedMoreReviewers
+				})).Return(&repository.PullRequest{ID: "pr-1001"}, nil)
+			},
+		},
+		{
+			name: "success: still not enough keeps flag",
+			setupMocks: func(tr *MockTeamRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{
+					{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen, NeedMoreReviewers: true},
+				}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "backend").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "returned", IsActive: true, TeamName: "backend"},
+				}, nil)
+				pr.On("GetReviewers", mock.Anything, "pr-1001").Return([]string{}, nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u3"}).Return(nil)
+			},
+		},
+		{
+			name: "success: fallback team fills the missing slot",
+			settings: &repository.TeamSettings{
+				TeamName:      "backend",
+				MinReviewers:  2,
+				MaxReviewers:  2,
+				FallbackTeams: []string{"frontend"},
+			},
+			setupMocks: func(tr *MockTeamRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{
+					{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen, NeedMoreReviewers: true},
+				}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "backend").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
+				}, nil)
+				pr.On("GetReviewers", mock.Anything, "pr-1001").Return([]string{"u2"}, nil)
+				rr.On("PeekTeamOpenReviews", mock.Anything, "frontend").Return(map[string]int{}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "frontend").Return([]*repository.User{
+					{ID: "f1", Username: "fred", IsActive: true, TeamName: "frontend"},
+				}, nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"f1"}).Return(nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return p.ID == "pr-1001" && p.NeedMoreReviewers != nil && !*p.NeedMoreReviewers
+				})).Return(&repository.PullRequest{ID: "pr-1001"}, nil)
+			},
+		},
+		{
+			name: "success: teams falling back to the team are topped up as well",
+			settings: &repository.TeamSettings{
+				TeamName:      "mobile",
+				MinReviewers:  1,
+				MaxReviewers:  1,
+				FallbackTeams: []string{"backend"},
+			},
+			setupMocks: func(tr *MockTeamRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				tr.On("GetTeamsFallingBackTo", mock.Anything, "backend").Return([]string{"mobile"}, nil)
+				pr.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{}, nil)
+
+				pr.On("GetNeedMoreReviewers", mock.Anything, "mobile").Return([]*repository.PullRequest{
+					{ID: "pr-2002", AuthorID: "m1", Status: model.PRStatusOpen, NeedMoreReviewers: true},
+				}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "mobile").Return([]*repository.User{
+					{ID: "m1", Username: "mike", IsActive: true, TeamName: "mobile"},
+				}, nil)
+				pr.On("GetReviewers", mock.Anything, "pr-2002").Return([]string{}, nil)
+				rr.On("PeekTeamOpenReviews", mock.Anything, "backend").Return(map[string]int{}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "backend").Return([]*repository.User{
+					{ID: "u3", Username: "returned", IsActive: true, TeamName: "backend"},
+				}, nil)
+				rr.On("Assign", mock.Anything, "pr-2002", []string{"u3"}).Return(nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return p.ID == "pr-2002" && p.NeedMoreReviewers != nil && !*p.NeedMoreReviewers
+				})).Return(&repository.PullRequest{ID: "pr-2002"}, nil)
+			},
+		},
+		{
+			name: "failure: dependent teams lookup failed",
+			setupMocks: func(tr *MockTeamRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				tr.On("GetTeamsFallingBackTo", mock.Anything, "backend").Return(nil, errors.New("db error"))
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUnspecified,
+		},
+		{
+			name: "success: flag cleared when PR already has min_reviewers",
+			setupMocks: func(tr *MockTeamRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{
+					{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen, NeedMoreReviewers: true},
+				}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "backend").Return([]*repository.User{
+					{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+					{ID: "u2", Username: "reviewer", IsActive: true, TeamName: "backend"},
+					{ID: "u3", Username: "second", IsActive: true, TeamName: "backend"},
+				}, nil)
+				pr.On("GetReviewers", mock.Anything, "pr-1001").Return([]string{"u2", "u3"}, nil)
+				pr.On("Patch", mock.Anything, mock.MatchedBy(func(p *repository.PullRequestPatch) bool {
+					return p.ID == "pr-1001" && p.NeedMoreReviewers != nil && !*p.NeedMoreReviewers
+				})).Return(&repository.PullRequest{ID: "pr-1001"}, nil)
+			},
+		},
+		{
+			name: "success: nothing flagged",
+			setupMocks: func(tr *MockTeamRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{}, nil)
+			},
+		},
+		{
+			name: "failure: repository error",
+			setupMocks: func(tr *MockTeamRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetNeedMoreReviewers", mock.Anything, "backend").Return(nil, errors.New("db error"))
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUnspecified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockTeamRepo := newMockTeamSettings(tt.settings)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockReviewRepo := new(MockReviewRepository)
+
+			tt.setupMocks(mockTeamRepo, mockPRRepo, mockReviewRepo)
+			mockTeamRepo.On("GetTeamsFallingBackTo", mock.Anything, "backend").Return([]string{}, nil).Maybe()
+
+			service := NewPullRequestService(mockTx).
+				WithTeamRepo(mockTeamRepo).
+				WithPullRequestRepo(mockPRRepo).
+				WithReviewRepo(mockReviewRepo).
+				WithReviewerSelector(NewRoundRobinSelector())
+
+			err := service.TopUpReviewers(context.Background(), "backend")
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+			} else {
+				assert.Nil(t, err)
+			}
+
+			mockTeamRepo.AssertExpectations(t)
+			mockPRRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_GetAuthorTeam(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockPullRequestRepository, *MockUserRepository)
+		expectedError bool
+		errorCode     ErrorCode
+		expected      string
+	}{
+		{
+			name: "success",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository) {
+				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{ID: "pr-1001", AuthorID: "u1"}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", TeamName: "backend"}, nil)
+			},
+			expected: "backend",
+		},
+		{
+			name: "failure: PR not found",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository) {
+				pr.On("Get", mock.Anything, "pr-1001").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+		{
+			name: "failure: author lookup failed",
+			setupMocks: func(pr *MockPullRequestRepository, ur *MockUserRepository) {
+				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{ID: "pr-1001", AuthorID: "u1"}, nil)
+				ur.On("Get", mock.Anything, "u1").Return(nil, errors.New("db error"))
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUnspecified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPRRepo := new(MockPullRequestRepository)
+			mockUserRepo := new(MockUserRepository)
+
+			tt.setupMocks(mockPRRepo, mockUserRepo)
+
+			service := NewPullRequestService(new(MockTransactor)).
+				WithPullRequestRepo(mockPRRepo).
+				WithUserRepo(mockUserRepo)
+
+			got, err := service.GetAuthorTeam(context.Background(), "pr-1001")
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+
+			mockPRRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_GetHistory(t *testing.T) {
+	createdAt := time.Date(2025, time.January, 6, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockPullRequestRepository, *MockPullRequestEventRepository)
+		expectedError bool
+		errorCode     ErrorCode
+		expected      []*model.PullRequestEvent
+	}{
+		{
+			name: "success",
+			setupMocks: func(pr *MockPullRequestRepository, er *MockPullRequestEventRepository) {
+				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{ID: "pr-1001", Status: model.PRStatusOpen}, nil)
+				er.On("List", mock.Anything, "pr-1001").Return([]*repository.PullRequestEvent{
+					{ID: 1, PullRequestID: "pr-1001", Type: model.PREventCreated, Actor: "admin", ToStatus: model.PRStatusOpen, CreatedAt: createdAt},
+					{ID: 2, PullRequestID: "pr-1001", Type: model.PREventReviewerAssigned, Actor: "admin", ReviewerID: "u2", Reason: model.PREventReasonAuto, CreatedAt: createdAt},
+					{ID: 3, PullRequestID: "pr-1001", Type: model.PREventReviewerReassigned, Actor: "admin", ReviewerID: "u2", NewReviewerID: "u3", Reason: model.PREventReasonManual, CreatedAt: createdAt},
+				}, nil)
+			},
+			expected: []*model.PullRequestEvent{
+				{ID: 1, Type: model.PREventCreated, Actor: "admin", ToStatus: model.PRStatusOpen, CreatedAt: createdAt},
+				{ID: 2, Type: model.PREventReviewerAssigned, Actor: "admin", ReviewerID: "u2", Reason: model.PREventReasonAuto, CreatedAt: createdAt},
+				{ID: 3, Type: model.PREventReviewerReassigned, Actor: "admin", ReviewerID: "u2", NewReviewerID: "u3", Reason: model.PREventReasonManual, CreatedAt: createdAt},
+			},
+		},
+		{
+			name: "failure: PR not found",
+			setupMocks: func(pr *MockPullRequestRepository, er *MockPullRequestEventRepository) {
+				pr.On("Get", mock.Anything, "pr-1001").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+		{
+			name: "failure: query failed",
+			setupMocks: func(pr *MockPullRequestRepository, er *MockPullRequestEventRepository) {
+				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{ID: "pr-1001"}, nil)
+				er.On("List", mock.Anything, "pr-1001").Return(nil, errors.New("db error"))
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUnspecified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPRRepo := new(MockPullRequestRepository)
+			mockEventRepo := new(MockPullRequestEventRepository)
+
+			tt.setupMocks(mockPRRepo, mockEventRepo)
+
+			service := NewPullRequestService(new(MockTransactor)).
+				WithPullRequestRepo(mockPRRepo).
+				WithEventRepo(mockEventRepo)
+
+			got, err := service.GetHistory(context.Background(), "pr-1001")
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, "pr-1001", got.PullRequestID)
+				assert.Equal(t, tt.expected, got.Events)
+			}
 
-			mockTx.AssertExpectations(t)
 			mockPRRepo.AssertExpectations(t)
+			mockEventRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPullRequestService_RecordsHistory(t *testing.T) {
+	ctx := audit.WithActor(context.Background(), "admin")
+
+	tests := []struct {
+		name       string
+		run        func(*PullRequestService) *Error
+		setupMocks func(*MockUserRepository, *MockPullRequestRepository, *MockReviewRepository)
+		expected   []*repository.PullRequestEvent
+	}{
+		{
+			name: "reassign",
+			run: func(s *PullRequestService) *Error {
+				_, err := s.ReassignPullRequest(ctx, "pr-1001", "u2", "", false)
+				return err
+			},
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				ur.On("GetUserTeam", mock.Anything, "u2").Return([]*repository.User{
+					{ID: "u1", IsActive: true, TeamName: "backend"},
+					{ID: "u2", IsActive: true, TeamName: "backend"},
+					{ID: "u3", IsActive: true, TeamName: "backend"},
+				}, nil)
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen}, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				rr.On("Unassign", mock.Anything, "pr-1001", "u2").Return(nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u3"}).Return(nil)
+				rr.On("RecordReassignments", mock.Anything, mock.Anything).Return(nil)
+			},
+			expected: []*repository.PullRequestEvent{
+				{PullRequestID: "pr-1001", Type: model.PREventReviewerReassigned, Actor: "admin", ReviewerID: "u2", NewReviewerID: "u3", Reason: model.PREventReasonManual},
+			},
+		},
+		{
+			name: "close",
+			run: func(s *PullRequestService) *Error {
+				_, err := s.ClosePullRequest(ctx, "pr-1001")
+				return err
+			},
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen}, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2", "u3"), nil)
+				rr.On("UnassignMany", mock.Anything, []string{"pr-1001"}, []string{"u2", "u3"}).Return(nil)
+				pr.On("Patch", mock.Anything, mock.Anything).Return(&repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusClosed}, nil)
+			},
+			expected: []*repository.PullRequestEvent{
+				{PullRequestID: "pr-1001", Type: model.PREventStatusChanged, Actor: "admin", FromStatus: model.PRStatusOpen, ToStatus: model.PRStatusClosed},
+				{PullRequestID: "pr-1001", Type: model.PREventReviewerUnassigned, Actor: "admin", ReviewerID: "u2", Reason: model.PREventReasonAuto},
+				{PullRequestID: "pr-1001", Type: model.PREventReviewerUnassigned, Actor: "admin", ReviewerID: "u3", Reason: model.PREventReasonAuto},
+			},
+		},
+		{
+			name: "review",
+			run: func(s *PullRequestService) *Error {
+				_, err := s.SubmitReview(ctx, "pr-1001", "u2", model.ReviewStateApproved)
+				return err
+			},
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("Get", mock.Anything, "pr-1001").Return(&repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusOpen}, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+				rr.On("SetState", mock.Anything, "pr-1001", "u2", model.ReviewStateApproved).Return(nil)
+			},
+			expected: []*repository.PullRequestEvent{
+				{PullRequestID: "pr-1001", Type: model.PREventReviewSubmitted, Actor: "admin", ReviewerID: "u2", ReviewState: model.ReviewStateApproved},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(MockUserRepository)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockReviewRepo := new(MockReviewRepository)
+			mockEventRepo := new(MockPullRequestEventRepository)
+
+			tt.setupMocks(mockUserRepo, mockPRRepo, mockReviewRepo)
+			withTeamNames(mockUserRepo, nil)
+			mockEventRepo.On("Append", mock.Anything, tt.expected).Return(nil).Once()
+
+			service := NewPullRequestService(new(MockTransactor)).
+				WithUserRepo(mockUserRepo).
+				WithTeamRepo(newMockTeamSettings(nil)).
+				WithPullRequestRepo(mockPRRepo).
+				WithReviewRepo(mockReviewRepo).
+				WithEventRepo(mockEventRepo).
+				WithReviewerSelector(NewRoundRobinSelector())
+
+			err := tt.run(service)
+
+			assert.Nil(t, err)
+			mockEventRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"github.com/yakoovad/avito-winter-2025/pkg/logger"
+	"go.uber.org/zap"
+	"slices"
+	"time"
+)
+
+// ProcessStalePullRequests Applies the stale policy of the author's team to every OPEN PR that had no activity
+// for longer than the team's stale_after_minutes at now. Each PR is handled in its own transaction, a failure
+// is logged and only skips that PR. Returns the number of handled PRs.
+func (p *PullRequestService) ProcessStalePullRequests(ctx context.Context, now time.Time) (int, *Error) {
+	l := logger.FromContext(ctx)
+
+	stale, err := p.prs.GetStale(ctx, now)
+	if err != nil {
+		l.Error("failed to get stale PRs", zap.Error(err))
+		return 0, NewError(ErrorCodeUnspecified, "failed to get stale PRs")
+	}
+
+	handled := 0
+	for _, s := range stale {
+		err = p.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+			return p.handleStale(txCtx, s)
+		})
+		if err != nil {
+			l.Error("failed to handle stale PR", zap.String("pull_request_id", s.ID), zap.Error(err))
+			continue
+		}
+		handled++
+	}
+
+	return handled, nil
+}
+
+// handleStale Adds a reviewer or replaces idle ones depending on the team's policy and escalates
+// when the policy is ESCALATE or could not change any reviewer. The recorded event counts as activity,
+// so the PR is handled again only after another stale_after_minutes without activity.
+func (p *PullRequestService) handleStale(ctx context.Context, s *repository.StalePullRequest) error {
+	l := logger.FromContext(ctx)
+
+	repoPR, err := p.prs.GetForUpdate(ctx, s.ID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return nil
+	case err != nil:
+		l.Error("failed to get PR", zap.String("pull_request_id", s.ID), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get PR")
+	}
+
+	// The PR could be merged or closed after the stale PRs were listed
+	if repoPR.Status != model.PRStatusOpen {
+		return nil
+	}
+
+	repoReviews, err := p.reviews.GetReviews(ctx, s.ID)
+	if err != nil {
+		l.Error("failed to get reviews", zap.String("pull_request_id", s.ID), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get reviews")
+	}
+	reviews := toModelReviews(repoReviews)
+	before := snapshotPullRequest(repoPR, reviews)
+
+	settings, err := loadTeamSettings(ctx, p.teams, s.TeamName)
+	if err != nil {
+		l.Error("failed to get team settings", zap.String("team_name", s.TeamName), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get team settings")
+	}
+
+	var events []*repository.PullRequestEvent
+	switch settings.StalePolicy {
+	case model.StalePolicyAddReviewer:
+		added, err := p.addStaleReviewer(ctx, repoPR, reviews, settings)
+		if err != nil {
+			return err
+		}
+		reviews = append(reviews, pendingReviews(added)...)
+		events = reviewerEvents(s.ID, model.PREventReviewerAssigned, added, model.PREventReasonStale)
+
+		if repoPR, err = p.syncNeedMoreReviewers(ctx, repoPR, settings, len(reviews)); err != nil {
+			return err
+		}
+	case model.StalePolicyReassignIdle:
+		if reviews, events, err = p.reassignIdleReviewers(ctx, repoPR, reviews, settings); err != nil {
+			return err
+		}
+	}
+
+	if len(events) == 0 {
+		l.Warn("stale PR escalated",
+			zap.String("pull_request_id", s.ID),
+			zap.String("team_name", s.TeamName),
+			zap.String("policy", string(settings.StalePolicy)),
+			zap.Time("last_activity_at", s.LastActivityAt))
+		events = []*repository.PullRequestEvent{{
+			PullRequestID: s.ID,
+			Type:          model.PREventEscalated,
+			Reason:        model.PREventReasonStale,
+		}}
+	} else {
+		l.Info("stale PR reviewers changed",
+			zap.String("pull_request_id", s.ID),
+			zap.String("policy", string(settings.StalePolicy)),
+			zap.Int("changes", len(events)))
+	}
+
+	if err = p.recordEvents(ctx, events...); err != nil {
+		return err
+	}
+
+	pr := &model.PullRequest{}
+	copyPullRequest(pr, repoPR, reviews)
+
+	return recordAudit(ctx, p.audits, model.AuditActionPRStale, model.AuditEntityPullRequest, s.ID, before, pr)
+}
+
+// addStaleReviewer Assigns one more reviewer from the team or its fallback teams, max_reviewers is not enforced.
+// Returns no reviewers when nobody is eligible.
+func (p *PullRequestService) addStaleReviewer(ctx context.Context, repoPR *repository.PullRequest, reviews []*model.Review, settings *model.TeamSettings) ([]string, error) {
+	l := logger.FromContext(ctx)
+
+	repoTeam, err := p.users.GetUserTeam(ctx, repoPR.AuthorID)
+	if err != nil {
+		l.Error("failed to get author team", zap.String("author_id", repoPR.AuthorID), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get author team")
+	}
+
+	rules, err := loadReviewerRules(ctx, p.teams, settings.TeamName)
+	if err != nil {
+		l.Error("failed to get reviewer rules", zap.String("team_name", settings.TeamName), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get reviewer rules")
+	}
+
+	reviewers := reviewerIDs(reviews)
+	selectCtx, _ := withReviewerRules(ctx, rules, repoPR.AuthorID, reviewers)
+
+	added, err := p.pickReviewers(selectCtx, settings, repoPR.AuthorID, reviewers, toModelUsers(repoTeam), 1)
+	if err == nil && len(added) == 0 {
+		added, err = p.pickFallbackReviewers(selectCtx, settings, append([]string{repoPR.AuthorID}, reviewers...), 1)
+	}
+	if err != nil {
+		l.Error("failed to select reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to select reviewers")
+	}
+	if len(added) == 0 {
+		return added, nil
+	}
+
+	if err = p.reviews.Assign(ctx, repoPR.ID, added); err != nil {
+		l.Error("failed to assign reviewers", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to assign reviewers")
+	}
+
+	return added, nil
+}
+
+// reassignIdleReviewers Replaces every reviewer who has not submitted a decision yet, the mandatory lead is kept.
+// Reviewers without a replacement stay assigned. Returns the updated reviews and one event per replacement.
+func (p *PullRequestService) reassignIdleReviewers(ctx context.Context, repoPR *repository.PullRequest, reviews []*model.Review, settings *model.TeamSettings) ([]*model.Review, []*repository.PullRequestEvent, error) {
+	l := logger.FromContext(ctx)
+
+	repoTeam, err := p.users.GetUserTeam(ctx, repoPR.AuthorID)
+	if err != nil {
+		l.Error("failed to get author team", zap.String("author_id", repoPR.AuthorID), zap.Error(err))
+		return nil, nil, NewError(ErrorCodeUnspecified, "failed to get author team")
+	}
+	team := toModelUsers(repoTeam)
+
+	rules, err := loadReviewerRules(ctx, p.teams, settings.TeamName)
+	if err != nil {
+		l.Error("failed to get reviewer rules", zap.String("team_name", settings.TeamName), zap.Error(err))
+		return nil, nil, NewError(ErrorCodeUnspecified, "failed to get reviewer rules")
+	}
+
+	exclude := append([]string{repoPR.AuthorID}, reviewerIDs(reviews)...)
+	reassignments := make([]*repository.Reassignment, 0)
+	events := make([]*repository.PullRequestEvent, 0)
+
+	for i, r := range reviews {
+		if r.State != model.ReviewStatePending {
+			continue
+		}
+		if settings.LeadMandatory && settings.LeadID == r.UserID && isActiveMember(team, r.UserID) {
+			continue
+		}
+
+		remaining := slices.DeleteFunc(reviewerIDs(reviews), func(id string) bool { return id == r.UserID })
+		selectCtx, _ := withReviewerRules(ctx, rules, repoPR.AuthorID, remaining)
+
+		replacement, err := p.selectReplacement(selectCtx, settings, settings.TeamName, exclude, remaining, team)
+		if err == nil && len(replacement) == 0 {
+			replacement, err = p.pickFallbackReviewers(selectCtx, settings, exclude, 1)
+		}
+		if err != nil {
+			l.Error("failed to select replacement reviewer", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+			return nil, nil, NewError(ErrorCodeUnspecified, "failed to select replacement reviewer")
+		}
+		if len(replacement) == 0 {
+			continue
+		}
+		newReviewer := replacement[0]
+
+		if err = p.reviews.Unassign(ctx, repoPR.ID, r.UserID); err != nil {
+			l.Error("failed to unassign idle reviewer", zap.String("pull_request_id", repoPR.ID), zap.String("user_id", r.UserID), zap.Error(err))
+			return nil, nil, NewError(ErrorCodeUnspecified, "failed to unassign idle reviewer")
+		}
+		if err = p.reviews.Assign(ctx, repoPR.ID, []string{newReviewer}); err != nil {
+			l.Error("failed to assign new reviewer", zap.String("pull_request_id", repoPR.ID), zap.String("new_reviewer", newReviewer), zap.Error(err))
+			return nil, nil, NewError(ErrorCodeUnspecified, "failed to assign new reviewer")
+		}
+
+		exclude = append(exclude, newReviewer)
+		reassignments = append(reassignments, &repository.Reassignment{
+			PullRequestID: repoPR.ID,
+			OldReviewerID: r.UserID,
+			NewReviewerID: &newReviewer,
+		})
+		events = append(events, &repository.PullRequestEvent{
+			PullRequestID: repoPR.ID,
+			Type:          model.PREventReviewerReassigned,
+			ReviewerID:    r.UserID,
+			NewReviewerID: newReviewer,
+			Reason:        model.PREventReasonStale,
+		})
+		reviews[i] = &model.Review{UserID: newReviewer, State: model.ReviewStatePending}
+	}
+
+	if len(reassignments) > 0 {
+		if err = p.reviews.RecordReassignments(ctx, reassignments); err != nil {
+			l.Error("failed to record reassignments", zap.String("pull_request_id", repoPR.ID), zap.Error(err))
+			return nil, nil, NewError(ErrorCodeUnspecified, "failed to record reassignments")
+		}
+	}
+
+	return reviews, events, nil
+}
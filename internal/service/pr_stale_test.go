@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestPullRequestService_ProcessStalePullRequests(t *testing.T) {
+	now := time.Date(2025, time.January, 6, 12, 0, 0, 0, time.UTC)
+	stale := []*repository.StalePullRequest{
+		{ID: "pr-1001", AuthorID: "u1", TeamName: "backend", LastActivityAt: now.Add(-3 * time.Hour)},
+	}
+	openPR := &repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Name: "feat: feature", Status: model.PRStatusOpen}
+	team := []*repository.User{
+		{ID: "u1", Username: "author", IsActive: true, TeamName: "backend"},
+		{ID: "u2", Username: "reviewer1", IsActive: true, TeamName: "backend"},
+		{ID: "u3", Username: "reviewer2", IsActive: true, TeamName: "backend"},
+		{ID: "u4", Username: "reviewer3", IsActive: true, TeamName: "backend"},
+	}
+	settings := func(policy model.StalePolicy) *repository.TeamSettings {
+		return &repository.TeamSettings{
+			TeamName:          "backend",
+			MinReviewers:      1,
+			MaxReviewers:      2,
+			FallbackTeams:     []string{},
+			StaleAfterMinutes: 60,
+			StalePolicy:       policy,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		settings       *repository.TeamSettings
+		setupMocks     func(*MockUserRepository, *MockPullRequestRepository, *MockReviewRepository)
+		expectedEvents []*repository.PullRequestEvent
+		expectedError  bool
+		errorCode      ErrorCode
+		handled        int
+	}{
+		{
+			name:     "escalate",
+			settings: settings(model.StalePolicyEscalate),
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetStale", mock.Anything, now).Return(stale, nil)
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2"), nil)
+			},
+			expectedEvents: []*repository.PullRequestEvent{
+				{PullRequestID: "pr-1001", Type: model.PREventEscalated, Reason: model.PREventReasonStale},
+			},
+			handled: 1,
+		},
+		{
+			name:     "add reviewer",
+			settings: settings(model.StalePolicyAddReviewer),
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetStale", mock.Anything, now).Return(stale, nil)
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2", "u3"), nil)
+				ur.On("GetUserTeam", mock.Anything, "u1").Return(team, nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u4"}).Return(nil)
+			},
+			expectedEvents: []*repository.PullRequestEvent{
+				{PullRequestID: "pr-1001", Type: model.PREventReviewerAssigned, ReviewerID: "u4", Reason: model.PREventReasonStale},
+			},
+			handled: 1,
+		},
+		{
+			name:     "add reviewer without candidates escalates",
+			settings: settings(model.StalePolicyAddReviewer),
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetStale", mock.Anything, now).Return(stale, nil)
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return(testReviews("pr-1001", model.ReviewStatePending, "u2", "u3", "u4"), nil)
+				ur.On("GetUserTeam", mock.Anything, "u1").Return(team, nil)
+			},
+			expectedEvents: []*repository.PullRequestEvent{
+				{PullRequestID: "pr-1001", Type: model.PREventEscalated, Reason: model.PREventReasonStale},
+			},
+			handled: 1,
+		},
+		{
+			name:     "reassign idle reviewers",
+			settings: settings(model.StalePolicyReassignIdle),
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetStale", mock.Anything, now).Return(stale, nil)
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(openPR, nil)
+				rr.On("GetReviews", mock.Anything, "pr-1001").Return([]*repository.Review{
+					{UserID: "u2", PullRequestID: "pr-1001", State: model.ReviewStatePending},
+					{UserID: "u3", PullRequestID: "pr-1001", State: model.ReviewStateApproved},
+				}, nil)
+				ur.On("GetUserTeam", mock.Anything, "u1").Return(team, nil)
+				rr.On("Unassign", mock.Anything, "pr-1001", "u2").Return(nil)
+				rr.On("Assign", mock.Anything, "pr-1001", []string{"u4"}).Return(nil)
+				rr.On("RecordReassignments", mock.Anything, []*repository.Reassignment{
+					{PullRequestID: "pr-1001", OldReviewerID: "u2", NewReviewerID: ptr("u4")},
+				}).Return(nil)
+			},
+			expectedEvents: []*repository.PullRequestEvent{
+				{PullRequestID: "pr-1001", Type: model.PREventReviewerReassigned, ReviewerID: "u2", NewReviewerID: "u4", Reason: model.PREventReasonStale},
+			},
+			handled: 1,
+		},
+		{
+			name:     "PR closed in the meantime is skipped",
+			settings: settings(model.StalePolicyEscalate),
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetStale", mock.Anything, now).Return(stale, nil)
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(&repository.PullRequest{ID: "pr-1001", AuthorID: "u1", Status: model.PRStatusClosed}, nil)
+			},
+			handled: 1,
+		},
+		{
+			name:     "failed PR is skipped",
+			settings: settings(model.StalePolicyEscalate),
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetStale", mock.Anything, now).Return(stale, nil)
+				pr.On("GetForUpdate", mock.Anything, "pr-1001").Return(nil, errors.New("db error"))
+			},
+			handled: 0,
+		},
+		{
+			name:     "failure: query failed",
+			settings: settings(model.StalePolicyEscalate),
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				pr.On("GetStale", mock.Anything, now).Return(nil, errors.New("db error"))
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUnspecified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := new(MockUserRepository)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockReviewRepo := new(MockReviewRepository)
+			mockEventRepo := new(MockPullRequestEventRepository)
+
+			tt.setupMocks(mockUserRepo, mockPRRepo, mockReviewRepo)
+			if tt.expectedEvents != nil {
+				mockEventRepo.On("Append", mock.Anything, tt.expectedEvents).Return(nil).Once()
+			}
+
+			service := NewPullRequestService(new(MockTransactor)).
+				WithUserRepo(mockUserRepo).
+				WithTeamRepo(newMockTeamSettings(tt.settings)).
+				WithPullRequestRepo(mockPRRepo).
+				WithReviewRepo(mockReviewRepo).
+				WithEventRepo(mockEventRepo).
+				WithReviewerSelector(NewRoundRobinSelector())
+
+			handled, err := service.ProcessStalePullRequests(context.Background(), now)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.handled, handled)
+			}
+
+			mockUserRepo.AssertExpectations(t)
+			mockPRRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+			mockEventRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// reviewerRules Team rules indexed for selection
+type reviewerRules struct {
+	// excluded maps an author to the users who may never review their PRs
+	excluded map[string][]string
+	// partners maps a reviewer to the users they must be paired with
+	partners map[string][]string
+}
+
+func newReviewerRules(rules []*repository.ReviewerRule) *reviewerRules {
+	r := &reviewerRules{
+		excluded: make(map[string][]string),
+		partners: make(map[string][]string),
+	}
+	for _, rule := range rules {
+		switch rule.Kind {
+		case model.ReviewerRuleExclude:
+			r.excluded[rule.TargetID] = append(r.excluded[rule.TargetID], rule.ReviewerID)
+		case model.ReviewerRulePair:
+			r.partners[rule.ReviewerID] = append(r.partners[rule.ReviewerID], rule.TargetID)
+		}
+	}
+	return r
+}
+
+func loadReviewerRules(ctx context.Context, teams repository.TeamRepository, teamName string) (*reviewerRules, error) {
+	rules, err := teams.GetReviewerRules(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	return newReviewerRules(rules), nil
+}
+
+func (r *reviewerRules) isExcluded(authorID, reviewerID string) bool {
+	return slices.Contains(r.excluded[authorID], reviewerID)
+}
+
+type reviewerRulesKey struct{}
+
+// prRules Rules applied while picking reviewers for a single PR.
+// assigned grows with every pick so that PAIR rules see the partners chosen so far.
+type prRules struct {
+	*reviewerRules
+	authorID   string
+	assigned   []string
+	eliminated map[string]string
+}
+
+// withReviewerRules Makes selectReviewers honor the rules for the PR of authorID that already has the assigned reviewers
+func withReviewerRules(ctx context.Context, rules *reviewerRules, authorID string, assigned []string) (context.Context, *prRules) {
+	pr := &prRules{
+		reviewerRules: rules,
+		authorID:      authorID,
+		assigned:      slices.Clone(assigned),
+		eliminated:    make(map[string]string),
+	}
+	return context.WithValue(ctx, reviewerRulesKey{}, pr), pr
+}
+
+func reviewerRulesFromContext(ctx context.Context) (*prRules, bool) {
+	rules, ok := ctx.Value(reviewerRulesKey{}).(*prRules)
+	return rules, ok
+}
+
+// allowed Reports whether the author's exclusions let the user review, recording the reason otherwise
+func (r *prRules) allowed(userID string) bool {
+	if !r.isExcluded(r.authorID, userID) {
+		return true
+	}
+	r.eliminated[userID] = fmt.Sprintf("%s %s: %s may not review PRs of %s", model.ReviewerRuleExclude, userID, userID, r.authorID)
+	return false
+}
+
+// needsPartner Reports whether the user has PAIR rules and none of their partners is assigned yet
+func (r *prRules) needsPartner(userID string) bool {
+	partners := r.partners[userID]
+	return len(partners) > 0 && !slices.ContainsFunc(partners, func(id string) bool { return slices.Contains(r.assigned, id) })
+}
+
+func (r *prRules) eliminatePairing(userID string) {
+	r.eliminated[userID] = fmt.Sprintf("%s %s: must be paired with one of %s", model.ReviewerRulePair, userID, strings.Join(r.partners[userID], ", "))
+}
+
+// explain Lists the rules that eliminated candidates, empty when no rule did
+func (r *prRules) explain() string {
+	reasons := make([]string, 0, len(r.eliminated))
+	for _, reason := range r.eliminated {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	return strings.Join(reasons, "; ")
+}
+
+// selectWithRules Picks reviewers one by one so that everybody with a PAIR rule is accompanied by a partner.
+// Such a user is only eligible when a partner is available and there is a slot left for them.
+func (p *PullRequestService) selectWithRules(ctx context.Context, teamName string, rules *prRules, candidates []*model.User, count int) ([]string, error) {
+	allowed := make([]*model.User, 0, len(candidates))
+	for _, c := range candidates {
+		if rules.allowed(c.ID) {
+			allowed = append(allowed, c)
+		}
+	}
+
+	// Without pairing constraints the selector sees the whole pool at once, as it does without rules
+	if !slices.ContainsFunc(allowed, func(u *model.User) bool { return rules.needsPartner(u.ID) }) {
+		if len(allowed) == 0 {
+			return []string{}, nil
+		}
+		picked, err := p.selectorFor(teamName).Select(ctx, teamName, allowed, count)
+		if err != nil {
+			return nil, err
+		}
+		rules.assigned = append(rules.assigned, picked...)
+		return picked, nil
+	}
+
+	picked := make([]string, 0, count)
+	for len(picked) < count {
+		eligible := make([]*model.User, 0, len(allowed))
+		for _, c := range allowed {
+			if rules.needsPartner(c.ID) && (count-len(picked) < 2 || len(partnersAmong(rules, c.ID, allowed)) == 0) {
+				rules.eliminatePairing(c.ID)
+				continue
+			}
+			eligible = append(eligible, c)
+		}
+		if len(eligible) == 0 {
+			break
+		}
+
+		next, err := p.selectorFor(teamName).Select(ctx, teamName, eligible, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(next) == 0 {
+			break
+		}
+
+		chosen := []string{next[0]}
+		if rules.needsPartner(next[0]) {
+			partner, err := p.selectorFor(teamName).Select(ctx, teamName, partnersAmong(rules, next[0], allowed), 1)
+			if err != nil {
+				return nil, err
+			}
+			if len(partner) == 0 {
+				break
+			}
+			chosen = append(chosen, partner[0])
+		}
+
+		for _, id := range chosen {
+			delete(rules.eliminated, id)
+		}
+		picked = append(picked, chosen...)
+		rules.assigned = append(rules.assigned, chosen...)
+		allowed = slices.DeleteFunc(allowed, func(u *model.User) bool { return slices.Contains(chosen, u.ID) })
+	}
+
+	return picked, nil
+}
+
+// partnersAmong Returns the candidates that satisfy the user's PAIR rule and need no partner of their own
+func partnersAmong(rules *prRules, userID string, candidates []*model.User) []*model.User {
+	partners := make([]*model.User, 0)
+	for _, c := range candidates {
+		if c.ID != userID && slices.Contains(rules.partners[userID], c.ID) && !rules.needsPartner(c.ID) {
+			partners = append(partners, c)
+		}
+	}
+	return partners
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// ReviewerSelector picks reviewers among already filtered candidates.
+// Candidates are always active team members who are neither the author nor already assigned.
+type ReviewerSelector interface {
+	// Select returns up to count user IDs from candidates ordered by preference
+	Select(ctx context.Context, team string, candidates []*model.User, count int) ([]string, error)
+}
+
+// ReviewLoader reports how many OPEN pull requests each team member currently reviews.
+// Implementations are expected to lock the team until the end of the transaction.
+type ReviewLoader interface {
+	CountTeamOpenReviews(ctx context.Context, teamName string) (map[string]int, error)
+}
+
+// reviewLoaderFunc Adapts a function to ReviewLoader
+type reviewLoaderFunc func(ctx context.Context, teamName string) (map[string]int, error)
+
+func (f reviewLoaderFunc) CountTeamOpenReviews(ctx context.Context, teamName string) (map[string]int, error) {
+	return f(ctx, teamName)
+}
+
+type reviewLoadKey struct{}
+
+// withReviewLoad Makes the least loaded selector use the given counts for the team instead of querying them.
+// Bulk operations count once, then keep the map up to date while they assign reviewers.
+// Counts attached earlier for other teams stay visible.
+func withReviewLoad(ctx context.Context, team string, count map[string]int) context.Context {
+	loads := map[string]map[string]int{team: count}
+	if parent, ok := ctx.Value(reviewLoadKey{}).(map[string]map[string]int); ok {
+		for name, c := range parent {
+			if name != team {
+				loads[name] = c
+			}
+		}
+	}
+	return context.WithValue(ctx, reviewLoadKey{}, loads)
+}
+
+func reviewLoadFromContext(ctx context.Context, team string) (map[string]int, bool) {
+	loads, ok := ctx.Value(reviewLoadKey{}).(map[string]map[string]int)
+	if !ok {
+		return nil, false
+	}
+	count, ok := loads[team]
+	return count, ok
+}
+
+type ReviewerStrategy string
+
+const (
+	ReviewerStrategyRandom      ReviewerStrategy = "random"
+	ReviewerStrategyRoundRobin  ReviewerStrategy = "round_robin"
+	ReviewerStrategyLeastLoaded ReviewerStrategy = "least_loaded"
+	ReviewerStrategyWeighted    ReviewerStrategy = "weighted"
+)
+
+type ReviewerSelectorConfig struct {
+	Strategy ReviewerStrategy
+	Seed     int64
+	// Weights is used only by the weighted strategy, users without weight get 1
+	Weights map[string]int
+}
+
+// NewReviewerSelector Builds a selector for the configured strategy, least loaded is the default
+func NewReviewerSelector(cfg ReviewerSelectorConfig, loader ReviewLoader) (ReviewerSelector, error) {
+	switch cfg.Strategy {
+	case ReviewerStrategyRandom:
+		return NewRandomSelector(cfg.Seed), nil
+	case ReviewerStrategyRoundRobin:
+		return NewRoundRobinSelector(), nil
+	case ReviewerStrategyLeastLoaded, "":
+		if loader == nil {
+			return nil, fmt.Errorf("strategy %q requires a review loader", ReviewerStrategyLeastLoaded)
+		}
+		return NewLeastLoadedSelector(loader, cfg.Seed), nil
+	case ReviewerStrategyWeighted:
+		return NewWeightedSelector(cfg.Weights, cfg.Seed), nil
+	default:
+		return nil, fmt.Errorf("unknown reviewer strategy %q", cfg.Strategy)
+	}
+}
+
+// lockedRand is a goroutine safe wrapper, rand.Rand itself is not safe for concurrent use
+type lockedRand struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func newLockedRand(seed int64) *lockedRand {
+	return &lockedRand{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (r *lockedRand) shuffle(ids []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rnd.Shuffle(len(ids), func(i, j int) {
+		ids[i], ids[j] = ids[j], ids[i]
+	})
+}
+
+func (r *lockedRand) float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rnd.Float64()
+}
+
+func candidateIDs(candidates []*model.User) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func firstN(ids []string, n int) []string {
+	if n < 0 {
+		n = 0
+	}
+	if len(ids) > n {
+		return ids[:n]
+	}
+	return ids
+}
+
+type randomSelector struct {
+	rnd *lockedRand
+}
+
+// NewRandomSelector Uniformly random selection, a fixed seed gives a reproducible sequence
+func NewRandomSelector(seed int64) ReviewerSelector {
+	return &randomSelector{rnd: newLockedRand(seed)}
+}
+
+func (s *randomSelector) Select(_ context.Context, _ string, candidates []*model.User, count int) ([]string, error) {
+	ids := candidateIDs(candidates)
+	s.rnd.shuffle(ids)
+	return firstN(ids, count), nil
+}
+
+type roundRobinSelector struct {
+	mu      sync.Mutex
+	offsets map[string]int
+}
+
+// NewRoundRobinSelector Rotates through team members ordered by user ID, keeping a cursor per team
+func NewRoundRobinSelector() ReviewerSelector {
+	return &roundRobinSelector{offsets: make(map[string]int)}
+}
+
+func (s *roundRobinSelector) Select(_ context.Context, team string, candidates []*model.User, count int) ([]string, error) {
+	ids := candidateIDs(candidates)
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	slices.Sort(ids)
+
+	s.mu.Lock()
+	offset := s.offsets[team] % len(ids)
+	picked := min(max(count, 0), len(ids))
+	s.offsets[team] = offset + picked
+	s.mu.Unlock()
+
+	res := make([]string, 0, picked)
+	for i := 0; i < picked; i++ {
+		res = append(res, ids[(offset+i)%len(ids)])
+	}
+	return res, nil
+}
+
+type leastLoadedSelector struct {
+	loader ReviewLoader
+	rnd    *lockedRand
+}
+
+// NewLeastLoadedSelector Prefers users with the fewest OPEN reviews, ties are broken randomly
+func NewLeastLoadedSelector(loader ReviewLoader, seed int64) ReviewerSelector {
+	return &leastLoadedSelector{loader: loader, rnd: newLockedRand(seed)}
+}
+
+func (s *leastLoadedSelector) Select(ctx context.Context, team string, candidates []*model.User, count int) ([]string, error) {
+	ids := candidateIDs(candidates)
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	load, ok := reviewLoadFromContext(ctx, team)
+	if !ok {
+		var err error
+		if load, err = s.loader.CountTeamOpenReviews(ctx, team); err != nil {
+			return nil, err
+		}
+	}
+
+	// Shuffle before the stable sort so that equally loaded users come out in random order
+	s.rnd.shuffle(ids)
+	sort.SliceStable(ids, func(i, j int) bool {
+		return load[ids[i]] < load[ids[j]]
+	})
+	return firstN(ids, count), nil
+}
+
+type weightedSelector struct {
+	weights map[string]int
+	rnd     *lockedRand
+}
+
+// NewWeightedSelector Random selection proportional to weights.
+// Users missing from weights get weight 1, users with non-positive weight are never picked.
+func NewWeightedSelector(weights map[string]int, seed int64) ReviewerSelector {
+	return &weightedSelector{weights: weights, rnd: newLockedRand(seed)}
+}
+
+func (s *weightedSelector) Select(_ context.Context, _ string, candidates []*model.User, count int) ([]string, error) {
+	type keyed struct {
+		id  string
+		key float64
+	}
+
+	// Efraimidis-Spirakis sampling without replacement: the largest u^(1/w) keys win
+	keys := make([]keyed, 0, len(candidates))
+	for _, c := range candidates {
+		w, ok := s.weights[c.ID]
+		if !ok {
+			w = 1
+		}
+		if w <= 0 {
+			continue
+		}
+		keys = append(keys, keyed{id: c.ID, key: math.Pow(s.rnd.float64(), 1/float64(w))})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].key > keys[j].key
+	})
+
+	ids := make([]string, 0, len(keys))
+	for _, k := range keys {
+		ids = append(ids, k.id)
+	}
+	return firstN(ids, count), nil
+}
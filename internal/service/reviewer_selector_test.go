@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+)
+
+func testCandidates(ids ...string) []*model.User {
+	users := make([]*model.User, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, &model.User{ID: id, IsActive: true, TeamName: "backend"})
+	}
+	return users
+}
+
+func TestRandomSelector_Select(t *testing.T) {
+	candidates := testCandidates("u1", "u2", "u3", "u4")
+
+	first, err := NewRandomSelector(42).Select(context.Background(), "backend", candidates, 2)
+	require.NoError(t, err)
+	second, err := NewRandomSelector(42).Select(context.Background(), "backend", candidates, 2)
+	require.NoError(t, err)
+
+	assert.Len(t, first, 2)
+	assert.NotEqual(t, first[0], first[1])
+	assert.Equal(t, first, second, "same seed must give the same picks")
+
+	all, err := NewRandomSelector(1).Select(context.Background(), "backend", candidates, 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u1", "u2", "u3", "u4"}, all)
+}
+
+func TestRoundRobinSelector_Select(t *testing.T) {
+	s := NewRoundRobinSelector()
+	candidates := testCandidates("u3", "u1", "u2")
+
+	tests := []struct {
+		name     string
+		team     string
+		count    int
+		expected []string
+	}{
+		{name: "first call starts from the lowest id", team: "backend", count: 2, expected: []string{"u1", "u2"}},
+		{name: "second call continues the rotation", team: "backend", count: 2, expected: []string{"u3", "u1"}},
+		{name: "other team has its own cursor", team: "frontend", count: 1, expected: []string{"u1"}},
+		{name: "count larger than candidates", team: "backend", count: 5, expected: []string{"u2", "u3", "u1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Select(context.Background(), tt.team, candidates, tt.count)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestLeastLoadedSelector_Select(t *testing.T) {
+	tests := []struct {
+		name          string
+		counts        map[string]int
+		loadErr       error
+		expected      []string
+		expectedError bool
+	}{
+		{
+			name:     "success: fewest open reviews first",
+			counts:   map[string]int{"u1": 3, "u2": 0, "u3": 1},
+			expected: []string{"u2", "u3"},
+		},
+		{
+			name:     "success: users without reviews count as zero",
+			counts:   map[string]int{"u1": 2, "u2": 1},
+			expected: []string{"u3", "u2"},
+		},
+		{
+			name:          "failure: loader error",
+			loadErr:       errors.New("db error"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := new(MockReviewRepository)
+			loader.On("CountTeamOpenReviews", mock.Anything, "backend").Return(tt.counts, tt.loadErr)
+
+			got, err := NewLeastLoadedSelector(loader, 1).Select(context.Background(), "backend", testCandidates("u1", "u2", "u3"), 2)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+
+			loader.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLeastLoadedSelector_RandomTieBreak(t *testing.T) {
+	loader := new(MockReviewRepository)
+	loader.On("CountTeamOpenReviews", mock.Anything, "backend").Return(map[string]int{"u4": 5}, nil)
+
+	s := NewLeastLoadedSelector(loader, 3)
+	candidates := testCandidates("u1", "u2", "u3", "u4")
+
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		got, err := s.Select(context.Background(), "backend", candidates, 1)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		seen[got[0]] = true
+	}
+
+	assert.False(t, seen["u4"], "most loaded user must never win a single slot")
+	assert.Len(t, seen, 3, "equally loaded users must all be picked eventually")
+}
+
+func TestLeastLoadedSelector_ContextLoad(t *testing.T) {
+	loader := new(MockReviewRepository)
+	loader.On("CountTeamOpenReviews", mock.Anything, "frontend").Return(map[string]int{"u1": 0, "u2": 5}, nil)
+
+	s := NewLeastLoadedSelector(loader, 1)
+	ctx := withReviewLoad(context.Background(), "backend", map[string]int{"u1": 4, "u2": 0})
+
+	got, err := s.Select(ctx, "backend", testCandidates("u1", "u2"), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, got, "counts from the context must be used for their team")
+
+	got, err = s.Select(ctx, "frontend", testCandidates("u1", "u2"), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, got, "other teams must still be counted by the loader")
+
+	ctx = withReviewLoad(ctx, "mobile", map[string]int{"u1": 0, "u2": 3})
+	got, err = s.Select(ctx, "backend", testCandidates("u1", "u2"), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, got, "counts of another team must not hide the earlier ones")
+
+	loader.AssertNumberOfCalls(t, "CountTeamOpenReviews", 1)
+}
+
+func TestWeightedSelector_Select(t *testing.T) {
+	s := NewWeightedSelector(map[string]int{"heavy": 50, "never": 0}, 7)
+	candidates := testCandidates("heavy", "light", "never")
+
+	hits := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		got, err := s.Select(context.Background(), "backend", candidates, 1)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		hits[got[0]]++
+	}
+
+	assert.Zero(t, hits["never"])
+	assert.Greater(t, hits["heavy"], hits["light"])
+}
+
+func TestNewReviewerSelector(t *testing.T) {
+	for _, strategy := range []ReviewerStrategy{"", ReviewerStrategyRandom, ReviewerStrategyRoundRobin, ReviewerStrategyLeastLoaded, ReviewerStrategyWeighted} {
+		s, err := NewReviewerSelector(ReviewerSelectorConfig{Strategy: strategy}, new(MockReviewRepository))
+		assert.NoError(t, err, strategy)
+		assert.NotNil(t, s, strategy)
+	}
+
+	_, err := NewReviewerSelector(ReviewerSelectorConfig{Strategy: "unknown"}, nil)
+	assert.Error(t, err)
+
+	_, err = NewReviewerSelector(ReviewerSelectorConfig{Strategy: ReviewerStrategyLeastLoaded}, nil)
+	assert.Error(t, err)
+}
+
+func TestNewPullRequestService_DefaultSelector(t *testing.T) {
+	mockReviewRepo := new(MockReviewRepository)
+	mockReviewRepo.On("CountTeamOpenReviews", mock.Anything, "backend").Return(map[string]int{"u1": 2, "u2": 0}, nil)
+
+	service := NewPullRequestService(new(MockTransactor)).
+		WithReviewRepo(mockReviewRepo)
+
+	got, err := service.selectorFor("backend").Select(context.Background(), "backend", testCandidates("u1", "u2"), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, got, "the default selector must be least loaded")
+
+	mockReviewRepo.AssertExpectations(t)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"github.com/yakoovad/avito-winter-2025/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type StatsService struct {
+	stats repository.StatsRepository
+	teams repository.TeamRepository
+}
+
+func NewStatsService() *StatsService {
+	return &StatsService{}
+}
+
+// GetReviewerStats Returns review work per user, every member of the filtered team is listed even without reviews
+func (s *StatsService) GetReviewerStats(ctx context.Context, filter *model.StatsFilter) (*model.ReviewerStatsReport, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("getting reviewer stats",
+		zap.String("team_name", filter.TeamName),
+		zap.Time("from", filter.From),
+		zap.Time("to", filter.To))
+
+	if err := s.checkFilter(ctx, filter); err != nil {
+		return nil, err
+	}
+
+	repoStats, err := s.stats.GetReviewerStats(ctx, &repository.StatsFilter{
+		TeamName: filter.TeamName,
+		From:     filter.From,
+		To:       filter.To,
+	})
+	if err != nil {
+		l.Error("failed to get reviewer stats", zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get reviewer stats")
+	}
+
+	res := &model.ReviewerStatsReport{Reviewers: make([]*model.ReviewerStats, 0, len(repoStats))}
+	for _, st := range repoStats {
+		res.Reviewers = append(res.Reviewers, &model.ReviewerStats{
+			UserID:         st.UserID,
+			Username:       st.Username,
+			TeamName:       st.TeamName,
+			Assigned:       st.Assigned,
+			Open:           st.Open,
+			Merged:         st.Merged,
+			ReassignedAway: st.ReassignedAway,
+		})
+	}
+
+	return res, nil
+}
+
+// GetPullRequestStats Returns created and merged PR counts, time to merge and reassignments per team and time bucket.
+// Buckets without created or merged PRs are omitted.
+func (s *StatsService) GetPullRequestStats(ctx context.Context, filter *model.PullRequestStatsFilter) (*model.PullRequestStatsReport, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("getting pull request stats",
+		zap.String("team_name", filter.TeamName),
+		zap.Time("from", filter.From),
+		zap.Time("to", filter.To),
+		zap.String("bucket", string(filter.Bucket)))
+
+	bucket := filter.Bucket
+	switch bucket {
+	case "":
+		bucket = model.StatsBucketDay
+	case model.StatsBucketDay, model.StatsBucketWeek, model.StatsBucketMonth:
+	default:
+		return nil, NewError(ErrorCodeInvalidBody, "bucket must be one of day, week, month")
+	}
+
+	if err := s.checkFilter(ctx, &filter.StatsFilter); err != nil {
+		return nil, err
+	}
+
+	repoStats, err := s.stats.GetPullRequestStats(ctx, &repository.StatsFilter{
+		TeamName: filter.TeamName,
+		From:     filter.From,
+		To:       filter.To,
+	}, bucket)
+	if err != nil {
+		l.Error("failed to get pull request stats", zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get pull request stats")
+	}
+
+	res := &model.PullRequestStatsReport{
+		Bucket:  bucket,
+		Buckets: make([]*model.PullRequestStats, 0, len(repoStats)),
+	}
+	for _, st := range repoStats {
+		res.Buckets = append(res.Buckets, &model.PullRequestStats{
+			TeamName:                 st.TeamName,
+			BucketStart:              st.BucketStart,
+			Created:                  st.Created,
+			Merged:                   st.Merged,
+			MedianTimeToMergeSeconds: st.MedianTimeToMerge,
+			P90TimeToMergeSeconds:    st.P90TimeToMerge,
+			AvgReassignments:         st.AvgReassignments,
+		})
+	}
+
+	return res, nil
+}
+
+// checkFilter Rejects inverted time ranges and unknown teams
+func (s *StatsService) checkFilter(ctx context.Context, filter *model.StatsFilter) *Error {
+	l := logger.FromContext(ctx)
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		return NewError(ErrorCodeInvalidBody, "to must be after from")
+	}
+
+	if filter.TeamName == "" {
+		return nil
+	}
+
+	_, err := s.teams.Get(ctx, filter.TeamName)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		l.Warn("team not found", zap.String("team_name", filter.TeamName))
+		return NewError(ErrorCodeNotFound, "team not found")
+	case err != nil:
+		l.Error("failed to get team", zap.String("team_name", filter.TeamName), zap.Error(err))
+		return NewError(ErrorCodeUnspecified, "failed to get team")
+	}
+
+	return nil
+}
+
+func (s *StatsService) WithStatsRepo(r repository.StatsRepository) *StatsService {
+	s.stats = r
+	return s
+}
+
+func (s *StatsService) WithTeamRepo(r repository.TeamRepository) *StatsService {
+	s.teams = r
+	return s
+}
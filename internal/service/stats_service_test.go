@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/yakoovad/avito-winter-2025/internal/model"
+	"github.com/yakoovad/avito-winter-2025/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestStatsService_GetReviewerStats(t *testing.T) {
+	from := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name          string
+		filter        *model.StatsFilter
+		setupMocks    func(*MockStatsRepository, *MockTeamRepository)
+		expectedError bool
+		errorCode     ErrorCode
+		expected      []*model.ReviewerStats
+	}{
+		{
+			name:   "success: team and time range",
+			filter: &model.StatsFilter{TeamName: "backend", From: from, To: to},
+			setupMocks: func(sr *MockStatsRepository, tr *MockTeamRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				sr.On("GetReviewerStats", mock.Anything, &repository.StatsFilter{TeamName: "backend", From: from, To: to}).Return([]*repository.ReviewerStats{
+					{UserID: "u1", Username: "alice", TeamName: "backend", Assigned: 5, Open: 2, Merged: 2, ReassignedAway: 1},
+					{UserID: "u2", Username: "bob", TeamName: "backend"},
+				}, nil)
+			},
+			expected: []*model.ReviewerStats{
+				{UserID: "u1", Username: "alice", TeamName: "backend", Assigned: 5, Open: 2, Merged: 2, ReassignedAway: 1},
+				{UserID: "u2", Username: "bob", TeamName: "backend"},
+			},
+		},
+		{
+			name:   "success: all users",
+			filter: &model.StatsFilter{},
+			setupMocks: func(sr *MockStatsRepository, tr *MockTeamRepository) {
+				sr.On("GetReviewerStats", mock.Anything, &repository.StatsFilter{}).Return([]*repository.ReviewerStats{}, nil)
+			},
+			expected: []*model.ReviewerStats{},
+		},
+		{
+			name:          "failure: inverted time range",
+			filter:        &model.StatsFilter{From: to, To: from},
+			setupMocks:    func(sr *MockStatsRepository, tr *MockTeamRepository) {},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name:   "failure: team not found",
+			filter: &model.StatsFilter{TeamName: "unknown"},
+			setupMocks: func(sr *MockStatsRepository, tr *MockTeamRepository) {
+				tr.On("Get", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+		{
+			name:   "failure: query failed",
+			filter: &model.StatsFilter{},
+			setupMocks: func(sr *MockStatsRepository, tr *MockTeamRepository) {
+				sr.On("GetReviewerStats", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUnspecified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStatsRepo := new(MockStatsRepository)
+			mockTeamRepo := new(MockTeamRepository)
+
+			tt.setupMocks(mockStatsRepo, mockTeamRepo)
+
+			service := NewStatsService().
+				WithStatsRepo(mockStatsRepo).
+				WithTeamRepo(mockTeamRepo)
+
+			got, err := service.GetReviewerStats(context.Background(), tt.filter)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expected, got.Reviewers)
+			}
+
+			mockStatsRepo.AssertExpectations(t)
+			mockTeamRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStatsService_GetPullRequestStats(t *testing.T) {
+	week := time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		filter         *model.PullRequestStatsFilter
+		setupMocks     func(*MockStatsRepository, *MockTeamRepository)
+		expectedError  bool
+		errorCode      ErrorCode
+		expectedBucket model.StatsBucket
+		expected       []*model.PullRequestStats
+	}{
+		{
+			name: "success: weekly buckets of a team",
+			filter: &model.PullRequestStatsFilter{
+				StatsFilter: model.StatsFilter{TeamName: "backend"},
+				Bucket:      model.StatsBucketWeek,
+			},
+			setupMocks: func(sr *MockStatsRepository, tr *MockTeamRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				sr.On("GetPullRequestStats", mock.Anything, &repository.StatsFilter{TeamName: "backend"}, model.StatsBucketWeek).Return([]*repository.PullRequestStats{
+					{TeamName: "backend", BucketStart: week, Created: 4, Merged: 2, MedianTimeToMerge: ptr(3600.0), P90TimeToMerge: ptr(7200.0), AvgReassignments: 0.5},
+					{TeamName: "backend", BucketStart: week.AddDate(0, 0, 7), Created: 1},
+				}, nil)
+			},
+			expectedBucket: model.StatsBucketWeek,
+			expected: []*model.PullRequestStats{
+				{TeamName: "backend", BucketStart: week, Created: 4, Merged: 2, MedianTimeToMergeSeconds: ptr(3600.0), P90TimeToMergeSeconds: ptr(7200.0), AvgReassignments: 0.5},
+				{TeamName: "backend", BucketStart: week.AddDate(0, 0, 7), Created: 1},
+			},
+		},
+		{
+			name:   "success: day is the default bucket",
+			filter: &model.PullRequestStatsFilter{},
+			setupMocks: func(sr *MockStatsRepository, tr *MockTeamRepository) {
+				sr.On("GetPullRequestStats", mock.Anything, &repository.StatsFilter{}, model.StatsBucketDay).Return([]*repository.PullRequestStats{}, nil)
+			},
+			expectedBucket: model.StatsBucketDay,
+			expected:       []*model.PullRequestStats{},
+		},
+		{
+			name:          "failure: unknown bucket",
+			filter:        &model.PullRequestStatsFilter{Bucket: "year"},
+			setupMocks:    func(sr *MockStatsRepository, tr *MockTeamRepository) {},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name: "failure: team not found",
+			filter: &model.PullRequestStatsFilter{
+				StatsFilter: model.StatsFilter{TeamName: "unknown"},
+			},
+			setupMocks: func(sr *MockStatsRepository, tr *MockTeamRepository) {
+				tr.On("Get", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStatsRepo := new(MockStatsRepository)
+			mockTeamRepo := new(MockTeamRepository)
+
+			tt.setupMocks(mockStatsRepo, mockTeamRepo)
+
+			service := NewStatsService().
+				WithStatsRepo(mockStatsRepo).
+				WithTeamRepo(mockTeamRepo)
+
+			got, err := service.GetPullRequestStats(context.Background(), tt.filter)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedBucket, got.Bucket)
+				assert.Equal(t, tt.expected, got.Buckets)
+			}
+
+			mockStatsRepo.AssertExpectations(t)
+			mockTeamRepo.AssertExpectations(t)
+		})
+	}
+}
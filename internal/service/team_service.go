@@ -8,6 +8,9 @@ import (
 	"github.com/yakoovad/avito-winter-2025/internal/repository"
 	"github.com/yakoovad/avito-winter-2025/pkg/logger"
 	"go.uber.org/zap"
+	"slices"
+	"strings"
+	"time"
 )
 
 type TeamService struct {
@@ -16,6 +19,9 @@ type TeamService struct {
 	users   repository.UserRepository
 	teams   repository.TeamRepository
 	reviews repository.ReviewRepository
+	audits  repository.AuditRepository
+
+	pullRequests *PullRequestService
 }
 
 func NewTeamService(tx db.Transactor) *TeamService {
@@ -42,11 +48,20 @@ func (t *TeamService) AddTeam(ctx context.Context, team *model.Team) *Error {
 		}
 
 		for _, user := range team.Members {
+			if user.Role == "" {
+				user.Role = model.UserRoleMiddle
+			}
+			if user.Skills == nil {
+				user.Skills = []string{}
+			}
+
 			if err = t.users.Upsert(txCtx, &repository.User{
 				ID:       user.UserID,
 				Username: user.Username,
 				IsActive: user.IsActive,
 				TeamName: team.Name,
+				Role:     user.Role,
+				Skills:   user.Skills,
 			}); err != nil {
 				l.Error("failed to upsert team member",
 					zap.String("team_name", team.Name),
@@ -58,12 +73,19 @@ func (t *TeamService) AddTeam(ctx context.Context, team *model.Team) *Error {
 
 		l.Debug("team added successfully", zap.String("team_name", team.Name))
 
-		return nil
+		return recordAudit(txCtx, t.audits, model.AuditActionTeamAdd, model.AuditEntityTeam, team.Name, nil, team)
 	})
 
 	var res *Error
 	errors.As(err, &res)
 
+	if res == nil {
+		// Members may have moved here together with their flagged PRs, try to complete them
+		if topUpErr := t.pullRequests.TopUpReviewers(ctx, team.Name); topUpErr != nil {
+			l.Warn("failed to top up reviewers", zap.String("team_name", team.Name), zap.Error(topUpErr))
+		}
+	}
+
 	return res
 }
 
@@ -93,6 +115,8 @@ func (t *TeamService) GetTeam(ctx context.Context, name string) (*model.Team, *E
 			UserID:   member.ID,
 			Username: member.Username,
 			IsActive: member.IsActive,
+			Role:     member.Role,
+			Skills:   member.Skills,
 		})
 	}
 
@@ -104,6 +128,401 @@ func (t *TeamService) GetTeam(ctx context.Context, name string) (*model.Team, *E
 	}, nil
 }
 
+// GetSettings Returns stored settings of the team or defaults when the team never configured them
+func (t *TeamService) GetSettings(ctx context.Context, name string) (*model.TeamSettings, *Error) {
+	l := logger.FromContext(ctx)
+	l.Debug("getting team settings", zap.String("team_name", name))
+
+	_, err := t.teams.Get(ctx, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		l.Warn("team not found", zap.String("team_name", name))
+		return nil, NewError(ErrorCodeNotFound, "team not found")
+	}
+	if err != nil {
+		l.Error("failed to get team", zap.String("team_name", name), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get team")
+	}
+
+	settings, err := loadTeamSettings(ctx, t.teams, name)
+	if err != nil {
+		l.Error("failed to get team settings", zap.String("team_name", name), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get team settings")
+	}
+
+	return settings, nil
+}
+
+// SetSettings Merges the update over the current settings of the team, or the defaults when it has none,
+// and stores the result. Omitted fields keep their value.
+func (t *TeamService) SetSettings(ctx context.Context, update *model.TeamSettingsUpdate) (*model.TeamSettings, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("setting team settings", zap.String("team_name", update.TeamName), zap.Any("settings", update))
+
+	settings := &model.TeamSettings{}
+
+	err := t.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		before, err := loadTeamSettings(txCtx, t.teams, update.TeamName)
+		if err != nil {
+			l.Error("failed to get team settings", zap.String("team_name", update.TeamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get team settings")
+		}
+
+		*settings = *before
+		settings.FallbackTeams = slices.Clone(before.FallbackTeams)
+		update.Apply(settings)
+
+		if err := checkTeamSettings(settings); err != nil {
+			return err
+		}
+
+		if settings.LeadID != "" {
+			lead, err := t.users.Get(txCtx, settings.LeadID)
+			if errors.Is(err, repository.ErrNotFound) {
+				l.Warn("team lead not found", zap.String("lead_id", settings.LeadID))
+				return NewError(ErrorCodeNotFound, "team lead not found")
+			}
+			if err != nil {
+				l.Error("failed to get team lead", zap.String("lead_id", settings.LeadID), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to get team lead")
+			}
+			if lead.TeamName != settings.TeamName {
+				l.Warn("team lead is not a team member",
+					zap.String("lead_id", settings.LeadID),
+					zap.String("team_name", settings.TeamName))
+				return NewError(ErrorCodeInvalidBody, "team lead must be a member of the team")
+			}
+		}
+
+		for _, name := range settings.FallbackTeams {
+			_, err := t.teams.Get(txCtx, name)
+			if errors.Is(err, repository.ErrNotFound) {
+				l.Warn("fallback team not found", zap.String("team_name", name))
+				return NewError(ErrorCodeNotFound, "fallback team not found: "+name)
+			}
+			if err != nil {
+				l.Error("failed to get fallback team", zap.String("team_name", name), zap.Error(err))
+				return NewError(ErrorCodeUnspecified, "failed to get fallback team")
+			}
+		}
+
+		repoSettings := &repository.TeamSettings{
+			TeamName:               settings.TeamName,
+			MinReviewers:           settings.MinReviewers,
+			MaxReviewers:           settings.MaxReviewers,
+			LeadMandatory:          settings.LeadMandatory,
+			AllowInactiveReviewers: settings.AllowInactiveReviewers,
+			RequiredApprovals:      settings.RequiredApprovals,
+			AllowExternalReviewers: settings.AllowExternalReviewers,
+			FallbackTeams:          settings.FallbackTeams,
+			RequireSenior:          settings.RequireSenior,
+			StaleAfterMinutes:      settings.StaleAfterMinutes,
+			StalePolicy:            settings.StalePolicy,
+		}
+		if settings.LeadID != "" {
+			repoSettings.LeadID = &settings.LeadID
+		}
+
+		err = t.teams.UpsertSettings(txCtx, repoSettings)
+		if errors.Is(err, repository.ErrNotFound) {
+			l.Warn("team not found", zap.String("team_name", settings.TeamName))
+			return NewError(ErrorCodeNotFound, "team not found")
+		}
+		if err != nil {
+			l.Error("failed to save team settings", zap.String("team_name", settings.TeamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to save team settings")
+		}
+
+		return recordAudit(txCtx, t.audits, model.AuditActionTeamSetSettings, model.AuditEntityTeam, settings.TeamName, before, settings)
+	})
+
+	var res *Error
+	errors.As(err, &res)
+
+	if res != nil {
+		return nil, res
+	}
+
+	l.Debug("team settings saved", zap.String("team_name", settings.TeamName))
+
+	return settings, nil
+}
+
+// checkTeamSettings Validates the merged settings, normalizing an empty fallback list and stale policy
+func checkTeamSettings(settings *model.TeamSettings) *Error {
+	if settings.MinReviewers < 0 {
+		return NewError(ErrorCodeInvalidBody, "min_reviewers must not be negative")
+	}
+	if settings.MaxReviewers < settings.MinReviewers {
+		return NewError(ErrorCodeInvalidBody, "max_reviewers must not be less than min_reviewers")
+	}
+	if settings.LeadMandatory && settings.LeadID == "" {
+		return NewError(ErrorCodeInvalidBody, "lead_id is required when lead is mandatory")
+	}
+	if settings.LeadMandatory && settings.MaxReviewers == 0 {
+		return NewError(ErrorCodeInvalidBody, "mandatory lead needs at least one reviewer slot")
+	}
+	if settings.RequireSenior && settings.MaxReviewers == 0 {
+		return NewError(ErrorCodeInvalidBody, "require_senior needs at least one reviewer slot")
+	}
+	if settings.RequiredApprovals < 0 {
+		return NewError(ErrorCodeInvalidBody, "required_approvals must not be negative")
+	}
+	if settings.RequiredApprovals > settings.MaxReviewers {
+		return NewError(ErrorCodeInvalidBody, "required_approvals must not exceed max_reviewers")
+	}
+	if settings.FallbackTeams == nil {
+		settings.FallbackTeams = []string{}
+	}
+	if settings.StaleAfterMinutes < 0 {
+		return NewError(ErrorCodeInvalidBody, "stale_after_minutes must not be negative")
+	}
+	if settings.StalePolicy == "" {
+		settings.StalePolicy = model.StalePolicyEscalate
+	}
+	for i, name := range settings.FallbackTeams {
+		if name == settings.TeamName {
+			return NewError(ErrorCodeInvalidBody, "team cannot be its own fallback team")
+		}
+		if slices.Contains(settings.FallbackTeams[:i], name) {
+			return NewError(ErrorCodeInvalidBody, "fallback_teams must not contain duplicates")
+		}
+	}
+	return nil
+}
+
+// GetAbsences Lists current and upcoming out-of-office windows of the team members
+func (t *TeamService) GetAbsences(ctx context.Context, name string) (*model.TeamAbsences, *Error) {
+	l := logger.FromContext(ctx)
+	l.Debug("getting team absences", zap.String("team_name", name))
+
+	_, err := t.teams.Get(ctx, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		l.Warn("team not found", zap.String("team_name", name))
+		return nil, NewError(ErrorCodeNotFound, "team not found")
+	}
+	if err != nil {
+		l.Error("failed to get team", zap.String("team_name", name), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get team")
+	}
+
+	repoAbsences, err := t.users.GetTeamAbsences(ctx, name, time.Now())
+	if err != nil {
+		l.Error("failed to get team absences", zap.String("team_name", name), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get team absences")
+	}
+
+	return &model.TeamAbsences{
+		TeamName: name,
+		Absences: toModelAbsences(repoAbsences),
+	}, nil
+}
+
+// GetRules Returns the reviewer rules of the team, empty when none are configured
+func (t *TeamService) GetRules(ctx context.Context, name string) (*model.TeamRules, *Error) {
+	l := logger.FromContext(ctx)
+	l.Debug("getting reviewer rules", zap.String("team_name", name))
+
+	_, err := t.teams.Get(ctx, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		l.Warn("team not found", zap.String("team_name", name))
+		return nil, NewError(ErrorCodeNotFound, "team not found")
+	}
+	if err != nil {
+		l.Error("failed to get team", zap.String("team_name", name), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get team")
+	}
+
+	repoRules, err := t.teams.GetReviewerRules(ctx, name)
+	if err != nil {
+		l.Error("failed to get reviewer rules", zap.String("team_name", name), zap.Error(err))
+		return nil, NewError(ErrorCodeUnspecified, "failed to get reviewer rules")
+	}
+
+	return &model.TeamRules{
+		TeamName: name,
+		Rules:    toModelRules(repoRules),
+	}, nil
+}
+
+// SetRules Replaces all reviewer rules of the team, an empty list removes them
+func (t *TeamService) SetRules(ctx context.Context, rules *model.TeamRules) (*model.TeamRules, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("setting reviewer rules", zap.String("team_name", rules.TeamName), zap.Int("count", len(rules.Rules)))
+
+	if rules.Rules == nil {
+		rules.Rules = []*model.ReviewerRule{}
+	}
+
+	repoRules := make([]*repository.ReviewerRule, 0, len(rules.Rules))
+	for i, r := range rules.Rules {
+		if slices.ContainsFunc(rules.Rules[:i], func(other *model.ReviewerRule) bool { return *other == *r }) {
+			return nil, NewError(ErrorCodeInvalidBody, "rules must not contain duplicates")
+		}
+		repoRules = append(repoRules, &repository.ReviewerRule{
+			Kind:       r.Kind,
+			ReviewerID: r.ReviewerID,
+			TargetID:   r.TargetID,
+		})
+	}
+
+	err := t.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		_, err := t.teams.Get(txCtx, rules.TeamName)
+		if errors.Is(err, repository.ErrNotFound) {
+			l.Warn("team not found", zap.String("team_name", rules.TeamName))
+			return NewError(ErrorCodeNotFound, "team not found")
+		}
+		if err != nil {
+			l.Error("failed to get team", zap.String("team_name", rules.TeamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get team")
+		}
+
+		repoBefore, err := t.teams.GetReviewerRules(txCtx, rules.TeamName)
+		if err != nil {
+			l.Error("failed to get reviewer rules", zap.String("team_name", rules.TeamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get reviewer rules")
+		}
+		before := &model.TeamRules{TeamName: rules.TeamName, Rules: toModelRules(repoBefore)}
+
+		err = t.teams.ReplaceReviewerRules(txCtx, rules.TeamName, repoRules)
+		if errors.Is(err, repository.ErrNotFound) {
+			l.Warn("rule refers to unknown user", zap.String("team_name", rules.TeamName))
+			return NewError(ErrorCodeNotFound, "user referenced by a rule not found")
+		}
+		if err != nil {
+			l.Error("failed to save reviewer rules", zap.String("team_name", rules.TeamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to save reviewer rules")
+		}
+
+		return recordAudit(txCtx, t.audits, model.AuditActionTeamSetRules, model.AuditEntityTeam, rules.TeamName, before, rules)
+	})
+
+	var res *Error
+	errors.As(err, &res)
+
+	if res != nil {
+		return nil, res
+	}
+
+	l.Debug("reviewer rules saved", zap.String("team_name", rules.TeamName))
+
+	return rules, nil
+}
+
+// DeactivateUsers Deactivates the listed team members and reassigns their OPEN reviews to the remaining
+// active members in one transaction. Reviews without a candidate are dropped and their PRs are flagged
+// with need_more_reviewers. Fails with NOT_FOUND when any of the users is not a member of the team.
+func (t *TeamService) DeactivateUsers(ctx context.Context, teamName string, userIDs []string) (*model.TeamDeactivation, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("deactivating team members", zap.String("team_name", teamName), zap.Int("count", len(userIDs)))
+
+	userIDs = slices.Compact(slices.Sorted(slices.Values(userIDs)))
+
+	res := &model.TeamDeactivation{
+		TeamName: teamName,
+	}
+
+	err := t.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		_, err := t.teams.Get(txCtx, teamName)
+		if errors.Is(err, repository.ErrNotFound) {
+			l.Warn("team not found", zap.String("team_name", teamName))
+			return NewError(ErrorCodeNotFound, "team not found")
+		}
+		if err != nil {
+			l.Error("failed to get team", zap.String("team_name", teamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get team")
+		}
+
+		repoUsers, err := t.users.SetTeamMembersActive(txCtx, teamName, userIDs, false)
+		if err != nil {
+			l.Error("failed to deactivate team members", zap.String("team_name", teamName), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to deactivate team members")
+		}
+
+		if len(repoUsers) != len(userIDs) {
+			missing := slices.DeleteFunc(slices.Clone(userIDs), func(id string) bool {
+				return slices.ContainsFunc(repoUsers, func(u *repository.User) bool { return u.ID == id })
+			})
+			l.Warn("users are not team members", zap.String("team_name", teamName), zap.Strings("user_ids", missing))
+			return NewError(ErrorCodeNotFound, "users not found in team: "+strings.Join(missing, ", "))
+		}
+
+		res.Users = toModelUsers(repoUsers)
+
+		if res.Reassignments, err = t.pullRequests.releaseReviewers(txCtx, teamName, userIDs); err != nil {
+			return err
+		}
+
+		return recordAudit(txCtx, t.audits, model.AuditActionTeamDeactivateUsers, model.AuditEntityTeam, teamName, nil, res)
+	})
+
+	var resErr *Error
+	errors.As(err, &resErr)
+
+	if resErr != nil {
+		return nil, resErr
+	}
+
+	l.Debug("team members deactivated",
+		zap.String("team_name", teamName),
+		zap.Int("users", len(res.Users)),
+		zap.Int("reassignments", len(res.Reassignments)))
+
+	return res, nil
+}
+
+// loadTeamSettings Reads team settings falling back to defaults when none are stored
+func loadTeamSettings(ctx context.Context, teams repository.TeamRepository, name string) (*model.TeamSettings, error) {
+	repoSettings, err := teams.GetSettings(ctx, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.DefaultTeamSettings(name), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &model.TeamSettings{
+		TeamName:               repoSettings.TeamName,
+		MinReviewers:           repoSettings.MinReviewers,
+		MaxReviewers:           repoSettings.MaxReviewers,
+		LeadMandatory:          repoSettings.LeadMandatory,
+		AllowInactiveReviewers: repoSettings.AllowInactiveReviewers,
+		RequiredApprovals:      repoSettings.RequiredApprovals,
+		AllowExternalReviewers: repoSettings.AllowExternalReviewers,
+		FallbackTeams:          repoSettings.FallbackTeams,
+		RequireSenior:          repoSettings.RequireSenior,
+		StaleAfterMinutes:      repoSettings.StaleAfterMinutes,
+		StalePolicy:            repoSettings.StalePolicy,
+	}
+	if repoSettings.LeadID != nil {
+		settings.LeadID = *repoSettings.LeadID
+	}
+	return settings, nil
+}
+
+func toModelRules(repoRules []*repository.ReviewerRule) []*model.ReviewerRule {
+	rules := make([]*model.ReviewerRule, 0, len(repoRules))
+	for _, r := range repoRules {
+		rules = append(rules, &model.ReviewerRule{
+			Kind:       r.Kind,
+			ReviewerID: r.ReviewerID,
+			TargetID:   r.TargetID,
+		})
+	}
+	return rules
+}
+
+func toModelAbsences(repoAbsences []*repository.Absence) []*model.Absence {
+	absences := make([]*model.Absence, 0, len(repoAbsences))
+	for _, a := range repoAbsences {
+		absences = append(absences, &model.Absence{
+			UserID:   a.UserID,
+			StartsAt: a.StartsAt,
+			EndsAt:   a.EndsAt,
+		})
+	}
+	return absences
+}
+
 func (t *TeamService) WithUserRepo(r repository.UserRepository) *TeamService {
 	t.users = r
 	return t
@@ -118,3 +537,13 @@ func (t *TeamService) WithReviewRepo(r repository.ReviewRepository) *TeamService
 	t.reviews = r
 	return t
 }
+
+func (t *TeamService) WithAuditRepo(r repository.AuditRepository) *TeamService {
+	t.audits = r
+	return t
+}
+
+func (t *TeamService) WithPullRequestService(pr *PullRequestService) *TeamService {
+	t.pullRequests = pr
+	return t
+}
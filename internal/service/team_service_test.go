@@ -99,7 +99,7 @@ func TestTeamService_AddTeam(t *testing.T) {
 	tests := []struct {
 		name          string
 		team          *model.Team
-		setupMocks    func(*MockTeamRepository, *MockUserRepository)
+		setupMocks    func(*MockTeamRepository, *MockUserRepository, *MockPullRequestRepository)
 		expectedError bool
 		errorCode     ErrorCode
 	}{
@@ -112,12 +112,39 @@ func TestTeamService_AddTeam(t *testing.T) {
 					{UserID: "user2", Username: "jane", IsActive: true},
 				},
 			},
-			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository, pr *MockPullRequestRepository) {
 				tr.On("Create", mock.Anything, mock.MatchedBy(func(t *repository.Team) bool {
 					return t.Name == "backend"
 				})).Return(nil)
 
 				ur.On("Upsert", mock.Anything, mock.Anything).Return(nil).Twice()
+
+				tr.On("GetTeamsFallingBackTo", mock.Anything, "backend").Return([]string{}, nil)
+				pr.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{}, nil)
+			},
+			expectedError: false,
+		},
+		{
+			name: "success: roles and skills",
+			team: &model.Team{
+				Name: "backend",
+				Members: []*model.TeamMember{
+					{UserID: "user1", Username: "john", IsActive: true, Role: model.UserRoleSenior, Skills: []string{"go", "postgres"}},
+					{UserID: "user2", Username: "jane", IsActive: true},
+				},
+			},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository, pr *MockPullRequestRepository) {
+				tr.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+				ur.On("Upsert", mock.Anything, &repository.User{
+					ID: "user1", Username: "john", IsActive: true, TeamName: "backend", Role: model.UserRoleSenior, Skills: []string{"go", "postgres"},
+				}).Return(nil)
+				ur.On("Upsert", mock.Anything, &repository.User{
+					ID: "user2", Username: "jane", IsActive: true, TeamName: "backend", Role: model.UserRoleMiddle, Skills: []string{},
+				}).Return(nil)
+
+				tr.On("GetTeamsFallingBackTo", mock.Anything, "backend").Return([]string{}, nil)
+				pr.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{}, nil)
 			},
 			expectedError: false,
 		},
@@ -127,7 +154,7 @@ func TestTeamService_AddTeam(t *testing.T) {
 				Name:    "existing-team",
 				Members: []*model.TeamMember{},
 			},
-			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository, pr *MockPullRequestRepository) {
 				tr.On("Create", mock.Anything, mock.Anything).Return(repository.ErrAlreadyExists)
 			},
 			expectedError: true,
@@ -141,7 +168,7 @@ func TestTeamService_AddTeam(t *testing.T) {
 					{UserID: "user1", Username: "john", IsActive: true},
 				},
 			},
-			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository, pr *MockPullRequestRepository) {
 				tr.On("Create", mock.Anything, mock.Anything).Return(nil)
 				ur.On("Upsert", mock.Anything, mock.Anything).Return(errors.New("db error"))
 			},
@@ -155,12 +182,18 @@ func TestTeamService_AddTeam(t *testing.T) {
 			mockTx := new(MockTransactor)
 			mockTeamRepo := new(MockTeamRepository)
 			mockUserRepo := new(MockUserRepository)
+			mockPRRepo := new(MockPullRequestRepository)
 
-			tt.setupMocks(mockTeamRepo, mockUserRepo)
+			tt.setupMocks(mockTeamRepo, mockUserRepo, mockPRRepo)
+
+			prService := NewPullRequestService(mockTx).
+				WithTeamRepo(mockTeamRepo).
+				WithPullRequestRepo(mockPRRepo)
 
 			service := NewTeamService(mockTx).
 				WithTeamRepo(mockTeamRepo).
-				WithUserRepo(mockUserRepo)
+				WithUserRepo(mockUserRepo).
+				WithPullRequestService(prService)
 
 			err := service.AddTeam(context.Background(), tt.team)
 
@@ -173,6 +206,625 @@ func TestTeamService_AddTeam(t *testing.T) {
 
 			mockTeamRepo.AssertExpectations(t)
 			mockUserRepo.AssertExpectations(t)
+			mockPRRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTeamService_GetSettings(t *testing.T) {
+	tests := []struct {
+		name             string
+		setupMocks       func(*MockTeamRepository)
+		expectedError    bool
+		errorCode        ErrorCode
+		expectedSettings *model.TeamSettings
+	}{
+		{
+			name: "success: stored settings",
+			setupMocks: func(tr *MockTeamRepository) {
+				lead := "u9"
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				tr.On("GetSettings", mock.Anything, "backend").Return(&repository.TeamSettings{
+					TeamName:      "backend",
+					MinReviewers:  1,
+					MaxReviewers:  3,
+					LeadID:        &lead,
+					LeadMandatory: true,
+				}, nil)
+			},
+			expectedSettings: &model.TeamSettings{
+				TeamName:      "backend",
+				MinReviewers:  1,
+				MaxReviewers:  3,
+				LeadID:        "u9",
+				LeadMandatory: true,
+			},
+		},
+		{
+			name: "success: defaults when not configured",
+			setupMocks: func(tr *MockTeamRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+			},
+			expectedSettings: model.DefaultTeamSettings("backend"),
+		},
+		{
+			name: "failure: team not found",
+			setupMocks: func(tr *MockTeamRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockTeamRepo := new(MockTeamRepository)
+
+			tt.setupMocks(mockTeamRepo)
+
+			service := NewTeamService(mockTx).
+				WithTeamRepo(mockTeamRepo)
+
+			got, err := service.GetSettings(context.Background(), "backend")
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedSettings, got)
+			}
+
+			mockTeamRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTeamService_SetSettings(t *testing.T) {
+	stored := &repository.TeamSettings{
+		TeamName:               "backend",
+		MinReviewers:           2,
+		MaxReviewers:           3,
+		AllowInactiveReviewers: true,
+		RequiredApprovals:      2,
+		FallbackTeams:          []string{"frontend"},
+		StaleAfterMinutes:      60,
+		StalePolicy:            model.StalePolicyAddReviewer,
+	}
+
+	tests := []struct {
+		name          string
+		update        *model.TeamSettingsUpdate
+		setupMocks    func(*MockTeamRepository, *MockUserRepository)
+		expectedError bool
+		errorCode     ErrorCode
+		errorMessage  string
+		expected      *model.TeamSettings
+	}{
+		{
+			name:   "success: omitted fields keep defaults",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", MinReviewers: ptr(1), MaxReviewers: ptr(3), LeadID: ptr("u9"), LeadMandatory: ptr(true)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+				ur.On("Get", mock.Anything, "u9").Return(&repository.User{ID: "u9", TeamName: "backend", IsActive: true}, nil)
+				tr.On("UpsertSettings", mock.Anything, mock.MatchedBy(func(s *repository.TeamSettings) bool {
+					return s.TeamName == "backend" && s.LeadID != nil && *s.LeadID == "u9" && s.MaxReviewers == 3 &&
+						s.RequiredApprovals == 1 && s.AllowInactiveReviewers
+				})).Return(nil)
+			},
+			expected: &model.TeamSettings{
+				TeamName: "backend", MinReviewers: 1, MaxReviewers: 3, LeadID: "u9", LeadMandatory: true,
+				AllowInactiveReviewers: true, RequiredApprovals: 1, FallbackTeams: []string{}, StalePolicy: model.StalePolicyEscalate,
+			},
+		},
+		{
+			name:   "success: omitted fields keep stored values",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", RequireSenior: ptr(true)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(stored, nil)
+				tr.On("Get", mock.Anything, "frontend").Return(&repository.Team{Name: "frontend"}, nil)
+				tr.On("UpsertSettings", mock.Anything, mock.Anything).Return(nil)
+			},
+			expected: &model.TeamSettings{
+				TeamName: "backend", MinReviewers: 2, MaxReviewers: 3, AllowInactiveReviewers: true, RequiredApprovals: 2,
+				FallbackTeams: []string{"frontend"}, RequireSenior: true, StaleAfterMinutes: 60, StalePolicy: model.StalePolicyAddReviewer,
+			},
+		},
+		{
+			name:   "success: empty values clear stored ones",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", FallbackTeams: &[]string{}, AllowInactiveReviewers: ptr(false), StaleAfterMinutes: ptr(0)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(stored, nil)
+				tr.On("UpsertSettings", mock.Anything, mock.MatchedBy(func(s *repository.TeamSettings) bool {
+					return len(s.FallbackTeams) == 0 && !s.AllowInactiveReviewers && s.StaleAfterMinutes == 0 && s.RequiredApprovals == 2
+				})).Return(nil)
+			},
+			expected: &model.TeamSettings{
+				TeamName: "backend", MinReviewers: 2, MaxReviewers: 3, RequiredApprovals: 2,
+				FallbackTeams: []string{}, StalePolicy: model.StalePolicyAddReviewer,
+			},
+		},
+		{
+			name:   "failure: negative min",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", MinReviewers: ptr(-1)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+			errorMessage:  "min_reviewers must not be negative",
+		},
+		{
+			name:   "failure: max less than min",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", MinReviewers: ptr(3), MaxReviewers: ptr(2)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+			errorMessage:  "max_reviewers must not be less than min_reviewers",
+		},
+		{
+			name:   "failure: max below stored min",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", MaxReviewers: ptr(1)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(stored, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name:   "failure: more required approvals than reviewers",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", MinReviewers: ptr(1), MaxReviewers: ptr(2), RequiredApprovals: ptr(3)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+			errorMessage:  "required_approvals must not exceed max_reviewers",
+		},
+		{
+			name:   "failure: negative required approvals",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", RequiredApprovals: ptr(-1)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+			errorMessage:  "required_approvals must not be negative",
+		},
+		{
+			name:   "failure: senior required without reviewer slots",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", MinReviewers: ptr(0), MaxReviewers: ptr(0), RequiredApprovals: ptr(0), RequireSenior: ptr(true)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name:   "failure: mandatory lead without lead",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", LeadMandatory: ptr(true)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name:   "failure: lead from another team",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", LeadID: ptr("u9")},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+				ur.On("Get", mock.Anything, "u9").Return(&repository.User{ID: "u9", TeamName: "frontend"}, nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name:   "failure: team is its own fallback",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", FallbackTeams: &[]string{"backend"}},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name:   "failure: fallback team not found",
+			update: &model.TeamSettingsUpdate{TeamName: "backend", FallbackTeams: &[]string{"unknown"}},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+				tr.On("Get", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+		{
+			name:   "failure: team not found",
+			update: &model.TeamSettingsUpdate{TeamName: "unknown", MinReviewers: ptr(1)},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository) {
+				tr.On("GetSettings", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+				tr.On("UpsertSettings", mock.Anything, mock.Anything).Return(repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockTeamRepo := new(MockTeamRepository)
+			mockUserRepo := new(MockUserRepository)
+
+			tt.setupMocks(mockTeamRepo, mockUserRepo)
+
+			service := NewTeamService(mockTx).
+				WithTeamRepo(mockTeamRepo).
+				WithUserRepo(mockUserRepo)
+
+			got, err := service.SetSettings(context.Background(), tt.update)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				if tt.errorMessage != "" {
+					assert.Equal(t, tt.errorMessage, err.Message)
+				}
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+
+			mockTeamRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTeamService_SetRules(t *testing.T) {
+	excludeRule := &model.ReviewerRule{Kind: model.ReviewerRuleExclude, ReviewerID: "u2", TargetID: "u1"}
+	pairRule := &model.ReviewerRule{Kind: model.ReviewerRulePair, ReviewerID: "j1", TargetID: "s1"}
+
+	tests := []struct {
+		name          string
+		rules         *model.TeamRules
+		setupMocks    func(*MockTeamRepository)
+		expectedError bool
+		errorCode     ErrorCode
+	}{
+		{
+			name:  "success",
+			rules: &model.TeamRules{TeamName: "backend", Rules: []*model.ReviewerRule{excludeRule, pairRule}},
+			setupMocks: func(tr *MockTeamRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				tr.On("GetReviewerRules", mock.Anything, "backend").Return([]*repository.ReviewerRule{}, nil)
+				tr.On("ReplaceReviewerRules", mock.Anything, "backend", []*repository.ReviewerRule{
+					{Kind: model.ReviewerRuleExclude, ReviewerID: "u2", TargetID: "u1"},
+					{Kind: model.ReviewerRulePair, ReviewerID: "j1", TargetID: "s1"},
+				}).Return(nil)
+			},
+		},
+		{
+			name:  "success: empty list clears rules",
+			rules: &model.TeamRules{TeamName: "backend"},
+			setupMocks: func(tr *MockTeamRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				tr.On("GetReviewerRules", mock.Anything, "backend").Return([]*repository.ReviewerRule{}, nil)
+				tr.On("ReplaceReviewerRules", mock.Anything, "backend", []*repository.ReviewerRule{}).Return(nil)
+			},
+		},
+		{
+			name: "failure: duplicate rule",
+			rules: &model.TeamRules{TeamName: "backend", Rules: []*model.ReviewerRule{
+				excludeRule,
+				{Kind: model.ReviewerRuleExclude, ReviewerID: "u2", TargetID: "u1"},
+			}},
+			setupMocks:    func(tr *MockTeamRepository) {},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name:  "failure: team not found",
+			rules: &model.TeamRules{TeamName: "unknown"},
+			setupMocks: func(tr *MockTeamRepository) {
+				tr.On("Get", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+		{
+			name:  "failure: unknown user",
+			rules: &model.TeamRules{TeamName: "backend", Rules: []*model.ReviewerRule{pairRule}},
+			setupMocks: func(tr *MockTeamRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				tr.On("GetReviewerRules", mock.Anything, "backend").Return([]*repository.ReviewerRule{}, nil)
+				tr.On("ReplaceReviewerRules", mock.Anything, "backend", mock.Anything).Return(repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockTeamRepo := new(MockTeamRepository)
+
+			tt.setupMocks(mockTeamRepo)
+
+			service := NewTeamService(mockTx).
+				WithTeamRepo(mockTeamRepo)
+
+			got, err := service.SetRules(context.Background(), tt.rules)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.rules, got)
+			}
+
+			mockTeamRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTeamService_DeactivateUsers(t *testing.T) {
+	backend := []*repository.User{
+		{ID: "u1", Username: "john", IsActive: false, TeamName: "backend"},
+		{ID: "u2", Username: "jane", IsActive: false, TeamName: "backend"},
+		{ID: "u3", Username: "jack", IsActive: true, TeamName: "backend"},
+		{ID: "u4", Username: "jill", IsActive: true, TeamName: "backend"},
+	}
+
+	tests := []struct {
+		name          string
+		teamName      string
+		userIDs       []string
+		setupMocks    func(*MockTeamRepository, *MockUserRepository, *MockPullRequestRepository, *MockReviewRepository)
+		expectedError bool
+		errorCode     ErrorCode
+		expected      *model.TeamDeactivation
+	}{
+		{
+			name:     "success",
+			teamName: "backend",
+			userIDs:  []string{"u2", "u1", "u2"},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				ur.On("SetTeamMembersActive", mock.Anything, "backend", []string{"u1", "u2"}, false).Return(backend[:2], nil)
+
+				pr.On("GetOpenReviewPRs", mock.Anything, []string{"u1", "u2"}).Return([]*repository.PullRequest{
+					{ID: "pr-1", AuthorID: "u3", Status: model.PRStatusOpen},
+					{ID: "pr-2", AuthorID: "u4", Status: model.PRStatusOpen},
+				}, nil)
+				pr.On("GetReviewersOf", mock.Anything, []string{"pr-1", "pr-2"}).Return(map[string][]string{
+					"pr-1": {"u1", "u2"},
+					"pr-2": {"u1"},
+				}, nil)
+				ur.On("GetTeamNames", mock.Anything, []string{"u3", "u4"}).Return(map[string]string{"u3": "backend", "u4": "backend"}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "backend").Return(backend, nil)
+				rr.On("CountTeamOpenReviews", mock.Anything, "backend").Return(map[string]int{}, nil)
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+				tr.On("GetReviewerRules", mock.Anything, "backend").Return([]*repository.ReviewerRule{}, nil)
+
+				rr.On("UnassignMany", mock.Anything, []string{"pr-1", "pr-2"}, []string{"u1", "u2"}).Return(nil)
+				rr.On("AssignMany", mock.Anything, []*repository.Review{
+					{UserID: "u4", PullRequestID: "pr-1"},
+					{UserID: "u3", PullRequestID: "pr-2"},
+				}).Return(nil)
+				pr.On("SetNeedMoreReviewers", mock.Anything, []string{"pr-1", "pr-2"}, true).Return(nil)
+				rr.On("RecordReassignments", mock.Anything, []*repository.Reassignment{
+					{PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: ptr("u4")},
+					{PullRequestID: "pr-1", OldReviewerID: "u2"},
+					{PullRequestID: "pr-2", OldReviewerID: "u1", NewReviewerID: ptr("u3")},
+				}).Return(nil)
+			},
+			expectedError: false,
+			expected: &model.TeamDeactivation{
+				TeamName: "backend",
+				Users: []*model.User{
+					{ID: "u1", Username: "john", IsActive: false, TeamName: "backend"},
+					{ID: "u2", Username: "jane", IsActive: false, TeamName: "backend"},
+				},
+				Reassignments: []*model.ReviewReassignment{
+					{PullRequestID: "pr-1", OldReviewerID: "u1", NewReviewerID: "u4", Status: model.ReassignmentStatusReassigned},
+					{PullRequestID: "pr-1", OldReviewerID: "u2", Status: model.ReassignmentStatusNoCandidate},
+					{PullRequestID: "pr-2", OldReviewerID: "u1", NewReviewerID: "u3", Status: model.ReassignmentStatusReassigned},
+				},
+			},
+		},
+		{
+			name:     "success: fallback team reviewer replaced under the author's team settings",
+			teamName: "mobile",
+			userIDs:  []string{"m1"},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				mobile := []*repository.User{
+					{ID: "m1", Username: "mike", IsActive: false, TeamName: "mobile"},
+					{ID: "m2", Username: "mary", IsActive: true, TeamName: "mobile"},
+				}
+				tr.On("Get", mock.Anything, "mobile").Return(&repository.Team{Name: "mobile"}, nil)
+				ur.On("SetTeamMembersActive", mock.Anything, "mobile", []string{"m1"}, false).Return(mobile[:1], nil)
+
+				pr.On("GetOpenReviewPRs", mock.Anything, []string{"m1"}).Return([]*repository.PullRequest{
+					{ID: "pr-3", AuthorID: "u3", Status: model.PRStatusOpen},
+				}, nil)
+				pr.On("GetReviewersOf", mock.Anything, []string{"pr-3"}).Return(map[string][]string{
+					"pr-3": {"u4", "m1"},
+				}, nil)
+				ur.On("GetTeamNames", mock.Anything, []string{"u3"}).Return(map[string]string{"u3": "backend"}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "mobile").Return(mobile, nil)
+				rr.On("CountTeamOpenReviews", mock.Anything, "mobile").Return(map[string]int{}, nil)
+
+				// m2 is excluded by a rule of the author's team, so the replacement comes from its fallback team
+				tr.On("GetSettings", mock.Anything, "backend").Return(&repository.TeamSettings{
+					TeamName:      "backend",
+					MinReviewers:  2,
+					MaxReviewers:  2,
+					FallbackTeams: []string{"frontend"},
+				}, nil)
+				tr.On("GetReviewerRules", mock.Anything, "backend").Return([]*repository.ReviewerRule{
+					{Kind: model.ReviewerRuleExclude, ReviewerID: "m2", TargetID: "u3"},
+				}, nil)
+				rr.On("PeekTeamOpenReviews", mock.Anything, "frontend").Return(map[string]int{}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "frontend").Return([]*repository.User{
+					{ID: "f1", Username: "fred", IsActive: true, TeamName: "frontend"},
+				}, nil)
+
+				rr.On("UnassignMany", mock.Anything, []string{"pr-3"}, []string{"m1"}).Return(nil)
+				rr.On("AssignMany", mock.Anything, []*repository.Review{
+					{UserID: "f1", PullRequestID: "pr-3"},
+				}).Return(nil)
+				pr.On("SetNeedMoreReviewers", mock.Anything, []string{}, true).Return(nil)
+				rr.On("RecordReassignments", mock.Anything, []*repository.Reassignment{
+					{PullRequestID: "pr-3", OldReviewerID: "m1", NewReviewerID: ptr("f1")},
+				}).Return(nil)
+			},
+			expectedError: false,
+			expected: &model.TeamDeactivation{
+				TeamName: "mobile",
+				Users: []*model.User{
+					{ID: "m1", Username: "mike", IsActive: false, TeamName: "mobile"},
+				},
+				Reassignments: []*model.ReviewReassignment{
+					{PullRequestID: "pr-3", OldReviewerID: "m1", NewReviewerID: "f1", Status: model.ReassignmentStatusReassigned},
+				},
+			},
+		},
+		{
+			name:     "success: no replacement but min_reviewers still met",
+			teamName: "backend",
+			userIDs:  []string{"u1"},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				ur.On("SetTeamMembersActive", mock.Anything, "backend", []string{"u1"}, false).Return(backend[:1], nil)
+
+				pr.On("GetOpenReviewPRs", mock.Anything, []string{"u1"}).Return([]*repository.PullRequest{
+					{ID: "pr-1", AuthorID: "u4", Status: model.PRStatusOpen},
+				}, nil)
+				pr.On("GetReviewersOf", mock.Anything, []string{"pr-1"}).Return(map[string][]string{
+					"pr-1": {"u1", "u3"},
+				}, nil)
+				ur.On("GetTeamNames", mock.Anything, []string{"u4"}).Return(map[string]string{"u4": "backend"}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "backend").Return(backend, nil)
+				rr.On("CountTeamOpenReviews", mock.Anything, "backend").Return(map[string]int{}, nil)
+				tr.On("GetSettings", mock.Anything, "backend").Return(&repository.TeamSettings{
+					TeamName:     "backend",
+					MinReviewers: 1,
+					MaxReviewers: 2,
+				}, nil)
+				tr.On("GetReviewerRules", mock.Anything, "backend").Return([]*repository.ReviewerRule{}, nil)
+
+				rr.On("UnassignMany", mock.Anything, []string{"pr-1"}, []string{"u1"}).Return(nil)
+				rr.On("AssignMany", mock.Anything, []*repository.Review{}).Return(nil)
+				pr.On("SetNeedMoreReviewers", mock.Anything, []string{}, true).Return(nil)
+				rr.On("RecordReassignments", mock.Anything, []*repository.Reassignment{
+					{PullRequestID: "pr-1", OldReviewerID: "u1"},
+				}).Return(nil)
+			},
+			expectedError: false,
+			expected: &model.TeamDeactivation{
+				TeamName: "backend",
+				Users: []*model.User{
+					{ID: "u1", Username: "john", IsActive: false, TeamName: "backend"},
+				},
+				Reassignments: []*model.ReviewReassignment{
+					{PullRequestID: "pr-1", OldReviewerID: "u1", Status: model.ReassignmentStatusNoCandidate},
+				},
+			},
+		},
+		{
+			name:     "team not found",
+			teamName: "unknown",
+			userIDs:  []string{"u1"},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				tr.On("Get", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+		{
+			name:     "user is not a team member",
+			teamName: "backend",
+			userIDs:  []string{"u1", "u9"},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				ur.On("SetTeamMembersActive", mock.Anything, "backend", []string{"u1", "u9"}, false).Return(backend[:1], nil)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+		{
+			name:     "batch assign failure",
+			teamName: "backend",
+			userIDs:  []string{"u1"},
+			setupMocks: func(tr *MockTeamRepository, ur *MockUserRepository, pr *MockPullRequestRepository, rr *MockReviewRepository) {
+				tr.On("Get", mock.Anything, "backend").Return(&repository.Team{Name: "backend"}, nil)
+				ur.On("SetTeamMembersActive", mock.Anything, "backend", []string{"u1"}, false).Return(backend[:1], nil)
+
+				pr.On("GetOpenReviewPRs", mock.Anything, []string{"u1"}).Return([]*repository.PullRequest{
+					{ID: "pr-1", AuthorID: "u3", Status: model.PRStatusOpen},
+				}, nil)
+				pr.On("GetReviewersOf", mock.Anything, []string{"pr-1"}).Return(map[string][]string{
+					"pr-1": {"u1"},
+				}, nil)
+				ur.On("GetTeamNames", mock.Anything, []string{"u3"}).Return(map[string]string{"u3": "backend"}, nil)
+				tr.On("GetTeamMembers", mock.Anything, "backend").Return(backend, nil)
+				rr.On("CountTeamOpenReviews", mock.Anything, "backend").Return(map[string]int{}, nil)
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+				tr.On("GetReviewerRules", mock.Anything, "backend").Return([]*repository.ReviewerRule{}, nil)
+
+				rr.On("UnassignMany", mock.Anything, []string{"pr-1"}, []string{"u1"}).Return(nil)
+				rr.On("AssignMany", mock.Anything, mock.Anything).Return(errors.New("db error"))
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeUnspecified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockTeamRepo := new(MockTeamRepository)
+			mockUserRepo := new(MockUserRepository)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockReviewRepo := new(MockReviewRepository)
+
+			tt.setupMocks(mockTeamRepo, mockUserRepo, mockPRRepo, mockReviewRepo)
+
+			prService := NewPullRequestService(mockTx).
+				WithUserRepo(mockUserRepo).
+				WithPullRequestRepo(mockPRRepo).
+				WithTeamRepo(mockTeamRepo).
+				WithReviewRepo(mockReviewRepo).
+				WithReviewerSelector(NewRoundRobinSelector())
+
+			service := NewTeamService(mockTx).
+				WithTeamRepo(mockTeamRepo).
+				WithUserRepo(mockUserRepo).
+				WithPullRequestService(prService)
+
+			got, err := service.DeactivateUsers(context.Background(), tt.teamName, tt.userIDs)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+
+			mockTeamRepo.AssertExpectations(t)
+			mockUserRepo.AssertExpectations(t)
+			mockPRRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/yakoovad/avito-winter-2025/internal/repository"
 	"github.com/yakoovad/avito-winter-2025/pkg/logger"
 	"go.uber.org/zap"
+	"sort"
 )
 
 type UserService struct {
@@ -16,6 +17,9 @@ type UserService struct {
 	users   repository.UserRepository
 	teams   repository.TeamRepository
 	reviews repository.ReviewRepository
+	audits  repository.AuditRepository
+
+	pullRequests *PullRequestService
 }
 
 func NewUserService(tx db.Transactor) *UserService {
@@ -24,32 +28,217 @@ func NewUserService(tx db.Transactor) *UserService {
 	}
 }
 
-func (u *UserService) SetUserIsActive(ctx context.Context, userID string, isActive bool) (*model.User, *Error) {
+// SetUserIsActive Changes the user's is_active flag.
+// When a user is deactivated with reassignOpenReviews, every OPEN review of the user is moved to another
+// active teammate in the same transaction, PRs without a candidate are flagged with need_more_reviewers.
+// Without it the user is only unassigned from PRs whose author's team forbids inactive reviewers.
+func (u *UserService) SetUserIsActive(ctx context.Context, userID string, isActive, reassignOpenReviews bool) (*model.UserActivation, *Error) {
 	l := logger.FromContext(ctx)
 
-	l.Info("setting user active status", zap.String("user_id", userID), zap.Bool("is_active", isActive))
+	l.Info("setting user active status",
+		zap.String("user_id", userID),
+		zap.Bool("is_active", isActive),
+		zap.Bool("reassign_open_reviews", reassignOpenReviews))
+
+	res := &model.UserActivation{}
+
+	err := u.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		before, err := u.users.Get(txCtx, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			l.Warn("user not found", zap.String("user_id", userID))
+			return NewError(ErrorCodeNotFound, "user not found")
+		}
+		if err != nil {
+			l.Error("failed to get user", zap.String("user_id", userID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get user")
+		}
+
+		user, err := u.users.Patch(txCtx, &repository.UserPatch{
+			ID:       userID,
+			IsActive: &isActive,
+		})
+		if errors.Is(err, repository.ErrNotFound) {
+			l.Warn("user not found", zap.String("user_id", userID))
+			return NewError(ErrorCodeNotFound, "user not found")
+		}
+		if err != nil {
+			l.Error("failed to patch user", zap.String("user_id", userID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to update user")
+		}
 
-	user, err := u.users.Patch(ctx, &repository.UserPatch{
-		ID:       userID,
-		IsActive: &isActive,
+		res.User = &model.User{
+			ID:             user.ID,
+			Username:       user.Username,
+			IsActive:       user.IsActive,
+			TeamName:       user.TeamName,
+			Role:           user.Role,
+			Skills:         user.Skills,
+			MaxOpenReviews: user.MaxOpenReviews,
+		}
+
+		switch {
+		case !isActive && reassignOpenReviews:
+			if res.Reassignments, err = u.pullRequests.releaseReviewers(txCtx, user.TeamName, []string{user.ID}); err != nil {
+				return err
+			}
+		case !isActive:
+			if err = u.pullRequests.unassignDeactivated(txCtx, user.ID); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(txCtx, u.audits, model.AuditActionUserSetIsActive, model.AuditEntityUser, userID, toModelUsers([]*repository.User{before})[0], res)
 	})
-	if errors.Is(err, repository.ErrNotFound) {
-		l.Warn("user not found", zap.String("user_id", userID))
-		return nil, NewError(ErrorCodeNotFound, "user not found")
-	}
-	if err != nil {
-		l.Error("failed to patch user", zap.String("user_id", userID), zap.Error(err))
-		return nil, NewError(ErrorCodeUnspecified, "failed to update user")
+
+	var resErr *Error
+	errors.As(err, &resErr)
+
+	if resErr != nil {
+		return nil, resErr
 	}
 
 	l.Debug("user active status updated successfully", zap.String("user_id", userID), zap.Bool("is_active", isActive))
 
-	return &model.User{
-		ID:       user.ID,
-		Username: user.Username,
-		IsActive: user.IsActive,
-		TeamName: user.TeamName,
-	}, nil
+	if res.IsActive {
+		// A returning teammate may complete PRs that were created with too few reviewers.
+		// The top-up is best effort and must not fail the activation itself.
+		if topUpErr := u.pullRequests.TopUpReviewers(ctx, res.TeamName); topUpErr != nil {
+			l.Warn("failed to top up reviewers", zap.String("team_name", res.TeamName), zap.Error(topUpErr))
+		}
+	}
+
+	return res, nil
+}
+
+// SetAvailability Replaces all out-of-office windows of the user, an empty list clears them.
+// The user is not picked as reviewer during the windows, is_active stays as it is.
+func (u *UserService) SetAvailability(ctx context.Context, availability *model.UserAvailability) (*model.UserAvailability, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("setting user availability", zap.String("user_id", availability.UserID), zap.Int("absences", len(availability.Absences)))
+
+	if availability.Absences == nil {
+		availability.Absences = []*model.Absence{}
+	}
+
+	sort.Slice(availability.Absences, func(i, j int) bool {
+		return availability.Absences[i].StartsAt.Before(availability.Absences[j].StartsAt)
+	})
+
+	repoAbsences := make([]*repository.Absence, 0, len(availability.Absences))
+	for i, a := range availability.Absences {
+		if !a.EndsAt.After(a.StartsAt) {
+			return nil, NewError(ErrorCodeInvalidBody, "absence must end after it starts")
+		}
+		if i > 0 && a.StartsAt.Before(availability.Absences[i-1].EndsAt) {
+			return nil, NewError(ErrorCodeInvalidBody, "absences must not overlap")
+		}
+
+		a.UserID = availability.UserID
+		repoAbsences = append(repoAbsences, &repository.Absence{
+			UserID:   availability.UserID,
+			StartsAt: a.StartsAt,
+			EndsAt:   a.EndsAt,
+		})
+	}
+
+	err := u.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		// clearing the absences of an unknown user deletes nothing, so existence is checked up front
+		_, err := u.users.Get(txCtx, availability.UserID)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			l.Warn("user not found", zap.String("user_id", availability.UserID))
+			return NewError(ErrorCodeNotFound, "user not found")
+		case err != nil:
+			l.Error("failed to get user", zap.String("user_id", availability.UserID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get user")
+		}
+
+		repoBefore, err := u.users.GetAbsences(txCtx, availability.UserID)
+		if err != nil {
+			l.Error("failed to get absences", zap.String("user_id", availability.UserID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get absences")
+		}
+		before := &model.UserAvailability{UserID: availability.UserID, Absences: toModelAbsences(repoBefore)}
+
+		err = u.users.ReplaceAbsences(txCtx, availability.UserID, repoAbsences)
+		if errors.Is(err, repository.ErrNotFound) {
+			l.Warn("user not found", zap.String("user_id", availability.UserID))
+			return NewError(ErrorCodeNotFound, "user not found")
+		}
+		if err != nil {
+			l.Error("failed to save absences", zap.String("user_id", availability.UserID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to save absences")
+		}
+
+		return recordAudit(txCtx, u.audits, model.AuditActionUserSetAvailability, model.AuditEntityUser, availability.UserID, before, availability)
+	})
+
+	var res *Error
+	errors.As(err, &res)
+
+	if res != nil {
+		return nil, res
+	}
+
+	l.Debug("user availability updated", zap.String("user_id", availability.UserID))
+
+	return availability, nil
+}
+
+// SetMaxOpenReviews Overrides the user's cap of OPEN reviews, nil falls back to the global default.
+// Lowering the cap keeps reviews that are already assigned.
+func (u *UserService) SetMaxOpenReviews(ctx context.Context, userID string, limit *int) (*model.User, *Error) {
+	l := logger.FromContext(ctx)
+	l.Info("setting user review cap", zap.String("user_id", userID), zap.Any("max_open_reviews", limit))
+
+	if limit != nil && *limit < 1 {
+		return nil, NewError(ErrorCodeInvalidBody, "max_open_reviews must be positive")
+	}
+
+	res := &model.User{}
+
+	err := u.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		before, err := u.users.Get(txCtx, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			l.Warn("user not found", zap.String("user_id", userID))
+			return NewError(ErrorCodeNotFound, "user not found")
+		}
+		if err != nil {
+			l.Error("failed to get user", zap.String("user_id", userID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to get user")
+		}
+
+		user, err := u.users.SetMaxOpenReviews(txCtx, userID, limit)
+		if errors.Is(err, repository.ErrNotFound) {
+			l.Warn("user not found", zap.String("user_id", userID))
+			return NewError(ErrorCodeNotFound, "user not found")
+		}
+		if err != nil {
+			l.Error("failed to update review cap", zap.String("user_id", userID), zap.Error(err))
+			return NewError(ErrorCodeUnspecified, "failed to update user")
+		}
+
+		res.ID = user.ID
+		res.Username = user.Username
+		res.IsActive = user.IsActive
+		res.TeamName = user.TeamName
+		res.Role = user.Role
+		res.Skills = user.Skills
+		res.MaxOpenReviews = user.MaxOpenReviews
+
+		return recordAudit(txCtx, u.audits, model.AuditActionUserSetMaxOpenReviews, model.AuditEntityUser, userID, toModelUsers([]*repository.User{before})[0], res)
+	})
+
+	var resErr *Error
+	errors.As(err, &resErr)
+
+	if resErr != nil {
+		return nil, resErr
+	}
+
+	l.Debug("user review cap updated", zap.String("user_id", userID))
+
+	return res, nil
 }
 
 func (u *UserService) WithUserRepo(userRepo repository.UserRepository) *UserService {
@@ -66,3 +255,13 @@ func (u *UserService) WithReviewRepo(reviewRepo repository.ReviewRepository) *Us
 	u.reviews = reviewRepo
 	return u
 }
+
+func (u *UserService) WithAuditRepo(auditRepo repository.AuditRepository) *UserService {
+	u.audits = auditRepo
+	return u
+}
+
+func (u *UserService) WithPullRequestService(pr *PullRequestService) *UserService {
+	u.pullRequests = pr
+	return u
+}
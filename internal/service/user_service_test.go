@@ -8,6 +8,7 @@ import (
 	"github.com/yakoovad/avito-winter-2025/internal/model"
 	"github.com/yakoovad/avito-winter-2025/internal/repository"
 	"testing"
+	"time"
 )
 
 func TestUserService_SetUserIsActive(t *testing.T) {
@@ -15,16 +16,19 @@ func TestUserService_SetUserIsActive(t *testing.T) {
 		name          string
 		userID        string
 		isActive      bool
-		setupMocks    func(*MockUserRepository)
+		reassign      bool
+		setupMocks    func(*MockUserRepository, *MockPullRequestRepository, *MockTeamRepository, *MockReviewRepository)
 		expectedError bool
 		errorCode     ErrorCode
 		expectedUser  *model.User
+		expectedMoves []*model.ReviewReassignment
 	}{
 		{
 			name:     "success activate",
 			userID:   "user1",
 			isActive: true,
-			setupMocks: func(ur *MockUserRepository) {
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, tr *MockTeamRepository, rr *MockReviewRepository) {
+				ur.On("Get", mock.Anything, "user1").Return(&repository.User{ID: "user1", Username: "john", IsActive: false, TeamName: "backend"}, nil)
 				isActive := true
 				ur.On("Patch", mock.Anything, &repository.UserPatch{
 					ID:       "user1",
@@ -35,6 +39,9 @@ func TestUserService_SetUserIsActive(t *testing.T) {
 					IsActive: true,
 					TeamName: "backend",
 				}, nil)
+
+				tr.On("GetTeamsFallingBackTo", mock.Anything, "backend").Return([]string{}, nil)
+				pr.On("GetNeedMoreReviewers", mock.Anything, "backend").Return([]*repository.PullRequest{}, nil)
 			},
 			expectedError: false,
 			expectedUser: &model.User{
@@ -48,7 +55,8 @@ func TestUserService_SetUserIsActive(t *testing.T) {
 			name:     "success deactivate",
 			userID:   "user1",
 			isActive: false,
-			setupMocks: func(ur *MockUserRepository) {
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, tr *MockTeamRepository, rr *MockReviewRepository) {
+				ur.On("Get", mock.Anything, "user1").Return(&repository.User{ID: "user1", Username: "john", IsActive: true, TeamName: "backend"}, nil)
 				isActive := false
 				ur.On("Patch", mock.Anything, &repository.UserPatch{
 					ID:       "user1",
@@ -59,6 +67,8 @@ func TestUserService_SetUserIsActive(t *testing.T) {
 					IsActive: false,
 					TeamName: "backend",
 				}, nil)
+
+				pr.On("GetOpenReviewPRs", mock.Anything, []string{"user1"}).Return([]*repository.PullRequest{}, nil)
 			},
 			expectedError: false,
 			expectedUser: &model.User{
@@ -68,12 +78,107 @@ func TestUserService_SetUserIsActive(t *testing.T) {
 				TeamName: "backend",
 			},
 		},
+		{
+			name:     "success deactivate releases reviews where inactive reviewers are forbidden",
+			userID:   "user1",
+			isActive: false,
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, tr *MockTeamRepository, rr *MockReviewRepository) {
+				ur.On("Get", mock.Anything, "user1").Return(&repository.User{ID: "user1", Username: "john", IsActive: true, TeamName: "backend"}, nil)
+				ur.On("Patch", mock.Anything, mock.Anything).Return(&repository.User{
+					ID:       "user1",
+					Username: "john",
+					IsActive: false,
+					TeamName: "backend",
+				}, nil)
+
+				pr.On("GetOpenReviewPRs", mock.Anything, []string{"user1"}).Return([]*repository.PullRequest{
+					{ID: "pr-1", AuthorID: "user2", Status: model.PRStatusOpen},
+					{ID: "pr-2", AuthorID: "user3", Status: model.PRStatusOpen},
+				}, nil)
+				ur.On("GetTeamNames", mock.Anything, []string{"user2", "user3"}).Return(map[string]string{"user2": "backend", "user3": "frontend"}, nil)
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+				tr.On("GetSettings", mock.Anything, "frontend").Return(&repository.TeamSettings{
+					TeamName:               "frontend",
+					MinReviewers:           2,
+					MaxReviewers:           2,
+					AllowInactiveReviewers: false,
+				}, nil)
+
+				pr.On("GetReviewersOf", mock.Anything, []string{"pr-2"}).Return(map[string][]string{
+					"pr-2": {"user1", "user4"},
+				}, nil)
+				rr.On("UnassignMany", mock.Anything, []string{"pr-2"}, []string{"user1"}).Return(nil)
+				pr.On("SetNeedMoreReviewers", mock.Anything, []string{"pr-2"}, true).Return(nil)
+			},
+			expectedError: false,
+			expectedUser: &model.User{
+				ID:       "user1",
+				Username: "john",
+				IsActive: false,
+				TeamName: "backend",
+			},
+		},
+		{
+			name:     "success deactivate with reassignment",
+			userID:   "user1",
+			isActive: false,
+			reassign: true,
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, tr *MockTeamRepository, rr *MockReviewRepository) {
+				ur.On("Get", mock.Anything, "user1").Return(&repository.User{ID: "user1", Username: "john", IsActive: true, TeamName: "backend"}, nil)
+				ur.On("Patch", mock.Anything, mock.Anything).Return(&repository.User{
+					ID:       "user1",
+					Username: "john",
+					IsActive: false,
+					TeamName: "backend",
+				}, nil)
+
+				pr.On("GetOpenReviewPRs", mock.Anything, []string{"user1"}).Return([]*repository.PullRequest{
+					{ID: "pr-1", AuthorID: "user2", Status: model.PRStatusOpen},
+					{ID: "pr-2", AuthorID: "user3", Status: model.PRStatusOpen},
+				}, nil)
+				pr.On("GetReviewersOf", mock.Anything, []string{"pr-1", "pr-2"}).Return(map[string][]string{
+					"pr-1": {"user1"},
+					"pr-2": {"user1", "user2"},
+				}, nil)
+				ur.On("GetTeamNames", mock.Anything, []string{"user2", "user3"}).Return(map[string]string{"user2": "backend", "user3": "backend"}, nil)
+
+				tr.On("GetTeamMembers", mock.Anything, "backend").Return([]*repository.User{
+					{ID: "user1", Username: "john", IsActive: false, TeamName: "backend"},
+					{ID: "user2", Username: "jane", IsActive: true, TeamName: "backend"},
+					{ID: "user3", Username: "jack", IsActive: true, TeamName: "backend"},
+				}, nil)
+
+				rr.On("CountTeamOpenReviews", mock.Anything, "backend").Return(map[string]int{}, nil)
+				tr.On("GetSettings", mock.Anything, "backend").Return(nil, repository.ErrNotFound)
+				tr.On("GetReviewerRules", mock.Anything, "backend").Return([]*repository.ReviewerRule{}, nil)
+				rr.On("UnassignMany", mock.Anything, []string{"pr-1", "pr-2"}, []string{"user1"}).Return(nil)
+				rr.On("AssignMany", mock.Anything, []*repository.Review{
+					{UserID: "user3", PullRequestID: "pr-1"},
+				}).Return(nil)
+				pr.On("SetNeedMoreReviewers", mock.Anything, []string{"pr-1", "pr-2"}, true).Return(nil)
+				rr.On("RecordReassignments", mock.Anything, []*repository.Reassignment{
+					{PullRequestID: "pr-1", OldReviewerID: "user1", NewReviewerID: ptr("user3")},
+					{PullRequestID: "pr-2", OldReviewerID: "user1"},
+				}).Return(nil)
+			},
+			expectedError: false,
+			expectedUser: &model.User{
+				ID:       "user1",
+				Username: "john",
+				IsActive: false,
+				TeamName: "backend",
+			},
+			expectedMoves: []*model.ReviewReassignment{
+				{PullRequestID: "pr-1", OldReviewerID: "user1", NewReviewerID: "user3", Status: model.ReassignmentStatusReassigned},
+				{PullRequestID: "pr-2", OldReviewerID: "user1", Status: model.ReassignmentStatusNoCandidate},
+			},
+		},
 		{
 			name:     "user not found",
 			userID:   "unknown",
 			isActive: true,
-			setupMocks: func(ur *MockUserRepository) {
-				ur.On("Patch", mock.Anything, mock.Anything).Return(nil, repository.ErrNotFound)
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, tr *MockTeamRepository, rr *MockReviewRepository) {
+				ur.On("Get", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
 			},
 			expectedError: true,
 			errorCode:     ErrorCodeNotFound,
@@ -82,7 +187,8 @@ func TestUserService_SetUserIsActive(t *testing.T) {
 			name:     "patch failed",
 			userID:   "user1",
 			isActive: true,
-			setupMocks: func(ur *MockUserRepository) {
+			setupMocks: func(ur *MockUserRepository, pr *MockPullRequestRepository, tr *MockTeamRepository, rr *MockReviewRepository) {
+				ur.On("Get", mock.Anything, "user1").Return(&repository.User{ID: "user1", Username: "john", IsActive: true, TeamName: "backend"}, nil)
 				ur.On("Patch", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
 			},
 			expectedError: true,
@@ -94,18 +200,144 @@ func TestUserService_SetUserIsActive(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockTx := new(MockTransactor)
 			mockUserRepo := new(MockUserRepository)
+			mockPRRepo := new(MockPullRequestRepository)
+			mockTeamRepo := new(MockTeamRepository)
+			mockReviewRepo := new(MockReviewRepository)
 
-			tt.setupMocks(mockUserRepo)
+			tt.setupMocks(mockUserRepo, mockPRRepo, mockTeamRepo, mockReviewRepo)
+
+			prService := NewPullRequestService(mockTx).
+				WithUserRepo(mockUserRepo).
+				WithPullRequestRepo(mockPRRepo).
+				WithTeamRepo(mockTeamRepo).
+				WithReviewRepo(mockReviewRepo).
+				WithReviewerSelector(NewRoundRobinSelector())
 
 			service := NewUserService(mockTx).
-				WithUserRepo(mockUserRepo)
+				WithUserRepo(mockUserRepo).
+				WithPullRequestService(prService)
 
-			got, err := service.SetUserIsActive(context.Background(), tt.userID, tt.isActive)
+			got, err := service.SetUserIsActive(context.Background(), tt.userID, tt.isActive, tt.reassign)
 
 			if tt.expectedError {
 				assert.Error(t, err)
 				assert.Equal(t, tt.errorCode, err.Code)
 				assert.Nil(t, got)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedUser, got.User)
+				assert.Equal(t, tt.expectedMoves, got.Reassignments)
+			}
+
+			mockUserRepo.AssertExpectations(t)
+			mockPRRepo.AssertExpectations(t)
+			mockTeamRepo.AssertExpectations(t)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_SetAvailability(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, time.July, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name          string
+		availability  *model.UserAvailability
+		setupMocks    func(*MockUserRepository)
+		expectedError bool
+		errorCode     ErrorCode
+		expected      []*model.Absence
+	}{
+		{
+			name: "success: windows are sorted",
+			availability: &model.UserAvailability{UserID: "u1", Absences: []*model.Absence{
+				{StartsAt: day(20), EndsAt: day(25)},
+				{StartsAt: day(1), EndsAt: day(14)},
+			}},
+			setupMocks: func(ur *MockUserRepository) {
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				ur.On("GetAbsences", mock.Anything, mock.Anything).Return([]*repository.Absence{}, nil)
+				ur.On("ReplaceAbsences", mock.Anything, "u1", []*repository.Absence{
+					{UserID: "u1", StartsAt: day(1), EndsAt: day(14)},
+					{UserID: "u1", StartsAt: day(20), EndsAt: day(25)},
+				}).Return(nil)
+			},
+			expected: []*model.Absence{
+				{UserID: "u1", StartsAt: day(1), EndsAt: day(14)},
+				{UserID: "u1", StartsAt: day(20), EndsAt: day(25)},
+			},
+		},
+		{
+			name:         "success: empty list clears windows",
+			availability: &model.UserAvailability{UserID: "u1"},
+			setupMocks: func(ur *MockUserRepository) {
+				ur.On("Get", mock.Anything, "u1").Return(&repository.User{ID: "u1", IsActive: true, TeamName: "backend"}, nil)
+				ur.On("GetAbsences", mock.Anything, mock.Anything).Return([]*repository.Absence{}, nil)
+				ur.On("ReplaceAbsences", mock.Anything, "u1", []*repository.Absence{}).Return(nil)
+			},
+			expected: []*model.Absence{},
+		},
+		{
+			name: "failure: overlapping windows",
+			availability: &model.UserAvailability{UserID: "u1", Absences: []*model.Absence{
+				{StartsAt: day(1), EndsAt: day(14)},
+				{StartsAt: day(10), EndsAt: day(20)},
+			}},
+			setupMocks:    func(ur *MockUserRepository) {},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name: "failure: window ends before it starts",
+			availability: &model.UserAvailability{UserID: "u1", Absences: []*model.Absence{
+				{StartsAt: day(14), EndsAt: day(1)},
+			}},
+			setupMocks:    func(ur *MockUserRepository) {},
+			expectedError: true,
+			errorCode:     ErrorCodeInvalidBody,
+		},
+		{
+			name: "failure: user not found",
+			availability: &model.UserAvailability{UserID: "unknown", Absences: []*model.Absence{
+				{StartsAt: day(1), EndsAt: day(14)},
+			}},
+			setupMocks: func(ur *MockUserRepository) {
+				ur.On("Get", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+		{
+			name:         "failure: clearing windows of unknown user",
+			availability: &model.UserAvailability{UserID: "unknown"},
+			setupMocks: func(ur *MockUserRepository) {
+				ur.On("Get", mock.Anything, "unknown").Return(nil, repository.ErrNotFound)
+			},
+			expectedError: true,
+			errorCode:     ErrorCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTx := new(MockTransactor)
+			mockUserRepo := new(MockUserRepository)
+
+			tt.setupMocks(mockUserRepo)
+
+			service := NewUserService(mockTx).
+				WithUserRepo(mockUserRepo)
+
+			got, err := service.SetAvailability(context.Background(), tt.availability)
+
+			if tt.expectedError {
+				assert.NotNil(t, err)
+				assert.Equal(t, tt.errorCode, err.Code)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expected, got.Absences)
 			}
 
 			mockUserRepo.AssertExpectations(t)
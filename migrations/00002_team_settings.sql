@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS team_settings
+(
+    team_name                VARCHAR(255) PRIMARY KEY REFERENCES team (name) ON DELETE CASCADE,
+    min_reviewers            INT     NOT NULL DEFAULT 2 CHECK (min_reviewers >= 0),
+    max_reviewers            INT     NOT NULL DEFAULT 2,
+    lead_id                  VARCHAR(255) REFERENCES users (id) DEFAULT NULL,
+    lead_mandatory           BOOLEAN NOT NULL DEFAULT FALSE,
+    allow_inactive_reviewers BOOLEAN NOT NULL DEFAULT TRUE,
+    CHECK (max_reviewers >= min_reviewers)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS team_settings;
+-- +goose StatementEnd
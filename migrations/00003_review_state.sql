@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE review_state AS ENUM ('PENDING', 'APPROVED', 'CHANGES_REQUESTED');
+
+ALTER TABLE review
+    ADD COLUMN IF NOT EXISTS state review_state NOT NULL DEFAULT 'PENDING';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE review
+    DROP COLUMN IF EXISTS state;
+DROP TYPE IF EXISTS review_state;
+-- +goose StatementEnd
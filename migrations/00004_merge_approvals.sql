@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS required_approvals INT NOT NULL DEFAULT 1 CHECK (required_approvals >= 0);
+
+ALTER TABLE pull_request
+    ADD COLUMN IF NOT EXISTS force_merged BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_request
+    DROP COLUMN IF EXISTS force_merged;
+
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS required_approvals;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE pull_request_status ADD VALUE IF NOT EXISTS 'DRAFT';
+ALTER TYPE pull_request_status ADD VALUE IF NOT EXISTS 'CLOSED';
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+UPDATE pull_request SET status = 'OPEN' WHERE status IN ('DRAFT', 'CLOSED');
+
+ALTER TYPE pull_request_status RENAME TO pull_request_status_old;
+CREATE TYPE pull_request_status AS ENUM ('OPEN', 'MERGED');
+
+ALTER TABLE pull_request ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE pull_request
+    ALTER COLUMN status TYPE pull_request_status USING status::text::pull_request_status;
+ALTER TABLE pull_request ALTER COLUMN status SET DEFAULT 'OPEN';
+
+DROP TYPE pull_request_status_old;
+
+COMMIT;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS allow_external_reviewers BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS allow_external_reviewers;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE reviewer_rule_kind AS ENUM ('EXCLUDE', 'PAIR');
+
+CREATE TABLE IF NOT EXISTS reviewer_rule
+(
+    team_name   VARCHAR(255)       NOT NULL REFERENCES team (name) ON DELETE CASCADE,
+    kind        reviewer_rule_kind NOT NULL,
+    reviewer_id VARCHAR(255)       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    target_id   VARCHAR(255)       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (team_name, kind, reviewer_id, target_id),
+    CHECK (reviewer_id <> target_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reviewer_rule;
+DROP TYPE IF EXISTS reviewer_rule_kind;
+-- +goose StatementEnd
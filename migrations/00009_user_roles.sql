@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE user_role AS ENUM ('JUNIOR', 'MIDDLE', 'SENIOR', 'LEAD');
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role   user_role NOT NULL DEFAULT 'MIDDLE',
+    ADD COLUMN IF NOT EXISTS skills TEXT[]    NOT NULL DEFAULT '{}';
+
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS require_senior BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS require_senior;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS skills,
+    DROP COLUMN IF EXISTS role;
+
+DROP TYPE IF EXISTS user_role;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_absence
+(
+    user_id   VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ  NOT NULL,
+    ends_at   TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (user_id, starts_at),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS user_absence_ends_at_idx ON user_absence (ends_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_absence;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS review_reassignment
+(
+    id              BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_request (id) ON DELETE CASCADE,
+    old_reviewer_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    new_reviewer_id VARCHAR(255) REFERENCES users (id) ON DELETE SET NULL,
+    reassigned_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS review_reassignment_old_reviewer_idx ON review_reassignment (old_reviewer_id, reassigned_at);
+CREATE INDEX IF NOT EXISTS review_reassignment_pull_request_idx ON review_reassignment (pull_request_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS review_reassignment;
+-- +goose StatementEnd
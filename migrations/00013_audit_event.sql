@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS audit_event
+(
+    id          BIGSERIAL PRIMARY KEY,
+    actor       VARCHAR(255) NOT NULL,
+    action      VARCHAR(64)  NOT NULL,
+    entity_type VARCHAR(64)  NOT NULL,
+    entity_id   VARCHAR(255) NOT NULL,
+    before      JSONB,
+    after       JSONB,
+    request_id  VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_event_entity_idx ON audit_event (entity_type, entity_id, id);
+CREATE INDEX IF NOT EXISTS audit_event_actor_idx ON audit_event (actor, id);
+CREATE INDEX IF NOT EXISTS audit_event_created_at_idx ON audit_event (created_at);
+
+CREATE OR REPLACE FUNCTION audit_event_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_event_append_only
+    BEFORE UPDATE OR DELETE
+    ON audit_event
+    FOR EACH ROW
+EXECUTE FUNCTION audit_event_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_event;
+DROP FUNCTION IF EXISTS audit_event_append_only();
+-- +goose StatementEnd
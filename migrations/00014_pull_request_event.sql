@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS pull_request_event
+(
+    id              BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_request (id) ON DELETE CASCADE,
+    type            VARCHAR(64)  NOT NULL,
+    actor           VARCHAR(255) NOT NULL DEFAULT '',
+    reviewer_id     VARCHAR(255) NOT NULL DEFAULT '',
+    new_reviewer_id VARCHAR(255) NOT NULL DEFAULT '',
+    review_state    VARCHAR(64)  NOT NULL DEFAULT '',
+    from_status     VARCHAR(64)  NOT NULL DEFAULT '',
+    to_status       VARCHAR(64)  NOT NULL DEFAULT '',
+    reason          VARCHAR(64)  NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS pull_request_event_pull_request_idx ON pull_request_event (pull_request_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pull_request_event;
+-- +goose StatementEnd
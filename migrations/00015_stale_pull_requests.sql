@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS stale_after_minutes INT         NOT NULL DEFAULT 0 CHECK (stale_after_minutes >= 0),
+    ADD COLUMN IF NOT EXISTS stale_policy        VARCHAR(32) NOT NULL DEFAULT 'ESCALATE'
+        CHECK (stale_policy IN ('ESCALATE', 'ADD_REVIEWER', 'REASSIGN_IDLE'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE team_settings
+    DROP COLUMN IF EXISTS stale_policy,
+    DROP COLUMN IF EXISTS stale_after_minutes;
+-- +goose StatementEnd
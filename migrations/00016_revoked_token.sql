@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS revoked_token
+(
+    jti        VARCHAR(255) PRIMARY KEY,
+    subject    VARCHAR(255) NOT NULL DEFAULT '',
+    reason     TEXT         NOT NULL DEFAULT '',
+    revoked_by VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS revoked_token_expires_at_idx ON revoked_token (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS revoked_token;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- PRs merged before merged_at was stamped on merge, created_at is the best known lower bound
+UPDATE pull_request
+SET merged_at = COALESCE(created_at, NOW())
+WHERE status = 'MERGED'
+  AND merged_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd